@@ -11,6 +11,7 @@ import (
 	"github.com/kilianmandscharo/lethimcook/logging"
 	"github.com/kilianmandscharo/lethimcook/render"
 	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/labstack/echo/v4"
 )
 
@@ -36,13 +37,13 @@ func (ac *AuthController) AttachHandlerFunctions(e *echo.Echo) {
 	e.POST("/auth/login", ac.HandleLogin)
 	e.POST("/auth/logout", ac.HandleLogout)
 	e.PUT("/auth/password", ac.HandleUpdatePassword)
+	e.POST("/admin/users", ac.HandleCreateUser)
+	e.PUT("/admin/users/:id/role", ac.HandleUpdateUserRole)
+	e.DELETE("/admin/users/:id", ac.HandleDeleteUser)
 }
 
 func (ac *AuthController) RenderAdminPage(c echo.Context) error {
-	return ac.renderAdminPage(renderAdminPageOptions{
-		c:            c,
-		isAuthorized: servutil.IsAuthorized(c),
-	})
+	return ac.renderAdminPage(renderAdminPageOptions{c: c})
 }
 
 func (ac *AuthController) HandleLogin(c echo.Context) error {
@@ -57,7 +58,10 @@ func (ac *AuthController) HandleLogin(c echo.Context) error {
 		})
 	}
 
-	err := ac.authService.validatePassword(c.Request().FormValue("password"))
+	user, err := ac.authService.login(
+		c.Request().FormValue("name"),
+		c.Request().FormValue("password"),
+	)
 	if err != nil {
 		appError := errutil.AddMessageToAppError(
 			err,
@@ -65,13 +69,12 @@ func (ac *AuthController) HandleLogin(c echo.Context) error {
 		)
 		return ac.renderAdminPage(renderAdminPageOptions{
 			c:              c,
-			isAuthorized:   servutil.IsAuthorized(c),
-			loginFormError: errutil.FormErrorInvalidPassword,
+			loginFormError: errutil.FormErrorInvalidLogin,
 			err:            appError,
 		})
 	}
 
-	token, err := ac.authService.createToken()
+	token, err := ac.authService.createToken(user)
 	if err != nil {
 		return ac.renderer.RenderError(
 			c,
@@ -82,19 +85,19 @@ func (ac *AuthController) HandleLogin(c echo.Context) error {
 	cookie := ac.authService.newTokenCookie(token, time.Now().Add(60*time.Minute))
 	c.SetCookie(&cookie)
 
-	c.Set("authorized", true)
+	servutil.SetUser(c, user)
 
-	ac.logger.Info("admin login successful")
+	ac.logger.Infof("user %s login successful", user.Name)
 
 	return ac.renderAdminPage(renderAdminPageOptions{
-		c:            c,
-		isAuthorized: servutil.IsAuthorized(c),
-		message:      "Angemeldet",
+		c:       c,
+		message: "Angemeldet",
 	})
 }
 
 func (ac *AuthController) HandleLogout(c echo.Context) error {
-	if !servutil.IsAuthorized(c) {
+	user, ok := servutil.GetUser(c)
+	if !ok {
 		return ac.renderer.RenderError(
 			c,
 			errutil.NewAppErrorNotAuthorized("HandleLogout()"),
@@ -104,18 +107,25 @@ func (ac *AuthController) HandleLogout(c echo.Context) error {
 	cookie := ac.authService.newTokenCookie("", time.Unix(0, 0))
 	c.SetCookie(&cookie)
 
-	c.Set("authorized", false)
+	servutil.ClearUser(c)
 
-	ac.logger.Info("admin logout successful")
+	ac.logger.Infof("user %s logout successful", user.Name)
 
 	return ac.renderAdminPage(renderAdminPageOptions{
-		c:            c,
-		isAuthorized: servutil.IsAuthorized(c),
-		message:      "Abgemeldet",
+		c:       c,
+		message: "Abgemeldet",
 	})
 }
 
 func (ac *AuthController) HandleUpdatePassword(c echo.Context) error {
+	user, ok := servutil.GetUser(c)
+	if !ok {
+		return ac.renderer.RenderError(
+			c,
+			errutil.NewAppErrorNotAuthorized("HandleUpdatePassword()"),
+		)
+	}
+
 	if err := c.Request().ParseForm(); err != nil {
 		return ac.renderer.RenderError(c, &errutil.AppError{
 			UserMessage: "Fehlerhaftes Formular",
@@ -127,7 +137,7 @@ func (ac *AuthController) HandleUpdatePassword(c echo.Context) error {
 		})
 	}
 
-	err := ac.authService.validatePassword(c.Request().FormValue("old-password"))
+	err := ac.authService.validatePassword(user.ID, c.Request().FormValue("old-password"))
 	if err != nil {
 		appError := errutil.AddMessageToAppError(
 			err,
@@ -135,13 +145,12 @@ func (ac *AuthController) HandleUpdatePassword(c echo.Context) error {
 		)
 		return ac.renderAdminPage(renderAdminPageOptions{
 			c:                c,
-			isAuthorized:     servutil.IsAuthorized(c),
 			err:              appError,
 			oldPasswordError: errutil.FormErrorInvalidPassword,
 		})
 	}
 
-	err = ac.authService.updateAdminPasswordHash(c.Request().FormValue("new-password"))
+	err = ac.authService.updatePasswordHash(user.ID, c.Request().FormValue("new-password"))
 	if err != nil {
 		formError := errors.Unwrap(err)
 		appError := errutil.AddMessageToAppError(
@@ -150,30 +159,124 @@ func (ac *AuthController) HandleUpdatePassword(c echo.Context) error {
 		)
 		return ac.renderAdminPage(renderAdminPageOptions{
 			c:                c,
-			isAuthorized:     servutil.IsAuthorized(c),
 			err:              appError,
 			newPasswordError: formError,
 		})
 	}
 
-	ac.logger.Info("admin password updated successfully")
+	ac.logger.Infof("password of user %s updated successfully", user.Name)
+
+	return ac.renderAdminPage(renderAdminPageOptions{
+		c:       c,
+		message: "Passwort aktualisiert",
+	})
+}
+
+func (ac *AuthController) HandleCreateUser(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return ac.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleCreateUser()"),
+		)
+	}
+
+	user, err := ac.authService.createUser(
+		c.FormValue("name"),
+		c.FormValue("password"),
+		types.Role(c.FormValue("role")),
+	)
+	if err != nil {
+		return ac.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleCreateUser()"),
+		)
+	}
+
+	ac.logger.Infof("created user %s with role %s", user.Name, user.Role)
+
+	return ac.renderAdminPage(renderAdminPageOptions{
+		c:       c,
+		message: "Benutzer angelegt",
+	})
+}
+
+func (ac *AuthController) HandleUpdateUserRole(c echo.Context) error {
+	currentUser, ok := servutil.GetUser(c)
+	if !ok || !currentUser.Role.IsAdmin() {
+		return ac.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleUpdateUserRole()"),
+		)
+	}
+
+	createError := func(err error) error {
+		return ac.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleUpdateUserRole()"),
+		)
+	}
+
+	id, err := ac.authService.getPathUserId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	role := types.Role(c.FormValue("role"))
+	if err := ac.authService.updateRole(currentUser.ID, id, role); err != nil {
+		return createError(err)
+	}
+
+	ac.logger.Infof("set role of user %d to %s", id, role)
 
 	return ac.renderAdminPage(renderAdminPageOptions{
-		c:            c,
-		isAuthorized: servutil.IsAuthorized(c),
-		message:      "Passwort aktualisiert",
+		c:       c,
+		message: "Rolle geändert",
+	})
+}
+
+func (ac *AuthController) HandleDeleteUser(c echo.Context) error {
+	currentUser, ok := servutil.GetUser(c)
+	if !ok || !currentUser.Role.IsAdmin() {
+		return ac.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleDeleteUser()"),
+		)
+	}
+
+	createError := func(err error) error {
+		return ac.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleDeleteUser()"),
+		)
+	}
+
+	id, err := ac.authService.getPathUserId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := ac.authService.deleteUser(currentUser.ID, id); err != nil {
+		return createError(err)
+	}
+
+	ac.logger.Info("deleted user", id)
+
+	return ac.renderAdminPage(renderAdminPageOptions{
+		c:       c,
+		message: "Benutzer gelöscht",
 	})
 }
 
 func (ac *AuthController) ValidateTokenMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		cookie, err := c.Cookie("token")
-		if err != nil || !ac.authService.validCookieToken(cookie) {
-			c.Set("authorized", false)
+		if err != nil {
 			return next(c)
 		}
 
-		c.Set("authorized", true)
+		if user, ok := ac.authService.parseCookieToken(cookie); ok {
+			servutil.SetUser(c, user)
+		}
 
 		return next(c)
 	}
@@ -181,7 +284,6 @@ func (ac *AuthController) ValidateTokenMiddleware(next echo.HandlerFunc) echo.Ha
 
 type renderAdminPageOptions struct {
 	c                echo.Context
-	isAuthorized     bool
 	loginFormError   error
 	message          string
 	err              error
@@ -190,11 +292,26 @@ type renderAdminPageOptions struct {
 }
 
 func (ac *AuthController) renderAdminPage(options renderAdminPageOptions) error {
+	user, _ := servutil.GetUser(options.c)
+
+	var users []types.User
+	if user.Role.IsAdmin() {
+		var err error
+		users, err = ac.authService.readUsers()
+		if err != nil {
+			return ac.renderer.RenderError(
+				options.c,
+				errutil.AddMessageToAppError(err, "failed at renderAdminPage()"),
+			)
+		}
+	}
+
 	return ac.renderer.RenderComponent(render.RenderComponentOptions{
 		Context: options.c,
 		Component: components.AdminPage(
-			options.isAuthorized,
-			ac.authService.createLoginForm(options.isAuthorized, options.loginFormError),
+			user,
+			users,
+			ac.authService.createLoginForm(user.IsLoggedIn(), options.loginFormError),
 			ac.authService.createNewPasswordForm(options.oldPasswordError, options.newPasswordError),
 		),
 		Message: options.message,
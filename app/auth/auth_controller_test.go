@@ -10,6 +10,8 @@ import (
 	"github.com/kilianmandscharo/lethimcook/render"
 	"github.com/kilianmandscharo/lethimcook/servutil"
 	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -34,7 +36,7 @@ func newTestAuthController(options controllerOptions) *AuthController {
 }
 
 func newTestCookie(t *testing.T, authController *AuthController) http.Cookie {
-	token, err := authController.authService.createToken()
+	token, err := authController.authService.createToken(types.User{ID: 1, Name: "admin", Role: types.RoleAdmin})
 	assert.NoError(t, err)
 	return authController.authService.newTokenCookie(token, time.Now().Add(60*time.Minute))
 }
@@ -65,9 +67,9 @@ func TestHandleLogin(t *testing.T) {
 				HandlerFunc:  authController.HandleLogin,
 				Method:       http.MethodPost,
 				Route:        "/auth/login",
-				StatusWant:   http.StatusNotFound,
+				StatusWant:   http.StatusUnauthorized,
 				WithFormData: true,
-				FormData:     "password=" + testPassword,
+				FormData:     "name=admin&password=" + testPassword,
 			},
 		)
 		assert.Equal(t, 0, len(w.Result().Cookies()))
@@ -85,7 +87,7 @@ func TestHandleLogin(t *testing.T) {
 				Route:        "/auth/login",
 				StatusWant:   http.StatusUnauthorized,
 				WithFormData: true,
-				FormData:     "invalidKey=" + testPassword,
+				FormData:     "name=admin&invalidKey=" + testPassword,
 			},
 		)
 		assert.Equal(t, 0, len(w.Result().Cookies()))
@@ -101,7 +103,7 @@ func TestHandleLogin(t *testing.T) {
 				Route:        "/auth/login",
 				StatusWant:   http.StatusUnauthorized,
 				WithFormData: true,
-				FormData:     "password=invalid_password",
+				FormData:     "name=admin&password=invalid_password",
 			},
 		)
 		assert.Equal(t, 0, len(w.Result().Cookies()))
@@ -117,12 +119,30 @@ func TestHandleLogin(t *testing.T) {
 				Route:        "/auth/login",
 				StatusWant:   http.StatusOK,
 				WithFormData: true,
-				FormData:     "password=" + testPassword,
+				FormData:     "name=admin&password=" + testPassword,
 			},
 		)
 		assert.Equal(t, 1, len(w.Result().Cookies()))
 		assert.True(t, servutil.IsAuthorized(c))
 	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		w, c := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   authController.HandleLogin,
+				Method:        http.MethodPost,
+				Route:         "/auth/login",
+				StatusWant:    http.StatusUnauthorized,
+				WithFormData:  true,
+				FormData:      "name=Oma&password=" + testPassword,
+				AssertMessage: true,
+				MessageWant:   "Falscher Benutzername oder Passwort",
+			},
+		)
+		assert.Equal(t, 0, len(w.Result().Cookies()))
+		assert.False(t, servutil.IsLoggedIn(c))
+	})
 }
 
 func TestHandleLogout(t *testing.T) {
@@ -158,24 +178,22 @@ func TestHandleLogout(t *testing.T) {
 }
 
 func TestHandleUpdatePassword(t *testing.T) {
-	authController := newTestAuthController(controllerOptions{})
+	authController := newTestAuthController(controllerOptions{withAdmin: true})
 
-	t.Run("no admin", func(t *testing.T) {
+	t.Run("not logged in", func(t *testing.T) {
 		testutil.AssertRequest(
 			t,
 			testutil.RequestOptions{
 				HandlerFunc:  authController.HandleUpdatePassword,
 				Method:       http.MethodPut,
 				Route:        "/auth/password",
-				StatusWant:   http.StatusNotFound,
+				StatusWant:   http.StatusUnauthorized,
 				WithFormData: true,
-				FormData:     "old-password=test&new-password=test",
+				FormData:     fmt.Sprintf("old-password=%s&new-password=updated", testPassword),
 			},
 		)
 	})
 
-	authController = newTestAuthController(controllerOptions{withAdmin: true})
-
 	t.Run("wrong old password", func(t *testing.T) {
 		testutil.AssertRequest(
 			t,
@@ -183,6 +201,7 @@ func TestHandleUpdatePassword(t *testing.T) {
 				HandlerFunc:  authController.HandleUpdatePassword,
 				Method:       http.MethodPut,
 				Route:        "/auth/password",
+				Authorized:   true,
 				StatusWant:   http.StatusUnauthorized,
 				WithFormData: true,
 				FormData:     "old-password=invalid_password&new-password=test",
@@ -199,6 +218,7 @@ func TestHandleUpdatePassword(t *testing.T) {
 				Route:        "/auth/password",
 				StatusWant:   http.StatusUnauthorized,
 				WithFormData: true,
+				Authorized:   true,
 				FormData:     fmt.Sprintf("wrongKey=%s&new-password=test", testPassword),
 			},
 		)
@@ -213,6 +233,7 @@ func TestHandleUpdatePassword(t *testing.T) {
 				Route:        "/auth/password",
 				StatusWant:   http.StatusBadRequest,
 				WithFormData: true,
+				Authorized:   true,
 				FormData:     fmt.Sprintf("old-password=%s&wrongKey=test", testPassword),
 			},
 		)
@@ -227,6 +248,7 @@ func TestHandleUpdatePassword(t *testing.T) {
 				Route:        "/auth/password",
 				StatusWant:   http.StatusBadRequest,
 				WithFormData: true,
+				Authorized:   true,
 				FormData:     fmt.Sprintf("old-password=%s&new-password=new", testPassword),
 			},
 		)
@@ -241,8 +263,261 @@ func TestHandleUpdatePassword(t *testing.T) {
 				Route:        "/auth/password",
 				StatusWant:   http.StatusOK,
 				WithFormData: true,
+				Authorized:   true,
 				FormData:     fmt.Sprintf("old-password=%s&new-password=updated", testPassword),
 			},
 		)
+		assert.NoError(t, authController.authService.validatePassword(1, "updated"))
+	})
+}
+
+func TestHandleCreateUser(t *testing.T) {
+	authController := newTestAuthController(controllerOptions{withAdmin: true})
+
+	t.Run("not an admin", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  authController.HandleCreateUser,
+				Method:       http.MethodPost,
+				Route:        "/admin/users",
+				Role:         types.RoleEditor,
+				StatusWant:   http.StatusForbidden,
+				WithFormData: true,
+				FormData:     "name=Oma&password=test_password&role=editor",
+			},
+		)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   authController.HandleCreateUser,
+				Method:        http.MethodPost,
+				Route:         "/admin/users",
+				Authorized:    true,
+				StatusWant:    http.StatusBadRequest,
+				WithFormData:  true,
+				FormData:      "name=Oma&password=test_password&role=chef",
+				AssertMessage: true,
+				MessageWant:   "Ungültige Rolle",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   authController.HandleCreateUser,
+				Method:        http.MethodPost,
+				Route:         "/admin/users",
+				Authorized:    true,
+				StatusWant:    http.StatusOK,
+				WithFormData:  true,
+				FormData:      "name=Oma&password=test_password&role=editor",
+				AssertMessage: true,
+				MessageWant:   "Benutzer angelegt",
+			},
+		)
+		user, err := authController.authService.login("Oma", "test_password")
+		assert.NoError(t, err)
+		assert.Equal(t, types.RoleEditor, user.Role)
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  authController.HandleCreateUser,
+				Method:       http.MethodPost,
+				Route:        "/admin/users",
+				Authorized:   true,
+				StatusWant:   http.StatusConflict,
+				WithFormData: true,
+				FormData:     "name=Oma&password=test_password&role=editor",
+			},
+		)
+	})
+}
+
+func TestHandleUpdateUserRole(t *testing.T) {
+	authController := newTestAuthController(controllerOptions{withAdmin: true})
+	_, err := authController.authService.createUser("Oma", "test_password", types.RoleContributor)
+	assert.NoError(t, err)
+
+	t.Run("not an admin", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    authController.HandleUpdateUserRole,
+				Method:         http.MethodPut,
+				Route:          "/admin/users/:id/role",
+				Role:           types.RoleEditor,
+				UserID:         2,
+				StatusWant:     http.StatusForbidden,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithFormData:   true,
+				FormData:       "role=admin",
+			},
+		)
+	})
+
+	t.Run("own role", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    authController.HandleUpdateUserRole,
+				Method:         http.MethodPut,
+				Route:          "/admin/users/:id/role",
+				Authorized:     true,
+				StatusWant:     http.StatusBadRequest,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithFormData:   true,
+				FormData:       "role=editor",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    authController.HandleUpdateUserRole,
+				Method:         http.MethodPut,
+				Route:          "/admin/users/:id/role",
+				Authorized:     true,
+				StatusWant:     http.StatusOK,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithFormData:   true,
+				FormData:       "role=editor",
+				AssertMessage:  true,
+				MessageWant:    "Rolle geändert",
+			},
+		)
+		user, err := authController.authService.login("Oma", "test_password")
+		assert.NoError(t, err)
+		assert.Equal(t, types.RoleEditor, user.Role)
+	})
+}
+
+func TestHandleDeleteUser(t *testing.T) {
+	authController := newTestAuthController(controllerOptions{withAdmin: true})
+	_, err := authController.authService.createUser("Oma", "test_password", types.RoleContributor)
+	assert.NoError(t, err)
+
+	t.Run("not an admin", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    authController.HandleDeleteUser,
+				Method:         http.MethodDelete,
+				Route:          "/admin/users/:id",
+				StatusWant:     http.StatusUnauthorized,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+			},
+		)
+	})
+
+	t.Run("own account", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    authController.HandleDeleteUser,
+				Method:         http.MethodDelete,
+				Route:          "/admin/users/:id",
+				Authorized:     true,
+				StatusWant:     http.StatusBadRequest,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    authController.HandleDeleteUser,
+				Method:         http.MethodDelete,
+				Route:          "/admin/users/:id",
+				Authorized:     true,
+				StatusWant:     http.StatusOK,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				AssertMessage:  true,
+				MessageWant:    "Benutzer gelöscht",
+			},
+		)
+		_, err := authController.authService.login("Oma", "test_password")
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateTokenMiddleware(t *testing.T) {
+	authController := newTestAuthController(controllerOptions{withAdmin: true})
+	handler := authController.ValidateTokenMiddleware(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	t.Run("no cookie", func(t *testing.T) {
+		_, c := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: handler,
+				Method:      http.MethodGet,
+				Route:       "/",
+				StatusWant:  http.StatusOK,
+			},
+		)
+		assert.False(t, servutil.IsLoggedIn(c))
+	})
+
+	t.Run("valid cookie", func(t *testing.T) {
+		_, c := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: handler,
+				Method:      http.MethodGet,
+				Route:       "/",
+				StatusWant:  http.StatusOK,
+				WithCookie:  true,
+				Cookie:      newTestCookie(t, authController),
+			},
+		)
+		user, ok := servutil.GetUser(c)
+		assert.True(t, ok)
+		assert.Equal(t, types.User{ID: 1, Name: "admin", Role: types.RoleAdmin}, user)
+	})
+	t.Run("deleted user", func(t *testing.T) {
+		oma, err := authController.authService.createUser("Oma", testPassword, types.RoleEditor)
+		assert.NoError(t, err)
+		token, err := authController.authService.createToken(oma)
+		assert.NoError(t, err)
+		assert.NoError(t, authController.authService.deleteUser(1, oma.ID))
+
+		_, c := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: handler,
+				Method:      http.MethodGet,
+				Route:       "/",
+				StatusWant:  http.StatusOK,
+				WithCookie:  true,
+				Cookie:      authController.authService.newTokenCookie(token, time.Now().Add(60*time.Minute)),
+			},
+		)
+		assert.False(t, servutil.IsLoggedIn(c))
 	})
 }
@@ -7,17 +7,29 @@ import (
 
 	"github.com/kilianmandscharo/lethimcook/errutil"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// admin is the single account of older versions, it is moved to the users
+// on startup.
 type admin struct {
 	ID           uint
 	PasswordHash string
 }
 
-func newAdmin(passwordHash string) admin {
-	return admin{PasswordHash: passwordHash}
+type user struct {
+	ID uint
+	// Names are unique regardless of case, "Anna" and "anna" are the same
+	// user
+	Name         string `gorm:"uniqueIndex:idx_users_name_nocase,collate:NOCASE"`
+	PasswordHash string
+	Role         types.Role
+}
+
+func (u *user) toUser() types.User {
+	return types.User{ID: u.ID, Name: u.Name, Role: u.Role}
 }
 
 type authDatabase struct {
@@ -30,27 +42,70 @@ func NewAuthDatabase(logger *logging.Logger) *authDatabase {
 	if err != nil {
 		logger.Fatal("failed to connect auth database: ", err)
 	}
-	db.AutoMigrate(&admin{})
-	return &authDatabase{handler: db, logger: logger}
+	authDatabase := &authDatabase{handler: db, logger: logger}
+	if err := authDatabase.migrateUserNameIndex(); err != nil {
+		logger.Fatal("failed to migrate user name index: ", err)
+	}
+	if err := db.AutoMigrate(&user{}); err != nil {
+		logger.Fatal("failed to migrate users: ", err)
+	}
+	if err := authDatabase.migrateAdmin(); err != nil {
+		logger.Fatal("failed to migrate admin: ", err)
+	}
+	return authDatabase
 }
 
-func (db *authDatabase) createAdmin(admin *admin) error {
-	doesAdminExist, err := db.doesAdminExist()
-	if err != nil {
-		return errutil.AddMessageToAppError(err, "failed at createAdmin()")
+// migrateUserNameIndex drops the case-sensitive name index of older
+// versions, it is replaced by one that ignores the case.
+func (db *authDatabase) migrateUserNameIndex() error {
+	if !db.handler.Migrator().HasIndex(&user{}, "idx_users_name") {
+		return nil
 	}
-	if doesAdminExist {
+	return db.handler.Migrator().DropIndex(&user{}, "idx_users_name")
+}
+
+// migrateAdmin turns the admin of older versions into a user named "admin"
+// with the same password.
+func (db *authDatabase) migrateAdmin() error {
+	if !db.handler.Migrator().HasTable(&admin{}) {
+		return nil
+	}
+	return db.handler.Transaction(func(tx *gorm.DB) error {
+		var admins []admin
+		if err := tx.Find(&admins).Error; err != nil {
+			return err
+		}
+		var userCount int64
+		if err := tx.Model(&user{}).Count(&userCount).Error; err != nil {
+			return err
+		}
+		if len(admins) > 0 && userCount == 0 {
+			migrated := user{Name: defaultAdminName, PasswordHash: admins[0].PasswordHash, Role: types.RoleAdmin}
+			if err := tx.Create(&migrated).Error; err != nil {
+				return err
+			}
+			db.logger.Info("migrated admin to user", migrated.ID)
+		}
+		return tx.Migrator().DropTable(&admin{})
+	})
+}
+
+func (db *authDatabase) createUser(user *user) error {
+	if _, err := db.readUserByName(user.Name); err == nil {
 		return &errutil.AppError{
-			UserMessage: "Ein Admin existiert bereits",
-			Err:         errors.New("failed at createAdmin(), an admin already exists"),
+			UserMessage: "Benutzername bereits vergeben",
+			Err:         fmt.Errorf("failed at createUser(), user %s already exists", user.Name),
 			StatusCode:  http.StatusConflict,
 		}
+	} else if errutil.GetAppErrorStatusCode(err) != http.StatusNotFound {
+		return errutil.AddMessageToAppError(err, "failed at createUser()")
 	}
-	if err := db.handler.Create(admin).Error; err != nil {
+	if err := db.handler.Create(user).Error; err != nil {
 		return &errutil.AppError{
 			UserMessage: "Datenbankfehler",
 			Err: fmt.Errorf(
-				"failed at createAdmin() with passwordHash, database failure: %w",
+				"failed at createUser() with name %s, database failure: %w",
+				user.Name,
 				err,
 			),
 			StatusCode: http.StatusInternalServerError,
@@ -60,7 +115,7 @@ func (db *authDatabase) createAdmin(admin *admin) error {
 }
 
 func (db *authDatabase) doesAdminExist() (bool, error) {
-	if err := db.handler.First(&admin{}).Error; err != nil {
+	if err := db.handler.Where("role = ?", types.RoleAdmin).First(&user{}).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return false, nil
 		}
@@ -77,47 +132,117 @@ func (db *authDatabase) doesAdminExist() (bool, error) {
 	return true, nil
 }
 
-func (db *authDatabase) readAdmin() (admin, error) {
-	var admin admin
-	if err := db.handler.First(&admin).Error; err != nil {
+func (db *authDatabase) readUser(id uint) (user, error) {
+	var user user
+	if err := db.handler.First(&user, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return admin, &errutil.AppError{
-				UserMessage: "Kein Admin gefunden",
-				Err:         errors.New("failed at readAdmin(), no admin found"),
+			return user, &errutil.AppError{
+				UserMessage: "Benutzer nicht gefunden",
+				Err:         fmt.Errorf("failed at readUser(), user with id %d not found", id),
 				StatusCode:  http.StatusNotFound,
 			}
 		}
-		return admin, &errutil.AppError{
+		return user, &errutil.AppError{
 			UserMessage: "Datenbankfehler",
 			Err: fmt.Errorf(
-				"failed at readAdmin(), database failure: %w",
+				"failed at readUser() with id %d, database failure: %w",
+				id,
 				err,
 			),
 			StatusCode: http.StatusInternalServerError,
 		}
 	}
-	return admin, nil
+	return user, nil
 }
 
-func (db *authDatabase) readAdminPasswordHash() (string, error) {
-	admin, err := db.readAdmin()
+func (db *authDatabase) readUserByName(name string) (user, error) {
+	var user user
+	if err := db.handler.Where("name = ? COLLATE NOCASE", name).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, &errutil.AppError{
+				UserMessage: "Benutzer nicht gefunden",
+				Err:         fmt.Errorf("failed at readUserByName(), user %s not found", name),
+				StatusCode:  http.StatusNotFound,
+			}
+		}
+		return user, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readUserByName() with name %s, database failure: %w",
+				name,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return user, nil
+}
+
+func (db *authDatabase) readUsers() ([]user, error) {
+	var users []user
+	if err := db.handler.Order("name COLLATE NOCASE").Find(&users).Error; err != nil {
+		return users, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readUsers(), database failure: %w",
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return users, nil
+}
+
+func (db *authDatabase) updatePasswordHash(id uint, newPasswordHash string) error {
+	user, err := db.readUser(id)
 	if err != nil {
-		return "", errutil.AddMessageToAppError(err, "failed at readAdminPasswordHash()")
+		return errutil.AddMessageToAppError(err, "failed at updatePasswordHash()")
 	}
-	return admin.PasswordHash, nil
+	user.PasswordHash = newPasswordHash
+	if err := db.handler.Save(&user).Error; err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at updatePasswordHash() with id %d, database failure: %w",
+				id,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return nil
 }
 
-func (db *authDatabase) updateAdminPasswordHash(newPasswordHash string) error {
-	admin, err := db.readAdmin()
+func (db *authDatabase) updateRole(id uint, role types.Role) error {
+	user, err := db.readUser(id)
 	if err != nil {
-		return errutil.AddMessageToAppError(err, "failed at updateAdminPasswordHash()")
+		return errutil.AddMessageToAppError(err, "failed at updateRole()")
+	}
+	user.Role = role
+	if err := db.handler.Save(&user).Error; err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at updateRole() with id %d, database failure: %w",
+				id,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return nil
+}
+
+func (db *authDatabase) deleteUser(id uint) error {
+	if _, err := db.readUser(id); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteUser()")
 	}
-	admin.PasswordHash = newPasswordHash
-	if err := db.handler.Save(&admin).Error; err != nil {
+	if err := db.handler.Delete(&user{}, id).Error; err != nil {
 		return &errutil.AppError{
 			UserMessage: "Datenbankfehler",
 			Err: fmt.Errorf(
-				"failed at updateAdminPasswordHash() with newPasswordHash, database failure: %w",
+				"failed at deleteUser() with id %d, database failure: %w",
+				id,
 				err,
 			),
 			StatusCode: http.StatusInternalServerError,
@@ -8,6 +8,7 @@ import (
 
 	"github.com/kilianmandscharo/lethimcook/errutil"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -22,36 +23,113 @@ func newTestAuthDatabase() *authDatabase {
 		fmt.Println("failed to connect test database: ", err)
 		os.Exit(1)
 	}
-	db.Migrator().DropTable(&admin{})
-	db.AutoMigrate(&admin{})
+	db.Migrator().DropTable(&admin{}, &user{})
+	db.AutoMigrate(&user{})
 	return &authDatabase{handler: db, logger: logging.New(logging.Debug, false)}
 }
 
-func newTestAdmin() admin {
-	return admin{PasswordHash: "test hash"}
+func newTestUser() user {
+	return user{Name: "admin", PasswordHash: "test hash", Role: types.RoleAdmin}
 }
 
-func TestCreateAdmin(t *testing.T) {
+func TestMigrateAdmin(t *testing.T) {
 	// Given
 	db := newTestAuthDatabase()
+	assert.NoError(t, db.handler.AutoMigrate(&admin{}))
+	assert.NoError(t, db.handler.Create(&admin{PasswordHash: "old hash"}).Error)
 
 	// When
-	admin := newTestAdmin()
-	err := db.createAdmin(&admin)
+	err := db.migrateAdmin()
 
 	// Then
 	assert.NoError(t, err)
-	assert.Equal(t, uint(1), admin.ID)
+	migrated, err := db.readUserByName("admin")
+	assert.NoError(t, err)
+	assert.Equal(t, "old hash", migrated.PasswordHash)
+	assert.Equal(t, types.RoleAdmin, migrated.Role)
+	assert.False(t, db.handler.Migrator().HasTable(&admin{}))
+
+	// When
+	err = db.migrateAdmin()
+
+	// Then
+	assert.NoError(t, err)
+	users, err := db.readUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(users))
+}
+
+func TestCreateUser(t *testing.T) {
+	// Given
+	db := newTestAuthDatabase()
 
 	// When
-	err = db.createAdmin(&admin)
+	user := newTestUser()
+	err := db.createUser(&user)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	// When
+	duplicate := newTestUser()
+	err = db.createUser(&duplicate)
 
 	// Then
 	assert.Error(t, err)
 	appError, ok := err.(*errutil.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, http.StatusConflict, appError.StatusCode)
-	assert.Equal(t, "Ein Admin existiert bereits", appError.UserMessage)
+	assert.Equal(t, "Benutzername bereits vergeben", appError.UserMessage)
+
+	// When
+	duplicate.Name = "Admin"
+	err = db.createUser(&duplicate)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	duplicate.Name = "ADMIN"
+	err = db.handler.Create(&duplicate).Error
+
+	// Then
+	assert.Error(t, err)
+}
+
+func TestReadUserByNameIgnoresCase(t *testing.T) {
+	// Given
+	db := newTestAuthDatabase()
+	anna := user{Name: "Anna", PasswordHash: "test hash", Role: types.RoleEditor}
+	assert.NoError(t, db.createUser(&anna))
+
+	// When
+	read, err := db.readUserByName("anna")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, anna.ID, read.ID)
+	assert.Equal(t, "Anna", read.Name)
+}
+
+func TestMigrateUserNameIndex(t *testing.T) {
+	// Given
+	db := newTestAuthDatabase()
+	assert.NoError(t, db.handler.Exec("CREATE UNIQUE INDEX idx_users_name ON users(name)").Error)
+
+	// When
+	err := db.migrateUserNameIndex()
+
+	// Then
+	assert.NoError(t, err)
+	assert.False(t, db.handler.Migrator().HasIndex(&user{}, "idx_users_name"))
+	assert.True(t, db.handler.Migrator().HasIndex(&user{}, "idx_users_name_nocase"))
+
+	// When
+	err = db.migrateUserNameIndex()
+
+	// Then
+	assert.NoError(t, err)
 }
 
 func TestDoesAdminExist(t *testing.T) {
@@ -66,8 +144,18 @@ func TestDoesAdminExist(t *testing.T) {
 	assert.False(t, doesAdminExist)
 
 	// Given
-	admin := newTestAdmin()
-	assert.NoError(t, db.createAdmin(&admin))
+	assert.NoError(t, db.createUser(&user{Name: "Oma", Role: types.RoleEditor}))
+
+	// When
+	doesAdminExist, err = db.doesAdminExist()
+
+	// Then
+	assert.NoError(t, err)
+	assert.False(t, doesAdminExist)
+
+	// Given
+	user := newTestUser()
+	assert.NoError(t, db.createUser(&user))
 
 	// When
 	doesAdminExist, err = db.doesAdminExist()
@@ -77,88 +165,113 @@ func TestDoesAdminExist(t *testing.T) {
 	assert.True(t, doesAdminExist)
 }
 
-func TestReadAdmin(t *testing.T) {
+func TestReadUser(t *testing.T) {
 	// Given
 	db := newTestAuthDatabase()
 
 	// When
-	_, err := db.readAdmin()
+	_, err := db.readUser(1)
 
 	// Then
 	assert.Error(t, err)
 	appError, ok := err.(*errutil.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, http.StatusNotFound, appError.StatusCode)
-	assert.Equal(t, "Kein Admin gefunden", appError.UserMessage)
+	assert.Equal(t, "Benutzer nicht gefunden", appError.UserMessage)
+
+	// When
+	_, err = db.readUserByName("admin")
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
 
 	// Given
-	admin := newTestAdmin()
-	assert.NoError(t, db.createAdmin(&admin))
+	user := newTestUser()
+	assert.NoError(t, db.createUser(&user))
 
 	// When
-	retrievedAdmin, err := db.readAdmin()
+	retrievedUser, err := db.readUser(user.ID)
 
 	// Then
 	assert.NoError(t, err)
-	assert.Equal(t, admin.ID, retrievedAdmin.ID)
-	assert.Equal(t, admin.PasswordHash, retrievedAdmin.PasswordHash)
-}
-
-func TestReadAdminPasswordHash(t *testing.T) {
-	// Given
-	db := newTestAuthDatabase()
+	assert.Equal(t, user, retrievedUser)
 
 	// When
-	_, err := db.readAdmin()
+	retrievedUser, err = db.readUserByName("admin")
 
 	// Then
-	assert.Error(t, err)
-	appError, ok := err.(*errutil.AppError)
-	assert.True(t, ok)
-	assert.Equal(t, http.StatusNotFound, appError.StatusCode)
-	assert.Equal(t, "Kein Admin gefunden", appError.UserMessage)
+	assert.NoError(t, err)
+	assert.Equal(t, user, retrievedUser)
+}
 
+func TestReadUsers(t *testing.T) {
 	// Given
-	admin := newTestAdmin()
-	assert.NoError(t, db.createAdmin(&admin))
+	db := newTestAuthDatabase()
+	assert.NoError(t, db.createUser(&user{Name: "Papa", Role: types.RoleEditor}))
+	assert.NoError(t, db.createUser(&user{Name: "Oma", Role: types.RoleContributor}))
 
 	// When
-	passwordHash, err := db.readAdminPasswordHash()
+	users, err := db.readUsers()
 
 	// Then
 	assert.NoError(t, err)
-	assert.Equal(t, admin.PasswordHash, passwordHash)
+	assert.Equal(t, 2, len(users))
+	assert.Equal(t, "Oma", users[0].Name)
+	assert.Equal(t, "Papa", users[1].Name)
 }
 
-func TestUpdateAdminPassortHash(t *testing.T) {
+func TestUpdateUserPasswordHash(t *testing.T) {
 	// Given
 	db := newTestAuthDatabase()
 	updatedHash := "updated test hash"
 
 	// When
-	err := db.updateAdminPasswordHash(updatedHash)
+	err := db.updatePasswordHash(1, updatedHash)
 
 	// Then
-	assert.Error(t, err)
-	appError, ok := err.(*errutil.AppError)
-	assert.True(t, ok)
-	assert.Equal(t, http.StatusNotFound, appError.StatusCode)
-	assert.Equal(t, "Kein Admin gefunden", appError.UserMessage)
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// Given
+	user := newTestUser()
+	assert.NoError(t, db.createUser(&user))
+
+	// When
+	err = db.updatePasswordHash(user.ID, updatedHash)
 
+	// Then
+	assert.NoError(t, err)
+	retrievedUser, err := db.readUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, updatedHash, retrievedUser.PasswordHash)
+}
+
+func TestUpdateRoleAndDeleteUser(t *testing.T) {
 	// Given
-	admin := newTestAdmin()
-	assert.NoError(t, db.createAdmin(&admin))
+	db := newTestAuthDatabase()
+	user := user{Name: "Oma", Role: types.RoleContributor}
+	assert.NoError(t, db.createUser(&user))
 
 	// When
-	err = db.updateAdminPasswordHash(updatedHash)
+	err := db.updateRole(user.ID, types.RoleEditor)
 
 	// Then
 	assert.NoError(t, err)
+	retrievedUser, err := db.readUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.RoleEditor, retrievedUser.Role)
 
 	// When
-	retrievedAdmin, err := db.readAdmin()
+	err = db.deleteUser(user.ID)
 
 	// Then
 	assert.NoError(t, err)
-	assert.Equal(t, updatedHash, retrievedAdmin.PasswordHash)
+	_, err = db.readUser(user.ID)
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = db.deleteUser(user.ID)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(db.updateRole(user.ID, types.RoleAdmin)))
 }
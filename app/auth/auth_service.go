@@ -5,20 +5,25 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/kilianmandscharo/lethimcook/env"
 	"github.com/kilianmandscharo/lethimcook/errutil"
 	"github.com/kilianmandscharo/lethimcook/logging"
 	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	db         *authDatabase
-	privateKey string
-	logger     *logging.Logger
+	db          *authDatabase
+	privateKey  string
+	logger      *logging.Logger
+	deleteHooks []func(id uint) error
 }
 
 func NewAuthService(db *authDatabase, logger *logging.Logger) *AuthService {
@@ -29,6 +34,25 @@ func NewAuthService(db *authDatabase, logger *logging.Logger) *AuthService {
 	}
 }
 
+const (
+	defaultAdminName = "admin"
+	maxUserNameLen   = 50
+)
+
+// tokenClaims identify the logged in user. The user is read from the database
+// on every request, so that deleted users and changed roles take effect
+// immediately.
+type tokenClaims struct {
+	UserID uint `json:"uid"`
+	jwt.StandardClaims
+}
+
+// OnDeleteUser registers a hook that is called with the id of every deleted
+// user, so that other packages can clean up their references.
+func (as *AuthService) OnDeleteUser(hook func(id uint) error) {
+	as.deleteHooks = append(as.deleteHooks, hook)
+}
+
 func (as *AuthService) CreateAdminIfDoesNotExist(password string) {
 	if as.doesAdminExist() {
 		if len(password) > 0 {
@@ -44,50 +68,109 @@ func (as *AuthService) CreateAdminIfDoesNotExist(password string) {
 	}
 }
 
-func (as *AuthService) updateAdminPasswordHash(newPassword string) error {
-	if len(newPassword) < 5 {
+func (as *AuthService) validateNewPassword(password string) error {
+	if len(password) < 5 {
 		return &errutil.AppError{
 			UserMessage: "Invalides Passwort",
 			Err: fmt.Errorf(
-				"failed at updateAdminPasswordHash(): %w",
+				"failed at validateNewPassword(): %w",
 				errutil.FormErrorPasswortTooShort,
 			),
 			StatusCode: http.StatusBadRequest,
 		}
 	}
-	if len(newPassword) > 72 {
+	if len(password) > 72 {
 		return &errutil.AppError{
 			UserMessage: "Invalides Passwort",
 			Err: fmt.Errorf(
-				"failed at updateAdminPasswordHash(): %w",
+				"failed at validateNewPassword(): %w",
 				errutil.FormErrorPasswortTooLong,
 			),
 			StatusCode: http.StatusBadRequest,
 		}
 	}
+	return nil
+}
+
+func (as *AuthService) validateUserName(name string) error {
+	if len(name) == 0 {
+		return &errutil.AppError{
+			UserMessage: "Invalider Benutzername",
+			Err: fmt.Errorf(
+				"failed at validateUserName(): %w",
+				errutil.FormErrorNoUserName,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	if utf8.RuneCountInString(name) > maxUserNameLen {
+		return &errutil.AppError{
+			UserMessage: "Invalider Benutzername",
+			Err: fmt.Errorf(
+				"failed at validateUserName(): %w",
+				errutil.FormErrorUserNameTooLong,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+func (as *AuthService) validateRole(role types.Role) error {
+	if !role.IsValid() {
+		return &errutil.AppError{
+			UserMessage: "Ungültige Rolle",
+			Err:         fmt.Errorf("failed at validateRole(), invalid role %s", role),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+func (as *AuthService) updatePasswordHash(id uint, newPassword string) error {
+	if err := as.validateNewPassword(newPassword); err != nil {
+		return err
+	}
 	newPasswordHash, err := as.hashPassword(newPassword)
 	if err != nil {
 		return errutil.AddMessageToAppError(
 			err,
-			"failed at updateAdminPasswordHash()",
+			"failed at updatePasswordHash()",
 		)
 	}
-	err = as.db.updateAdminPasswordHash(newPasswordHash)
+	err = as.db.updatePasswordHash(id, newPasswordHash)
 	if err != nil {
 		return errutil.AddMessageToAppError(
 			err,
-			"failed at updateAdminPasswordHash()",
+			"failed at updatePasswordHash()",
 		)
 	}
 	return nil
 }
 
-func (as *AuthService) validatePassword(password string) error {
-	hash, err := as.db.readAdminPasswordHash()
+// login returns the user with the name if the password matches. Unknown
+// names fail the same way as wrong passwords.
+func (as *AuthService) login(name, password string) (types.User, error) {
+	user, err := as.db.readUserByName(name)
+	if err != nil && errutil.GetAppErrorStatusCode(err) != http.StatusNotFound {
+		return types.User{}, errutil.AddMessageToAppError(err, "failed at login()")
+	}
+	if err != nil || !as.matchPassword(password, user.PasswordHash) {
+		return types.User{}, &errutil.AppError{
+			UserMessage: "Falscher Benutzername oder Passwort",
+			Err:         fmt.Errorf("failed at login(), invalid login for %s", name),
+			StatusCode:  http.StatusUnauthorized,
+		}
+	}
+	return user.toUser(), nil
+}
+
+func (as *AuthService) validatePassword(id uint, password string) error {
+	user, err := as.db.readUser(id)
 	if err != nil {
 		return errutil.AddMessageToAppError(err, "failed at validatePassword()")
 	}
-	if !as.matchPassword(password, hash) {
+	if !as.matchPassword(password, user.PasswordHash) {
 		return &errutil.AppError{
 			UserMessage: "Falsches Passwort",
 			Err:         errors.New("failed at validatePassword(), invalid password"),
@@ -110,15 +193,117 @@ func (as *AuthService) createAdmin(password string) {
 	if err != nil {
 		as.logger.Fatal(err)
 	}
-	err = as.db.createAdmin(&admin{PasswordHash: passwordHash})
+	err = as.db.createUser(&user{Name: defaultAdminName, PasswordHash: passwordHash, Role: types.RoleAdmin})
 	if err != nil {
 		as.logger.Fatal(err)
 	}
 }
 
-func (as *AuthService) createToken() (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(60 * time.Minute).UnixMilli(),
+func (as *AuthService) createUser(name, password string, role types.Role) (types.User, error) {
+	name = strings.TrimSpace(name)
+	if err := as.validateUserName(name); err != nil {
+		return types.User{}, err
+	}
+	if err := as.validateNewPassword(password); err != nil {
+		return types.User{}, err
+	}
+	if err := as.validateRole(role); err != nil {
+		return types.User{}, err
+	}
+	passwordHash, err := as.hashPassword(password)
+	if err != nil {
+		return types.User{}, errutil.AddMessageToAppError(err, "failed at createUser()")
+	}
+	user := user{Name: name, PasswordHash: passwordHash, Role: role}
+	if err := as.db.createUser(&user); err != nil {
+		return types.User{}, errutil.AddMessageToAppError(err, "failed at createUser()")
+	}
+	return user.toUser(), nil
+}
+
+// ReadUser returns the account with the id, so that other packages can show
+// users without depending on the auth database.
+func (as *AuthService) ReadUser(id uint) (types.User, error) {
+	user, err := as.db.readUser(id)
+	if err != nil {
+		return types.User{}, errutil.AddMessageToAppError(err, "failed at ReadUser()")
+	}
+	return user.toUser(), nil
+}
+
+func (as *AuthService) readUsers() ([]types.User, error) {
+	users, err := as.db.readUsers()
+	if err != nil {
+		return nil, errutil.AddMessageToAppError(err, "failed at readUsers()")
+	}
+	result := make([]types.User, len(users))
+	for i, user := range users {
+		result[i] = user.toUser()
+	}
+	return result, nil
+}
+
+// updateRole changes the role of another user, admins can't change their own
+// role so that there is always an admin left.
+func (as *AuthService) updateRole(currentUserId, id uint, role types.Role) error {
+	if err := as.validateRole(role); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at updateRole()")
+	}
+	if currentUserId == id {
+		return &errutil.AppError{
+			UserMessage: "Die eigene Rolle kann nicht geändert werden",
+			Err:         fmt.Errorf("failed at updateRole(), user %d tried to change their own role", id),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	if err := as.db.updateRole(id, role); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at updateRole()")
+	}
+	return nil
+}
+
+func (as *AuthService) deleteUser(currentUserId, id uint) error {
+	if currentUserId == id {
+		return &errutil.AppError{
+			UserMessage: "Das eigene Konto kann nicht gelöscht werden",
+			Err:         fmt.Errorf("failed at deleteUser(), user %d tried to delete themselves", id),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	if err := as.db.deleteUser(id); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteUser()")
+	}
+	// The user is gone either way, a failing hook must not fail the deletion
+	for _, hook := range as.deleteHooks {
+		if err := hook(id); err != nil {
+			as.logger.Error(errutil.AddMessageToAppError(err, "failed at deleteUser()"))
+		}
+	}
+	return nil
+}
+
+func (as *AuthService) getPathUserId(c echo.Context) (uint, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, &errutil.AppError{
+			UserMessage: "Ungültiges Pfadparameter",
+			Err: fmt.Errorf(
+				"failed at getPathUserId() with parameter %s: %w",
+				c.Param("id"),
+				err,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return uint(id), nil
+}
+
+func (as *AuthService) createToken(user types.User) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
+		UserID: user.ID,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(60 * time.Minute).Unix(),
+		},
 	})
 	tokenString, err := token.SignedString([]byte(as.privateKey))
 	if err != nil {
@@ -162,15 +347,38 @@ func (as *AuthService) newTokenCookie(token string, expires time.Time) http.Cook
 	}
 }
 
-func (as *AuthService) validCookieToken(cookie *http.Cookie) bool {
-	token, err := jwt.Parse(cookie.Value, func(token *jwt.Token) (interface{}, error) {
+// parseCookieToken returns the user of a valid token as currently stored.
+func (as *AuthService) parseCookieToken(cookie *http.Cookie) (types.User, bool) {
+	var claims tokenClaims
+	token, err := jwt.ParseWithClaims(cookie.Value, &claims, func(token *jwt.Token) (interface{}, error) {
 		return []byte(as.privateKey), nil
 	})
-	return err == nil && token.Valid
+	if err != nil || !token.Valid {
+		return types.User{}, false
+	}
+	user, err := as.db.readUser(claims.UserID)
+	if err != nil {
+		if errutil.GetAppErrorStatusCode(err) != http.StatusNotFound {
+			as.logger.Error(errutil.AddMessageToAppError(err, "failed at parseCookieToken()"))
+		}
+		return types.User{}, false
+	}
+	if !user.Role.IsValid() {
+		return types.User{}, false
+	}
+	return user.toUser(), true
 }
 
 func (as *AuthService) createLoginForm(disabled bool, err error) []types.FormElement {
 	return []types.FormElement{
+		{
+			Type:      types.FormElementInput,
+			Name:      "name",
+			InputType: "text",
+			Label:     "Benutzername",
+			Required:  true,
+			Disabled:  disabled,
+		},
 		{
 			Type:      types.FormElementInput,
 			Name:      "password",
@@ -5,11 +5,14 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/kilianmandscharo/lethimcook/errutil"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,26 +52,26 @@ func TestCreateAdminIfDoesNotExist(t *testing.T) {
 	authService.CreateAdminIfDoesNotExist("")
 }
 
-func TestUpdateAdminPasswordHash(t *testing.T) {
+func TestUpdatePasswordHash(t *testing.T) {
 	// Given
 	authService := newTestAuthService()
 
 	// When
-	err := authService.updateAdminPasswordHash("test_password")
+	err := authService.updatePasswordHash(1, "test_password")
 
 	// Then
 	assert.Error(t, err)
 	appError, ok := err.(*errutil.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, http.StatusNotFound, appError.StatusCode)
-	assert.Equal(t, "Kein Admin gefunden", appError.UserMessage)
+	assert.Equal(t, "Benutzer nicht gefunden", appError.UserMessage)
 
 	// Given
-	admin := newTestAdmin()
-	assert.NoError(t, authService.db.createAdmin(&admin))
+	user := newTestUser()
+	assert.NoError(t, authService.db.createUser(&user))
 
 	// When
-	err = authService.updateAdminPasswordHash("aaaa")
+	err = authService.updatePasswordHash(user.ID, "aaaa")
 
 	// Then
 	assert.Error(t, err)
@@ -79,10 +82,11 @@ func TestUpdateAdminPasswordHash(t *testing.T) {
 	assert.Equal(t, "Invalides Passwort", appError.UserMessage)
 
 	// When
-	err = authService.updateAdminPasswordHash("test_password")
+	err = authService.updatePasswordHash(user.ID, "test_password")
 
 	// Then
 	assert.NoError(t, err)
+	assert.NoError(t, authService.validatePassword(user.ID, "test_password"))
 }
 
 func TestValidatePassword(t *testing.T) {
@@ -90,29 +94,29 @@ func TestValidatePassword(t *testing.T) {
 	authService := newTestAuthService()
 
 	// When
-	err := authService.validatePassword("test_password")
+	err := authService.validatePassword(1, "test_password")
 
 	// Then
 	assert.Error(t, err)
 	appError, ok := err.(*errutil.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, http.StatusNotFound, appError.StatusCode)
-	assert.Equal(t, "Kein Admin gefunden", appError.UserMessage)
+	assert.Equal(t, "Benutzer nicht gefunden", appError.UserMessage)
 
 	// Given
 	testHash, err := authService.hashPassword("test_password")
 	assert.NoError(t, err)
-	admin := admin{PasswordHash: testHash}
-	assert.NoError(t, authService.db.createAdmin(&admin))
+	user := user{Name: "admin", PasswordHash: testHash, Role: types.RoleAdmin}
+	assert.NoError(t, authService.db.createUser(&user))
 
 	// When
-	err = authService.validatePassword("test_password")
+	err = authService.validatePassword(user.ID, "test_password")
 
 	// Then
 	assert.NoError(t, err)
 
 	// When
-	err = authService.validatePassword("invalid_password")
+	err = authService.validatePassword(user.ID, "invalid_password")
 
 	// Then
 	assert.Error(t, err)
@@ -122,12 +126,154 @@ func TestValidatePassword(t *testing.T) {
 	assert.Equal(t, "Falsches Passwort", appError.UserMessage)
 }
 
+func TestLogin(t *testing.T) {
+	// Given
+	authService := newTestAuthService()
+	created, err := authService.createUser("Oma", "test_password", types.RoleEditor)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "Oma", password: "test_password"},
+		{name: "Oma", password: "invalid_password", wantErr: true},
+		{name: "Opa", password: "test_password", wantErr: true},
+	}
+
+	for _, test := range testCases {
+		// When
+		user, err := authService.login(test.name, test.password)
+
+		// Then
+		if test.wantErr {
+			assert.Equal(t, http.StatusUnauthorized, errutil.GetAppErrorStatusCode(err))
+			assert.Equal(t, "Falscher Benutzername oder Passwort", errutil.GetAppErrorUserMessage(err))
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, created, user)
+		}
+	}
+}
+
+func TestCreateUserAccount(t *testing.T) {
+	// Given
+	authService := newTestAuthService()
+
+	testCases := []struct {
+		name      string
+		password  string
+		role      types.Role
+		wantError error
+	}{
+		{name: " ", password: "test_password", role: types.RoleEditor, wantError: errutil.FormErrorNoUserName},
+		{name: strings.Repeat("a", 51), password: "test_password", role: types.RoleEditor, wantError: errutil.FormErrorUserNameTooLong},
+		{name: "Oma", password: "aaaa", role: types.RoleEditor, wantError: errutil.FormErrorPasswortTooShort},
+	}
+
+	for _, test := range testCases {
+		// When
+		_, err := authService.createUser(test.name, test.password, test.role)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+		assert.ErrorIs(t, err, test.wantError)
+	}
+
+	// When
+	_, err := authService.createUser("Oma", "test_password", types.Role("chef"))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	user, err := authService.createUser(" Oma ", "test_password", types.RoleContributor)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, types.User{ID: 1, Name: "Oma", Role: types.RoleContributor}, user)
+
+	// When
+	_, err = authService.createUser("Oma", "test_password", types.RoleEditor)
+
+	// Then
+	assert.Equal(t, http.StatusConflict, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	read, err := authService.ReadUser(user.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, user, read)
+
+	// When
+	_, err = authService.ReadUser(99)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestUpdateUserRoleAndDeleteUser(t *testing.T) {
+	// Given
+	authService := newTestAuthService()
+	authService.createAdmin("test_password")
+	oma, err := authService.createUser("Oma", "test_password", types.RoleContributor)
+	assert.NoError(t, err)
+	var deletedIds []uint
+	authService.OnDeleteUser(func(id uint) error {
+		deletedIds = append(deletedIds, id)
+		return nil
+	})
+
+	// When
+	err = authService.updateRole(1, oma.ID, types.Role("chef"))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = authService.updateRole(1, 1, types.RoleEditor)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = authService.updateRole(1, oma.ID, types.RoleEditor)
+
+	// Then
+	assert.NoError(t, err)
+	users, err := authService.readUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.User{
+		{ID: 1, Name: "admin", Role: types.RoleAdmin},
+		{ID: oma.ID, Name: "Oma", Role: types.RoleEditor},
+	}, users)
+
+	// When
+	err = authService.deleteUser(1, 1)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+	assert.Empty(t, deletedIds)
+
+	// When
+	err = authService.deleteUser(1, oma.ID)
+
+	// Then
+	assert.NoError(t, err)
+	users, err = authService.readUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, []uint{oma.ID}, deletedIds)
+}
+
 func TestCreateToken(t *testing.T) {
 	// Given
 	authService := newTestAuthService()
 
 	// When
-	token, err := authService.createToken()
+	token, err := authService.createToken(types.User{ID: 1, Name: "admin", Role: types.RoleAdmin})
 
 	// Then
 	assert.NoError(t, err)
@@ -182,10 +328,14 @@ func TestMatchPassword(t *testing.T) {
 	}
 }
 
-func TestValidCookieToken(t *testing.T) {
+func TestParseCookieToken(t *testing.T) {
 	// Given
 	authService := newTestAuthService()
-	token, err := authService.createToken()
+	user, err := authService.createUser("Oma", "test_password", types.RoleEditor)
+	assert.NoError(t, err)
+	token, err := authService.createToken(user)
+	assert.NoError(t, err)
+	unknownToken, err := authService.createToken(types.User{ID: 99, Name: "Opa", Role: types.RoleAdmin})
 	assert.NoError(t, err)
 
 	testCases := []struct {
@@ -200,9 +350,50 @@ func TestValidCookieToken(t *testing.T) {
 			cookie:    authService.newTokenCookie("invalid_token", time.Now().Add(60*time.Minute)),
 			wantValid: false,
 		},
+		{
+			cookie:    authService.newTokenCookie(unknownToken, time.Now().Add(60*time.Minute)),
+			wantValid: false,
+		},
 	}
 
 	for _, test := range testCases {
-		assert.Equal(t, test.wantValid, authService.validCookieToken(&test.cookie))
+		parsedUser, ok := authService.parseCookieToken(&test.cookie)
+		assert.Equal(t, test.wantValid, ok)
+		if test.wantValid {
+			assert.Equal(t, user, parsedUser)
+		}
 	}
+
+	// When
+	assert.NoError(t, authService.db.updateRole(user.ID, types.RoleContributor))
+	cookie := authService.newTokenCookie(token, time.Now().Add(60*time.Minute))
+	parsedUser, ok := authService.parseCookieToken(&cookie)
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, types.RoleContributor, parsedUser.Role)
+
+	// When
+	assert.NoError(t, authService.db.deleteUser(user.ID))
+	_, ok = authService.parseCookieToken(&cookie)
+
+	// Then
+	assert.False(t, ok)
+}
+
+func TestCreateTokenExpiresInSeconds(t *testing.T) {
+	// Given
+	authService := newTestAuthService()
+
+	// When
+	token, err := authService.createToken(types.User{ID: 1})
+
+	// Then
+	assert.NoError(t, err)
+	var claims tokenClaims
+	_, err = jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(authService.privateKey), nil
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(60*time.Minute).Unix(), claims.ExpiresAt, 5)
 }
@@ -1,13 +1,21 @@
 package cache
 
 import (
+	"sync"
+
 	"github.com/kilianmandscharo/lethimcook/logging"
 	"github.com/kilianmandscharo/lethimcook/types"
 )
 
+// RecipeCache is shared by the request handlers and the background purge of
+// the trash, so every access is guarded by mu.
 type RecipeCache struct {
+	mu      sync.RWMutex
 	recipes []types.Recipe
-	logger  *logging.Logger
+	// generation counts the invalidations, recipes read before an
+	// invalidation must not be cached after it
+	generation uint64
+	logger     *logging.Logger
 }
 
 func NewRecipeCache(logger *logging.Logger) *RecipeCache {
@@ -15,46 +23,69 @@ func NewRecipeCache(logger *logging.Logger) *RecipeCache {
 }
 
 func (r *RecipeCache) Get(isAdmin bool) *[]types.Recipe {
-	var recipes *[]types.Recipe
-	if isAdmin {
-		recipes = r.getWithPending()
-	} else {
-		recipes = r.getWithoutPending()
-	}
-	r.logger.Debugf("read %d recipes from cache", len(*recipes))
-	return recipes
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	recipes := filterRecipes(r.recipes, isAdmin)
+	r.logger.Debugf("read %d recipes from cache", len(recipes))
+	return &recipes
 }
 
-func (r *RecipeCache) getWithPending() *[]types.Recipe {
-	if r.recipes == nil {
-		return &[]types.Recipe{}
+// Load returns the cached recipes, or reads them with load and caches them
+// unless the cache was invalidated in the meantime.
+func (r *RecipeCache) Load(isAdmin bool, load func() ([]types.Recipe, error)) ([]types.Recipe, error) {
+	r.mu.RLock()
+	if r.recipes != nil {
+		recipes := filterRecipes(r.recipes, isAdmin)
+		r.mu.RUnlock()
+		r.logger.Debugf("read %d recipes from cache", len(recipes))
+		return recipes, nil
 	}
-	return &r.recipes
-}
+	generation := r.generation
+	r.mu.RUnlock()
 
-func (r *RecipeCache) getWithoutPending() *[]types.Recipe {
-	recipes := make([]types.Recipe, 0)
-	if r.recipes == nil {
-		return &recipes
+	recipes, err := load()
+	if err != nil {
+		return nil, err
 	}
-	for _, recipe := range r.recipes {
-		if !recipe.Pending {
+
+	r.mu.Lock()
+	if r.generation == generation {
+		r.recipes = recipes
+		r.logger.Debugf("put %d recipes into cache", len(recipes))
+	}
+	r.mu.Unlock()
+	return filterRecipes(recipes, isAdmin), nil
+}
+
+// filterRecipes returns a copy, so that callers can't change the cached
+// recipes while others read them.
+func filterRecipes(all []types.Recipe, isAdmin bool) []types.Recipe {
+	recipes := make([]types.Recipe, 0, len(all))
+	for _, recipe := range all {
+		if isAdmin || !recipe.Pending {
 			recipes = append(recipes, recipe)
 		}
 	}
-	return &recipes
+	return recipes
 }
 
 func (r *RecipeCache) Set(recipes []types.Recipe) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.recipes = recipes
 	r.logger.Debugf("put %d recipes into cache", len(recipes))
 }
 
 func (r *RecipeCache) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.recipes = nil
+	r.generation++
 	r.logger.Debug("invalidated cache")
 }
 
 func (r *RecipeCache) IsValid() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.recipes != nil
 }
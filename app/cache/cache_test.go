@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/kilianmandscharo/lethimcook/logging"
@@ -62,3 +63,64 @@ func TestGet(t *testing.T) {
 		{ID: 1},
 	}, *c.Get(false))
 }
+
+func TestLoad(t *testing.T) {
+	c := newTestRecipeCache()
+	loads := 0
+	load := func() ([]types.Recipe, error) {
+		loads++
+		return []types.Recipe{{ID: 1}, {ID: 2, Pending: true}}, nil
+	}
+
+	recipes, err := c.Load(false, load)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Recipe{{ID: 1}}, recipes)
+
+	recipes, err = c.Load(true, load)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Recipe{{ID: 1}, {ID: 2, Pending: true}}, recipes)
+	assert.Equal(t, 1, loads)
+
+	recipes[0].Title = "changed"
+	assert.Equal(t, "", (*c.Get(true))[0].Title)
+}
+
+func TestLoadInvalidatedWhileLoading(t *testing.T) {
+	c := newTestRecipeCache()
+
+	recipes, err := c.Load(true, func() ([]types.Recipe, error) {
+		c.Invalidate()
+		return []types.Recipe{{ID: 1}}, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Recipe{{ID: 1}}, recipes)
+	assert.False(t, c.IsValid())
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	c := newTestRecipeCache()
+	load := func() ([]types.Recipe, error) {
+		return []types.Recipe{{ID: 1}, {ID: 2, Pending: true}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Invalidate()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				recipes, err := c.Load(false, load)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(recipes))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,84 @@
+// Code generated by templ - DO NOT EDIT.
+
+// templ: version: v0.3.833
+package components
+
+//lint:file-ignore SA4006 This context is only used if a nested component is present.
+
+import "github.com/a-h/templ"
+import templruntime "github.com/a-h/templ/runtime"
+
+// InstructionsLabelButtons shows the image upload only to logged in users.
+func InstructionsLabelButtons(withImageUpload bool) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var1 == nil {
+			templ_7745c5c3_Var1 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"form-label-buttons\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if withImageUpload {
+			templ_7745c5c3_Err = instructionsImageButton().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = PreviewButton("instructions").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+func instructionsImageButton() templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var2 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var2 == nil {
+			templ_7745c5c3_Var2 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<label class=\"secondary-button\" title=\"Bild in die Anleitung einfügen, Bilder können auch eingefügt werden\">Bild <input id=\"instructions-image\" type=\"file\" name=\"instructionsImage\" accept=\"image/jpeg,image/png,image/gif,image/webp\" hidden hx-post=\"/recipe/instructions-image\" hx-encoding=\"multipart/form-data\" hx-params=\"instructionsImage\" hx-trigger=\"change\" hx-swap=\"none\"></label><script>\n        setTimeout(() => {\n            const input = document.getElementById(\"instructions-image\");\n            const instructions = document.getElementById(\"instructions\");\n            if (!input || !instructions) {\n                return;\n            }\n\n            input.addEventListener(\"htmx:afterRequest\", (event) => {\n                input.value = \"\";\n                if (!event.detail.successful) {\n                    return;\n                }\n                const snippet = \"\\n\" + event.detail.xhr.responseText + \"\\n\";\n                const start = instructions.selectionStart;\n                instructions.value =\n                    instructions.value.slice(0, start) +\n                    snippet +\n                    instructions.value.slice(instructions.selectionEnd);\n                instructions.selectionStart = instructions.selectionEnd = start + snippet.length;\n                instructions.focus();\n            });\n\n            instructions.addEventListener(\"paste\", (event) => {\n                const image = [...event.clipboardData.files].find((file) =>\n                    file.type.startsWith(\"image/\")\n                );\n                if (!image) {\n                    return;\n                }\n                event.preventDefault();\n                const transfer = new DataTransfer();\n                transfer.items.add(image);\n                input.files = transfer.files;\n                input.dispatchEvent(new Event(\"change\"));\n            });\n        }, 0);\n    </script>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
+var _ = templruntime.GeneratedTemplate
@@ -82,7 +82,7 @@ func PageControl(paginationInfo types.PaginationInfo, swapOob bool) templ.Compon
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\" hx-push-url=\"true\" hx-vals=\"js:{search: document.getElementById(&#39;search-input&#39;)?.value || &#39;&#39;}\">")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\" hx-push-url=\"true\" hx-include=\"#search-input, #tag-facets, #recipe-sort\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
@@ -8,9 +8,12 @@ package components
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/kilianmandscharo/lethimcook/types"
+import (
+	"github.com/kilianmandscharo/lethimcook/imageutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
 
-func RecipePage(isAdmin bool, recipe types.Recipe, tags []string) templ.Component {
+func RecipePage(user types.User, recipe types.Recipe, tags []string, units string, suggestions []types.RecipeSuggestionEntry) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
@@ -31,7 +34,7 @@ func RecipePage(isAdmin bool, recipe types.Recipe, tags []string) templ.Componen
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = header(isAdmin).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = header(user.Role.IsAdmin()).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -39,7 +42,13 @@ func RecipePage(isAdmin bool, recipe types.Recipe, tags []string) templ.Componen
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = recipePageInfoSection(isAdmin, recipe, tags).Render(ctx, templ_7745c5c3_Buffer)
+		if len(recipe.Image) > 0 {
+			templ_7745c5c3_Err = recipeImage(recipe.Image, imageutil.VariantLarge, imageutil.VariantLargeWebp, recipe.Title, "recipe-hero-image").Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = recipePageInfoSection(user, recipe, tags, units).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -59,7 +68,17 @@ func RecipePage(isAdmin bool, recipe types.Recipe, tags []string) templ.Componen
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div></section></div></main>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</div></section></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if user.CanModify(recipe) && len(suggestions) > 0 {
+			templ_7745c5c3_Err = recipeSuggestions(recipe.ID, suggestions).Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</main>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
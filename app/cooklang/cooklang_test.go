@@ -0,0 +1,173 @@
+package cooklang
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/ingredient"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRecipe = `---
+title: Käsespätzle
+description: "Ein Klassiker: schnell gemacht"
+servings: 4 Portionen
+tags: [Pasta, Käse]
+prep time: 20 min
+cook time: 1h 10m
+---
+
+-- Ein Kommentar
+> Am besten mit Salat.
+
+== Zwiebeln ==
+
+Die @Zwiebeln{2} in Ringe schneiden und in einer #Pfanne{} mit @Butter{30%g}(kalt)
+für ~{10%Minuten} braten. [- noch ein Kommentar -]
+
+== Spätzle ==
+
+@Spätzle{500%g} im #großen Topf{} kochen und mit @geriebenem Bergkäse{1.5%Tassen}
+schichten. Mit @Salz und @Pfeffer{} würzen.
+
+Im Ofen ~backen{15%Minuten} backen.
+`
+
+func TestParse(t *testing.T) {
+	// When
+	recipe, err := Parse("kaesespaetzle.cook", testRecipe)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, types.Recipe{
+		Title:         "Käsespätzle",
+		Description:   "Ein Klassiker: schnell gemacht",
+		Servings:      4,
+		Tags:          "Pasta, Käse",
+		Duration:      70,
+		TotalDuration: 90,
+		Ingredients: "### Zwiebeln\n\n" +
+			"- 2 Zwiebeln\n" +
+			"- 30 g Butter (kalt)\n\n" +
+			"### Spätzle\n\n" +
+			"- 500 g Spätzle\n" +
+			"- 1,5 Tassen geriebenem Bergkäse\n" +
+			"- Salz\n" +
+			"- Pfeffer",
+		Instructions: "**Utensilien:** Pfanne, großen Topf\n\n" +
+			"### Zwiebeln\n\n" +
+			"1. Die Zwiebeln in Ringe schneiden und in einer Pfanne mit Butter für 10 Minuten braten.\n\n" +
+			"### Spätzle\n\n" +
+			"1. Spätzle im großen Topf kochen und mit geriebenem Bergkäse schichten. Mit Salz und Pfeffer würzen.\n" +
+			"2. Im Ofen 15 Minuten backen.",
+	}, recipe)
+}
+
+func TestParseMetadataLines(t *testing.T) {
+	// Given
+	text := ">> source: https://example.com/pfannkuchen\n" +
+		">> author: Oma\n" +
+		">> time: 25 minutes\n" +
+		"\n" +
+		"@Eier{2} und @Milch{250%ml} verrühren.\n"
+
+	// When
+	recipe, err := Parse("uploads/Pfannkuchen.cook", text)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Pfannkuchen", recipe.Title)
+	assert.Equal(t, "https://example.com/pfannkuchen", recipe.Source)
+	assert.Equal(t, "Oma", recipe.Author)
+	assert.Equal(t, 25, recipe.TotalDuration)
+	assert.Equal(t, 25, recipe.Duration)
+	assert.Equal(t, "- 2 Eier\n- 250 ml Milch", recipe.Ingredients)
+	assert.Equal(t, "1. Eier und Milch verrühren.", recipe.Instructions)
+}
+
+func TestParseNoSteps(t *testing.T) {
+	_, err := Parse("leer.cook", "---\ntitle: Leer\n---\n-- nur ein Kommentar\n")
+	assert.ErrorIs(t, err, ErrNoSteps)
+}
+
+func TestParseTime(t *testing.T) {
+	testCases := []struct {
+		value   string
+		minutes int
+	}{
+		{value: "", minutes: 0},
+		{value: "45", minutes: 45},
+		{value: "45 minutes", minutes: 45},
+		{value: "1h 30m", minutes: 90},
+		{value: "2 hours", minutes: 120},
+		{value: "1,5 Stunden", minutes: 90},
+		{value: "1 Stunde 15 Minuten", minutes: 75},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.minutes, parseTime(test.value), test.value)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	// Given
+	recipe := types.Recipe{
+		Title:         "Pfannkuchen",
+		Description:   "Süß: und schnell",
+		Author:        "Oma",
+		Servings:      2,
+		Tags:          "Süß, Schnell",
+		Duration:      15,
+		TotalDuration: 25,
+		Ingredients:   "- 2 Eier\n- 1 1/2 EL Zucker\n- 250 ml Milch\n- Salz (eine Prise)",
+		Instructions:  "**Utensilien:** Schüssel, Pfanne\n\n1. Eier, Zucker und Milch in einer Schüssel verrühren.\n\n### Backen\n\n1. In der Pfanne 5 Minuten backen.",
+	}
+
+	// When
+	text := Format(recipe)
+
+	// Then
+	assert.Equal(
+		t,
+		"---\n"+
+			"title: Pfannkuchen\n"+
+			"description: \"Süß: und schnell\"\n"+
+			"author: Oma\n"+
+			"servings: 2\n"+
+			"tags:\n"+
+			"  - Süß\n"+
+			"  - Schnell\n"+
+			"cook time: 15 minutes\n"+
+			"time: 25 minutes\n"+
+			"---\n"+
+			"\n"+
+			"Zutaten: @Salz{}(eine Prise)\n"+
+			"\n"+
+			"@Eier{2}, @Zucker{1.5%EL} und @Milch{250%ml} in einer #Schüssel{} verrühren.\n"+
+			"\n"+
+			"== Backen ==\n"+
+			"\n"+
+			"In der #Pfanne{} ~{5%Minuten} backen.\n",
+		text,
+	)
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	// Given
+	recipe, err := Parse("kaesespaetzle.cook", testRecipe)
+	assert.NoError(t, err)
+
+	// When
+	parsed, err := Parse("export.cook", Format(recipe))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, recipe.Title, parsed.Title)
+	assert.Equal(t, recipe.Description, parsed.Description)
+	assert.Equal(t, recipe.Servings, parsed.Servings)
+	assert.Equal(t, recipe.Tags, parsed.Tags)
+	assert.Equal(t, recipe.Duration, parsed.Duration)
+	assert.Equal(t, recipe.TotalDuration, parsed.TotalDuration)
+	assert.Equal(t, recipe.Instructions, parsed.Instructions)
+	assert.Equal(t, ingredient.Parse(recipe.Ingredients), ingredient.Parse(parsed.Ingredients))
+}
@@ -0,0 +1,263 @@
+package cooklang
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kilianmandscharo/lethimcook/ingredient"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+var (
+	listItemPattern     = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.*)$`)
+	headingPattern      = regexp.MustCompile(`^\s*#{1,6}\s+(.+?)\s*#*\s*$`)
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	durationPattern     = regexp.MustCompile(`(\d+(?:[.,]\d+)?(?:\s*-\s*\d+)?)\s+(Minuten|Minute|Stunden|Stunde|Sekunden|minutes|minute|hours|hour|seconds)`)
+)
+
+// segment is a part of a step. Annotated segments are already written in
+// Cooklang syntax and are not searched for further annotations.
+type segment struct {
+	text      string
+	annotated bool
+}
+
+type step struct {
+	section  string
+	segments []segment
+}
+
+// Format writes a recipe in the Cooklang format. The fields of the recipe
+// become YAML front matter, every list item or paragraph of the instructions
+// a step. Ingredients and utensils are annotated where their name first
+// appears in the steps, the rest is listed in an extra first step.
+func Format(recipe types.Recipe) string {
+	var builder strings.Builder
+
+	builder.WriteString(formatFrontMatter(recipe))
+
+	steps, utensils := splitSteps(recipe.Instructions)
+	var missing []string
+
+	for _, i := range ingredient.Parse(recipe.Ingredients) {
+		annotation := formatIngredientAnnotation(i)
+		if !annotate(steps, i.Name, annotation) {
+			missing = append(missing, annotation)
+		}
+	}
+	var missingUtensils []string
+	for _, utensil := range utensils {
+		annotation := "#" + utensil + "{}"
+		if !annotate(steps, utensil, annotation) {
+			missingUtensils = append(missingUtensils, annotation)
+		}
+	}
+	for i := range steps {
+		annotateDurations(&steps[i])
+	}
+
+	if len(missing) > 0 {
+		builder.WriteString("\nZutaten: " + strings.Join(missing, ", ") + "\n")
+	}
+	if len(missingUtensils) > 0 {
+		builder.WriteString("\nUtensilien: " + strings.Join(missingUtensils, ", ") + "\n")
+	}
+
+	section := ""
+	for _, s := range steps {
+		if s.section != section {
+			section = s.section
+			builder.WriteString("\n== " + section + " ==\n")
+		}
+		builder.WriteString("\n")
+		for _, seg := range s.segments {
+			builder.WriteString(seg.text)
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+func formatFrontMatter(recipe types.Recipe) string {
+	var builder strings.Builder
+	builder.WriteString("---\n")
+
+	writeValue := func(key, value string) {
+		if len(value) > 0 {
+			builder.WriteString(fmt.Sprintf("%s: %s\n", key, quoteValue(value)))
+		}
+	}
+
+	writeValue("title", recipe.Title)
+	writeValue("description", recipe.Description)
+	writeValue("author", recipe.Author)
+	writeValue("source", recipe.Source)
+	if recipe.Servings > 0 {
+		writeValue("servings", strconv.Itoa(recipe.Servings))
+	}
+	if tags := recipe.ParseTags(); len(tags) > 0 {
+		builder.WriteString("tags:\n")
+		for _, tag := range tags {
+			builder.WriteString(fmt.Sprintf("  - %s\n", quoteValue(tag)))
+		}
+	}
+	if recipe.Duration > 0 {
+		writeValue("cook time", fmt.Sprintf("%d minutes", recipe.Duration))
+	}
+	if recipe.TotalDuration > 0 {
+		writeValue("time", fmt.Sprintf("%d minutes", recipe.TotalDuration))
+	}
+
+	builder.WriteString("---\n")
+	return builder.String()
+}
+
+// quoteValue quotes values that YAML would not read as plain strings.
+func quoteValue(value string) string {
+	value = strings.ReplaceAll(value, "\n", " ")
+	if strings.ContainsAny(value, ":#[]{}\"'") || strings.HasPrefix(value, "- ") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// splitSteps turns every list item and paragraph of the Markdown
+// instructions into a step. Headings set the section of the steps below
+// them, the utensils line of an imported recipe is returned separately.
+func splitSteps(markdown string) ([]step, []string) {
+	var steps []step
+	var utensils []string
+	section := ""
+
+	for _, line := range strings.Split(markdown, "\n") {
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			section = match[1]
+			continue
+		}
+		if list, found := strings.CutPrefix(strings.TrimSpace(line), UtensilsLabel); found {
+			for _, utensil := range strings.Split(list, ",") {
+				if utensil = strings.TrimSpace(utensil); len(utensil) > 0 {
+					utensils = append(utensils, utensil)
+				}
+			}
+			continue
+		}
+		if match := listItemPattern.FindStringSubmatch(line); match != nil {
+			line = match[1]
+		}
+		line = strings.TrimSpace(markdownLinkPattern.ReplaceAllString(line, "$1"))
+		if len(line) > 0 {
+			steps = append(steps, step{section: section, segments: []segment{{text: line}}})
+		}
+	}
+
+	return steps, utensils
+}
+
+func formatIngredientAnnotation(i types.Ingredient) string {
+	content := ""
+	if i.HasQuantity() {
+		content = formatQuantity(i.Quantity)
+		if i.QuantityMax > i.Quantity {
+			content += "-" + formatQuantity(i.QuantityMax)
+		}
+		if len(i.Unit) > 0 {
+			content += "%" + i.Unit
+		}
+	}
+	annotation := "@" + i.Name + "{" + content + "}"
+	if len(i.Note) > 0 {
+		annotation += "(" + i.Note + ")"
+	}
+	return annotation
+}
+
+// formatQuantity writes quantities as Cooklang expects them, fractions like
+// "1/2" are kept, everything else uses a decimal point.
+func formatQuantity(quantity float64) string {
+	if formatted := ingredient.FormatQuantity(quantity); !strings.ContainsAny(formatted, " ,") {
+		return formatted
+	}
+	return strconv.FormatFloat(quantity, 'f', -1, 64)
+}
+
+// annotate replaces the first whole word occurrence of name in the steps
+// with the annotation and reports whether name was found.
+func annotate(steps []step, name, annotation string) bool {
+	lowerName := strings.ToLower(name)
+	for i := range steps {
+		for j, seg := range steps[i].segments {
+			if seg.annotated {
+				continue
+			}
+			index := findWord(seg.text, lowerName)
+			if index < 0 {
+				continue
+			}
+			replaced := []segment{
+				{text: seg.text[:index]},
+				{text: annotation, annotated: true},
+				{text: seg.text[index+len(name):]},
+			}
+			segments := append([]segment{}, steps[i].segments[:j]...)
+			segments = append(segments, replaced...)
+			steps[i].segments = append(segments, steps[i].segments[j+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// findWord returns the byte index of the first occurrence of word in text
+// that is not part of a longer word, or -1. word has to be lower case.
+func findWord(text, word string) int {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) || len(word) == 0 {
+		return -1
+	}
+	for offset := 0; offset < len(lower); {
+		index := strings.Index(lower[offset:], word)
+		if index < 0 {
+			return -1
+		}
+		start, end := offset+index, offset+index+len(word)
+		before, _ := utf8.DecodeLastRuneInString(lower[:start])
+		after, _ := utf8.DecodeRuneInString(lower[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(lower) || !isWordRune(after)) {
+			return start
+		}
+		offset = start + 1
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// annotateDurations turns durations like "10 Minuten" into timers.
+func annotateDurations(s *step) {
+	var segments []segment
+	for _, seg := range s.segments {
+		if seg.annotated {
+			segments = append(segments, seg)
+			continue
+		}
+		last := 0
+		for _, match := range durationPattern.FindAllStringSubmatchIndex(seg.text, -1) {
+			segments = append(segments, segment{text: seg.text[last:match[0]]})
+			segments = append(segments, segment{
+				text:      "~{" + seg.text[match[2]:match[3]] + "%" + seg.text[match[4]:match[5]] + "}",
+				annotated: true,
+			})
+			last = match[1]
+		}
+		segments = append(segments, segment{text: seg.text[last:]})
+	}
+	s.segments = segments
+}
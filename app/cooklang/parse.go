@@ -0,0 +1,411 @@
+package cooklang
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+var (
+	ErrNoSteps = errors.New("no steps found")
+
+	blockCommentPattern = regexp.MustCompile(`(?s)\[-.*?-\]`)
+	sectionPattern      = regexp.MustCompile(`^=+\s*(.*?)\s*=*$`)
+	timePattern         = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(h|hr|hrs|hours?|std|stunden?|m|min|mins|minutes?|minuten?)?\b`)
+	decimalPattern      = regexp.MustCompile(`^(\d+)\.(\d+)$`)
+)
+
+// UtensilsLabel starts the line of the instructions that lists the cookware
+// of an imported recipe.
+const UtensilsLabel = "**Utensilien:**"
+
+type ingredientRef struct {
+	name     string
+	quantity string
+	unit     string
+	note     string
+}
+
+type section struct {
+	name        string
+	steps       []string
+	ingredients []ingredientRef
+}
+
+type parser struct {
+	metadata map[string]string
+	notes    []string
+	sections []*section
+	cookware []string
+}
+
+// Parse reads a recipe in the Cooklang format. Ingredients, cookware and
+// timers are taken from the annotations of the steps, the remaining fields
+// from the metadata, either as YAML front matter or as ">> key: value"
+// lines. name is the file name, which is used as title if the metadata has
+// none.
+func Parse(name string, text string) (types.Recipe, error) {
+	p := &parser{
+		metadata: make(map[string]string),
+		sections: []*section{{}},
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = p.parseFrontMatter(text)
+	text = blockCommentPattern.ReplaceAllString(text, "")
+
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			p.parseStep(strings.Join(paragraph, " "))
+			paragraph = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if index := strings.Index(line, "--"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case len(line) == 0:
+			flush()
+		case strings.HasPrefix(line, ">>"):
+			flush()
+			key, value, _ := strings.Cut(strings.TrimPrefix(line, ">>"), ":")
+			p.setMetadata(key, value)
+		case strings.HasPrefix(line, ">"):
+			flush()
+			p.notes = append(p.notes, strings.TrimSpace(strings.TrimPrefix(line, ">")))
+		case strings.HasPrefix(line, "="):
+			flush()
+			match := sectionPattern.FindStringSubmatch(line)
+			p.sections = append(p.sections, &section{name: match[1]})
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+
+	recipe := p.recipe()
+	if len(recipe.Title) == 0 {
+		recipe.Title = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+	if len(recipe.Instructions) == 0 {
+		return recipe, ErrNoSteps
+	}
+	return recipe, nil
+}
+
+// parseFrontMatter reads the YAML front matter at the start of text and
+// returns the rest. Only plain "key: value" pairs and lists of scalars are
+// supported, which is what Cooklang recipes use.
+func (p *parser) parseFrontMatter(text string) string {
+	trimmed := strings.TrimLeft(text, " \t\n")
+	if !strings.HasPrefix(trimmed, "---\n") {
+		return text
+	}
+	frontMatter, rest, found := strings.Cut(strings.TrimPrefix(trimmed, "---\n"), "\n---")
+	if !found {
+		return text
+	}
+	if index := strings.Index(rest, "\n"); index >= 0 {
+		rest = rest[index+1:]
+	} else {
+		rest = ""
+	}
+
+	key := ""
+	for _, line := range strings.Split(frontMatter, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+		if item, isItem := strings.CutPrefix(trimmedLine, "- "); isItem && len(key) > 0 {
+			value := unquote(item)
+			if existing := p.metadata[key]; len(existing) > 0 {
+				value = existing + ", " + value
+			}
+			p.metadata[key] = value
+			continue
+		}
+		k, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = normalizeKey(k)
+		p.setMetadata(k, value)
+	}
+
+	return rest
+}
+
+func (p *parser) setMetadata(key, value string) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		items := strings.Split(strings.Trim(value, "[]"), ",")
+		for i := range items {
+			items[i] = unquote(items[i])
+		}
+		value = strings.Join(items, ", ")
+	}
+	p.metadata[normalizeKey(key)] = unquote(value)
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	return strings.NewReplacer("_", " ", "-", " ").Replace(key)
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// parseStep replaces the annotations of a step with their plain text and
+// collects the ingredients and cookware.
+func (p *parser) parseStep(step string) {
+	current := p.sections[len(p.sections)-1]
+
+	var builder strings.Builder
+	for i := 0; i < len(step); {
+		marker := step[i]
+		if marker != '@' && marker != '#' && marker != '~' {
+			builder.WriteByte(marker)
+			i++
+			continue
+		}
+
+		name, content, note, end, ok := readAnnotation(step, i+1)
+		if !ok || (marker != '~' && len(name) == 0) {
+			builder.WriteByte(marker)
+			i++
+			continue
+		}
+		i = end
+
+		quantity, unit, _ := strings.Cut(content, "%")
+		quantity, unit = strings.TrimSpace(quantity), strings.TrimSpace(unit)
+
+		switch marker {
+		case '@':
+			current.ingredients = append(current.ingredients, ingredientRef{
+				name:     name,
+				quantity: quantity,
+				unit:     unit,
+				note:     note,
+			})
+			builder.WriteString(name)
+		case '#':
+			p.addCookware(name)
+			builder.WriteString(name)
+		case '~':
+			timer := strings.TrimSpace(quantity + " " + unit)
+			if len(timer) == 0 {
+				timer = name
+			}
+			builder.WriteString(timer)
+		}
+		if len(note) > 0 && marker != '@' {
+			builder.WriteString(" (" + note + ")")
+		}
+	}
+
+	if text := strings.TrimSpace(builder.String()); len(text) > 0 {
+		current.steps = append(current.steps, text)
+	}
+}
+
+// readAnnotation reads the name, the content of the braces and an optional
+// note in parentheses of an annotation starting at start. Names with more
+// than one word need braces, "@salt" ends at the first character that is not
+// part of a word.
+func readAnnotation(step string, start int) (string, string, string, int, bool) {
+	rest := step[start:]
+
+	name, end := "", 0
+	if brace := strings.Index(rest, "{"); brace >= 0 && !strings.ContainsAny(rest[:brace], "@#~{}") {
+		closing := strings.Index(rest[brace:], "}")
+		if closing < 0 {
+			return "", "", "", 0, false
+		}
+		name = strings.TrimSpace(rest[:brace])
+		content := rest[brace+1 : brace+closing]
+		end = brace + closing + 1
+		note, noteEnd := readNote(rest[end:])
+		return name, content, note, start + end + noteEnd, true
+	}
+
+	for index, r := range rest {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-') {
+			break
+		}
+		end = index + len(string(r))
+	}
+	name = rest[:end]
+	if len(name) == 0 {
+		return "", "", "", 0, false
+	}
+	note, noteEnd := readNote(rest[end:])
+	return name, "", note, start + end + noteEnd, true
+}
+
+func readNote(text string) (string, int) {
+	if !strings.HasPrefix(text, "(") {
+		return "", 0
+	}
+	closing := strings.Index(text, ")")
+	if closing < 0 {
+		return "", 0
+	}
+	return strings.TrimSpace(text[1:closing]), closing + 1
+}
+
+func (p *parser) addCookware(name string) {
+	for _, existing := range p.cookware {
+		if strings.EqualFold(existing, name) {
+			return
+		}
+	}
+	p.cookware = append(p.cookware, name)
+}
+
+func (p *parser) recipe() types.Recipe {
+	recipe := types.Recipe{
+		Title:        p.firstMetadata("title"),
+		Description:  p.firstMetadata("description", "introduction"),
+		Author:       p.firstMetadata("author", "source author"),
+		Source:       p.firstMetadata("source", "source url", "url"),
+		Servings:     parseServings(p.firstMetadata("servings", "serves", "yield")),
+		Tags:         p.firstMetadata("tags", "tag", "keywords"),
+		Ingredients:  p.formatIngredients(),
+		Instructions: p.formatInstructions(),
+	}
+	if len(recipe.Description) == 0 {
+		recipe.Description = strings.Join(p.notes, " ")
+	}
+
+	cookTime := parseTime(p.firstMetadata("cook time", "cooking time"))
+	prepTime := parseTime(p.firstMetadata("prep time", "preparation time"))
+	totalTime := parseTime(p.firstMetadata("time", "total time", "duration"))
+	if totalTime == 0 {
+		totalTime = prepTime + cookTime
+	}
+	if cookTime == 0 {
+		cookTime = totalTime - prepTime
+	}
+	recipe.Duration = cookTime
+	recipe.TotalDuration = totalTime
+
+	return recipe
+}
+
+func (p *parser) firstMetadata(keys ...string) string {
+	for _, key := range keys {
+		if value := p.metadata[key]; len(value) > 0 {
+			return value
+		}
+	}
+	return ""
+}
+
+func parseServings(value string) int {
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		servings, err := strconv.Atoi(field)
+		if err == nil {
+			return servings
+		}
+	}
+	return 0
+}
+
+// parseTime converts times like "1h 30m", "90 minutes" or "1 Stunde" into
+// minutes. Numbers without a unit are minutes.
+func parseTime(value string) int {
+	minutes := 0.0
+	for _, match := range timePattern.FindAllStringSubmatch(value, -1) {
+		number, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+		if err != nil {
+			continue
+		}
+		if unit := strings.ToLower(match[2]); strings.HasPrefix(unit, "h") || strings.HasPrefix(unit, "st") {
+			number *= 60
+		}
+		minutes += number
+	}
+	return int(minutes + 0.5)
+}
+
+func (p *parser) formatIngredients() string {
+	var blocks []string
+	for _, s := range p.sections {
+		var lines []string
+		seen := make(map[ingredientRef]bool)
+		for _, ingredient := range s.ingredients {
+			if seen[ingredient] {
+				continue
+			}
+			seen[ingredient] = true
+			lines = append(lines, "- "+formatIngredient(ingredient))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		if len(s.name) > 0 && len(p.sections) > 1 {
+			blocks = append(blocks, "### "+s.name)
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func formatIngredient(ingredient ingredientRef) string {
+	quantity := ingredient.quantity
+	if match := decimalPattern.FindStringSubmatch(quantity); match != nil {
+		quantity = match[1] + "," + match[2]
+	}
+	parts := []string{}
+	for _, part := range []string{quantity, ingredient.unit, ingredient.name} {
+		if len(part) > 0 {
+			parts = append(parts, part)
+		}
+	}
+	if len(ingredient.note) > 0 {
+		parts = append(parts, "("+ingredient.note+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+func (p *parser) formatInstructions() string {
+	var blocks []string
+	if len(p.cookware) > 0 {
+		blocks = append(blocks, UtensilsLabel+" "+strings.Join(p.cookware, ", "))
+	}
+	for _, s := range p.sections {
+		if len(s.steps) == 0 {
+			continue
+		}
+		if len(s.name) > 0 {
+			blocks = append(blocks, "### "+s.name)
+		}
+		lines := make([]string, len(s.steps))
+		for i, step := range s.steps {
+			lines[i] = fmt.Sprintf("%d. %s", i+1, step)
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	if len(blocks) == 1 && len(p.cookware) > 0 {
+		return ""
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -8,9 +8,16 @@ import (
 )
 
 const (
-	EnvKeyCertFilePath  = "CERT_FILE_PATH"
-	EnvKeyKeyFilePath   = "KEY_FILE_PATH"
-	EnvKeyJWTPrivateKey = "JWT_PRIVATE_KEY"
+	EnvKeyCertFilePath          = "CERT_FILE_PATH"
+	EnvKeyKeyFilePath           = "KEY_FILE_PATH"
+	EnvKeyJWTPrivateKey         = "JWT_PRIVATE_KEY"
+	EnvKeyImageDir              = "IMAGE_DIR"
+	EnvKeyTrashRetentionDays    = "TRASH_RETENTION_DAYS"
+	EnvKeySpamRateLimit         = "SPAM_RATE_LIMIT"
+	EnvKeySpamRateWindowMinutes = "SPAM_RATE_WINDOW_MINUTES"
+	EnvKeySpamMinFillSeconds    = "SPAM_MIN_FILL_SECONDS"
+	EnvKeySpamMaxLinks          = "SPAM_MAX_LINKS"
+	EnvKeySpamBannedWordsFile   = "SPAM_BANNED_WORDS_FILE"
 )
 
 func LoadEnvironment(envName string, logger *logging.Logger) {
@@ -32,6 +32,14 @@ func NewAppErrorNotAuthorized(functionName string) error {
 	}
 }
 
+func NewAppErrorForbidden(functionName string) error {
+	return &AppError{
+		UserMessage: "Keine Berechtigung",
+		Err:         errors.New(fmt.Sprintf("failed at %s, forbidden", functionName)),
+		StatusCode:  http.StatusForbidden,
+	}
+}
+
 func AddMessageToAppError(err error, message string) error {
 	if appError, ok := err.(*AppError); ok {
 		appError.AddMessage(message)
@@ -62,7 +70,13 @@ var (
 	FormErrorNoDescription     = errors.New("Bitte trage eine Rezeptbeschreibung ein")
 	FormErrorNoCookingDuration = errors.New("Bitte trage die Kochzeit ein")
 	FormErrorNoTotalDuration   = errors.New("Bitte trage die Gesamtzeit ein")
+	FormErrorInvalidServings   = errors.New("Bitte trage eine gültige Portionsanzahl ein")
 	FormErrorNoIngredients     = errors.New("Bitte trage die Rezeptzutaten ein")
 	FormErrorNoInstructions    = errors.New("Bitte trage die Rezeptanleitung ein")
 	FormErrorInvalidPassword   = errors.New("Falsches Passwort")
+	FormErrorInvalidLogin      = errors.New("Falscher Benutzername oder Passwort")
+	FormErrorNoUserName        = errors.New("Bitte trage einen Benutzernamen ein")
+	FormErrorUserNameTooLong   = errors.New("Maximale Länge des Benutzernamens: 50")
+	FormErrorInvalidImage      = errors.New("Bitte lade ein JPEG-, PNG-, GIF- oder WebP-Bild hoch")
+	FormErrorImageTooLarge     = errors.New("Das Bild ist zu groß, erlaubt sind höchstens 10 MB")
 )
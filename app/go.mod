@@ -3,6 +3,7 @@ module github.com/kilianmandscharo/lethimcook
 go 1.23.1
 
 require (
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/a-h/templ v0.3.833
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/joho/godotenv v1.5.1
@@ -10,6 +11,8 @@ require (
 	github.com/stretchr/testify v1.8.4
 	github.com/yuin/goldmark v1.7.0
 	golang.org/x/crypto v0.31.0
+	golang.org/x/image v0.18.0
+	golang.org/x/net v0.33.0
 	gorm.io/driver/sqlite v1.5.4
 	gorm.io/gorm v1.25.6
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
@@ -26,7 +29,6 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
-	golang.org/x/net v0.33.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -0,0 +1,264 @@
+package imageutil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	MaxUploadSize = 10 << 20
+	// maxPixels guards against images that are small on disk but huge once
+	// decoded.
+	maxPixels = 40_000_000
+
+	VariantThumbnail     = "thumbnail.jpg"
+	VariantThumbnailWebp = "thumbnail.webp"
+	VariantLarge         = "large.jpg"
+	VariantLargeWebp     = "large.webp"
+
+	thumbnailWidth = 480
+	largeWidth     = 1600
+	jpegQuality    = 85
+)
+
+var (
+	ErrTooLarge        = errors.New("image too large")
+	ErrUnsupportedType = errors.New("unsupported image type")
+)
+
+var (
+	keyPattern = regexp.MustCompile("^[0-9a-f]{32}$")
+	// urlPattern matches the urls of images embedded in markdown
+	urlPattern = regexp.MustCompile("/images/([0-9a-f]{32})/")
+)
+
+type format struct {
+	extension    string
+	decode       func(r io.Reader) (image.Image, error)
+	decodeConfig func(r io.Reader) (image.Config, error)
+}
+
+// formats are the accepted content types.
+var formats = map[string]format{
+	"image/jpeg": {extension: ".jpg", decode: jpeg.Decode, decodeConfig: jpeg.DecodeConfig},
+	"image/png":  {extension: ".png", decode: png.Decode, decodeConfig: png.DecodeConfig},
+	"image/gif":  {extension: ".gif", decode: gif.Decode, decodeConfig: gif.DecodeConfig},
+	"image/webp": {extension: ".webp", decode: webp.Decode, decodeConfig: webp.DecodeConfig},
+}
+
+// Store keeps uploaded images on disk. Every image gets its own directory
+// named by a random key that holds the original and the resized variants.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	if len(dir) == 0 {
+		dir = "./images"
+	}
+	return &Store{dir: dir}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Url is the path an image variant is served at.
+func Url(key, variant string) string {
+	return fmt.Sprintf("/images/%s/%s", key, variant)
+}
+
+// KeysInText returns the keys of the images the text links to, like the
+// instructions of a recipe do.
+func KeysInText(text string) []string {
+	keys := []string{}
+	for _, match := range urlPattern.FindAllStringSubmatch(text, -1) {
+		keys = append(keys, match[1])
+	}
+	return keys
+}
+
+// StoredImage is an image in the store together with the time it was saved.
+type StoredImage struct {
+	Key     string
+	SavedAt time.Time
+}
+
+// List returns all images in the store. Entries that are not images of the
+// store are ignored.
+func (s *Store) List() ([]StoredImage, error) {
+	images := []StoredImage{}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return images, nil
+		}
+		return images, fmt.Errorf("failed at List(): %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !keyPattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return images, fmt.Errorf("failed at List(): %w", err)
+		}
+		images = append(images, StoredImage{Key: entry.Name(), SavedAt: info.ModTime()})
+	}
+	return images, nil
+}
+
+// Validate reads the uploaded data and returns it together with the decoded
+// image if it is a JPEG, PNG, GIF or WebP image of at most MaxUploadSize
+// bytes.
+func Validate(r io.Reader) ([]byte, image.Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUploadSize+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed at Validate(): %w", err)
+	}
+	if len(data) > MaxUploadSize {
+		return nil, nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	format, ok := formats[contentType]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	config, err := format.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrUnsupportedType, err)
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, nil, ErrTooLarge
+	}
+
+	img, err := format.decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrUnsupportedType, err)
+	}
+	return data, img, nil
+}
+
+// Save stores the original data and writes a thumbnail and a large variant,
+// each as JPEG and WebP, and returns the key of the new image.
+func (s *Store) Save(data []byte, img image.Image) (string, error) {
+	key, err := newKey()
+	if err != nil {
+		return "", fmt.Errorf("failed at Save(): %w", err)
+	}
+
+	dir := filepath.Join(s.dir, key)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed at Save(): %w", err)
+	}
+
+	err = s.writeVariants(dir, data, img)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed at Save(): %w", err)
+	}
+	return key, nil
+}
+
+func (s *Store) writeVariants(dir string, data []byte, img image.Image) error {
+	extension := formats[http.DetectContentType(data)].extension
+	if err := os.WriteFile(filepath.Join(dir, "original"+extension), data, 0o644); err != nil {
+		return err
+	}
+
+	for _, variant := range []struct {
+		width    int
+		jpegName string
+		webpName string
+	}{
+		{width: thumbnailWidth, jpegName: VariantThumbnail, webpName: VariantThumbnailWebp},
+		{width: largeWidth, jpegName: VariantLarge, webpName: VariantLargeWebp},
+	} {
+		resized := Resize(img, variant.width)
+		err := writeFile(filepath.Join(dir, variant.jpegName), func(w io.Writer) error {
+			return jpeg.Encode(w, resized, &jpeg.Options{Quality: jpegQuality})
+		})
+		if err != nil {
+			return err
+		}
+		err = writeFile(filepath.Join(dir, variant.webpName), func(w io.Writer) error {
+			return nativewebp.Encode(w, resized, nil)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, encode func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encode(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Delete removes the image with all of its variants. Deleting an image that
+// does not exist is not an error.
+func (s *Store) Delete(key string) error {
+	if len(key) == 0 {
+		return nil
+	}
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("failed at Delete(), invalid key %s", key)
+	}
+	if err := os.RemoveAll(filepath.Join(s.dir, key)); err != nil {
+		return fmt.Errorf("failed at Delete() with key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Resize scales the image down to the given width, keeping the aspect ratio.
+// Smaller images are only converted, never scaled up. The result is always
+// opaque, transparent areas become white.
+func Resize(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() > width {
+		height := max(1, bounds.Dy()*width/bounds.Dx())
+		bounds = image.Rect(0, 0, width, height)
+	} else {
+		bounds = image.Rect(0, 0, bounds.Dx(), bounds.Dy())
+	}
+
+	resized := image.NewRGBA(bounds)
+	draw.Draw(resized, bounds, image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(resized, bounds, img, img.Bounds(), draw.Over, nil)
+	return resized
+}
+
+func newKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
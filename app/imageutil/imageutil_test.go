@@ -0,0 +1,134 @@
+package imageutil
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPng(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestValidate(t *testing.T) {
+	// When
+	data, img, err := Validate(bytes.NewReader(newTestPng(t, 20, 10)))
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, data)
+	assert.Equal(t, 20, img.Bounds().Dx())
+
+	// When
+	_, _, err = Validate(bytes.NewReader([]byte("kein Bild")))
+
+	// Then
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	// When
+	_, _, err = Validate(bytes.NewReader(make([]byte, MaxUploadSize+1)))
+
+	// Then
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestResize(t *testing.T) {
+	testCases := []struct {
+		width, height int
+		target        int
+		wantWidth     int
+		wantHeight    int
+	}{
+		{width: 2000, height: 1000, target: 480, wantWidth: 480, wantHeight: 240},
+		{width: 300, height: 200, target: 480, wantWidth: 300, wantHeight: 200},
+		{width: 2000, height: 1, target: 480, wantWidth: 480, wantHeight: 1},
+	}
+
+	for _, test := range testCases {
+		// When
+		resized := Resize(image.NewRGBA(image.Rect(0, 0, test.width, test.height)), test.target)
+
+		// Then
+		assert.Equal(t, test.wantWidth, resized.Bounds().Dx())
+		assert.Equal(t, test.wantHeight, resized.Bounds().Dy())
+	}
+}
+
+func TestSaveAndDelete(t *testing.T) {
+	// Given
+	store := NewStore(t.TempDir())
+	data, img, err := Validate(bytes.NewReader(newTestPng(t, 40, 30)))
+	assert.NoError(t, err)
+
+	// When
+	key, err := store.Save(data, img)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Regexp(t, "^[0-9a-f]{32}$", key)
+	for _, name := range []string{"original.png", VariantThumbnail, VariantThumbnailWebp, VariantLarge, VariantLargeWebp} {
+		assert.FileExists(t, filepath.Join(store.Dir(), key, name))
+	}
+
+	// When
+	err = store.Delete(key)
+
+	// Then
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(store.Dir(), key))
+	assert.True(t, os.IsNotExist(err))
+
+	// When
+	err = store.Delete("../recipe")
+
+	// Then
+	assert.Error(t, err)
+}
+
+func TestList(t *testing.T) {
+	// Given
+	store := NewStore(t.TempDir())
+	data, img, err := Validate(bytes.NewReader(newTestPng(t, 40, 30)))
+	assert.NoError(t, err)
+	key, err := store.Save(data, img)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Mkdir(filepath.Join(store.Dir(), "other"), 0o755))
+
+	// When
+	images, err := store.List()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(images))
+	assert.Equal(t, key, images[0].Key)
+
+	// When
+	images, err = NewStore(filepath.Join(store.Dir(), "missing")).List()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestKeysInText(t *testing.T) {
+	key := "0123456789abcdef0123456789abcdef"
+	text := fmt.Sprintf("Teig kneten\n\n![](%s)\n\n![](/images/zu-kurz/large.jpg)", Url(key, VariantLarge))
+
+	assert.Equal(t, []string{key}, KeysInText(text))
+	assert.Empty(t, KeysInText("Teig kneten"))
+}
@@ -0,0 +1,121 @@
+package ingredient
+
+import "strings"
+
+const CategoryOther = "Sonstiges"
+
+type category struct {
+	name     string
+	keywords []string
+}
+
+// categories are checked in order and the first category with a keyword
+// contained in the ingredient name wins, so more specific keywords like
+// "paprikapulver" or "tomatenmark" have to come before "paprika" or
+// "tomate".
+var categories = []category{
+	{
+		name: "Gewürze & Öle",
+		keywords: []string{
+			"salz", "pfeffer", "paprikapulver", "chiliflocken", "kreuzkümmel",
+			"curry", "kurkuma", "zimt", "muskat", "oregano", "thymian",
+			"rosmarin", "lorbeer", "nelke", "kardamom", "öl", "olivenöl",
+			"rapsöl", "sonnenblumenöl", "sesamöl", "kokosöl", "essig", "senf",
+			"sojasauce", "sojasoße", "brühe", "fond", "honig", "sirup",
+		},
+	},
+	{
+		name: "Konserven & Vorrat",
+		keywords: []string{
+			"tomatenmark", "passierte", "gehackte tomaten", "kichererbse",
+			"kidneybohne", "kokosmilch", "mais", "linse", "oliven", "kapern",
+			"nüsse", "nuss", "mandel", "sesam", "rosine",
+		},
+	},
+	{
+		name: "Obst & Gemüse",
+		keywords: []string{
+			"apfel", "äpfel", "banane", "zitrone", "limette", "orange",
+			"beere", "tomate", "frühlingszwiebel", "zwiebel", "schalotte",
+			"knoblauch", "kartoffel", "karotte", "möhre", "paprika", "gurke",
+			"salat", "spinat", "zucchini", "aubergine", "pilz", "champignon",
+			"lauch", "porree", "sellerie", "ingwer", "petersilie", "basilikum",
+			"koriander", "schnittlauch", "dill", "minze", "kohl", "brokkoli",
+			"avocado", "chili", "kürbis", "erbse", "bohne", "rucola",
+			"spargel", "rote bete", "süßkartoffel", "mango",
+		},
+	},
+	{
+		name: "Milchprodukte & Eier",
+		keywords: []string{
+			"milch", "sahne", "butter", "käse", "joghurt", "quark", "schmand",
+			"crème fraîche", "creme fraiche", "mozzarella", "parmesan", "feta",
+			"mascarpone", "ricotta", "ei", "eigelb", "eiweiß",
+		},
+	},
+	{
+		name: "Fleisch & Fisch",
+		keywords: []string{
+			"hähnchen", "huhn", "hühner", "pute", "rind", "schwein", "hack",
+			"speck", "schinken", "wurst", "lamm", "lachs", "thunfisch",
+			"fisch", "garnele", "shrimp", "chorizo",
+		},
+	},
+	{
+		name: "Backen",
+		keywords: []string{
+			"mehl", "zucker", "backpulver", "natron", "hefe", "vanille",
+			"stärke", "kakao", "schokolade", "kuvertüre", "gelatine",
+		},
+	},
+	{
+		name: "Nudeln, Reis & Brot",
+		keywords: []string{
+			"nudel", "spaghetti", "pasta", "penne", "lasagne", "reis",
+			"couscous", "bulgur", "quinoa", "haferflocken", "brot", "brötchen",
+			"tortilla", "wrap", "naan", "semmelbrösel", "paniermehl",
+		},
+	},
+}
+
+// Categories returns the names of all categories in the order in which they
+// should be listed, followed by CategoryOther.
+func Categories() []string {
+	names := make([]string, 0, len(categories)+1)
+	for _, c := range categories {
+		names = append(names, c.name)
+	}
+	return append(names, CategoryOther)
+}
+
+// Category returns the shopping category of an ingredient, e.g. "Backen" for
+// "Weizenmehl", or CategoryOther if the name matches no category.
+func Category(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	normalized := NormalizeName(name)
+	for _, c := range categories {
+		for _, keyword := range c.keywords {
+			if matchesKeyword(words, normalized, keyword) {
+				return c.name
+			}
+		}
+	}
+	return CategoryOther
+}
+
+// matchesKeyword matches keywords of up to two letters only against whole
+// words, so that "ei" matches "Eier" but not "Reis".
+func matchesKeyword(words []string, normalized, keyword string) bool {
+	if len([]rune(keyword)) > 2 {
+		return strings.Contains(strings.Join(words, " "), keyword)
+	}
+	if normalized == keyword {
+		return true
+	}
+	for _, word := range words {
+		if NormalizeName(word) == keyword {
+			return true
+		}
+	}
+	return false
+}
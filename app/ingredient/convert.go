@@ -0,0 +1,249 @@
+package ingredient
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+type UnitSystem string
+
+const (
+	UnitSystemMetric   UnitSystem = "metric"
+	UnitSystemImperial UnitSystem = "imperial"
+)
+
+func ParseUnitSystem(value string) (UnitSystem, bool) {
+	switch UnitSystem(value) {
+	case UnitSystemMetric:
+		return UnitSystemMetric, true
+	case UnitSystemImperial:
+		return UnitSystemImperial, true
+	}
+	return "", false
+}
+
+type dimension int
+
+const (
+	dimensionMass dimension = iota + 1
+	dimensionVolume
+)
+
+type conversion struct {
+	dimension dimension
+	// base is the amount of the unit in grams or milliliters
+	base float64
+}
+
+var conversions = map[string]conversion{
+	"mg":    {dimensionMass, 0.001},
+	"g":     {dimensionMass, 1},
+	"kg":    {dimensionMass, 1000},
+	"oz":    {dimensionMass, 28.349523125},
+	"lb":    {dimensionMass, 453.59237},
+	"ml":    {dimensionVolume, 1},
+	"cl":    {dimensionVolume, 10},
+	"dl":    {dimensionVolume, 100},
+	"l":     {dimensionVolume, 1000},
+	"fl oz": {dimensionVolume, 29.5735295625},
+	"cup":   {dimensionVolume, 236.5882365},
+	"pint":  {dimensionVolume, 473.176473},
+	"quart": {dimensionVolume, 946.352946},
+	"tbsp":  {dimensionVolume, 14.78676478125},
+	"tsp":   {dimensionVolume, 4.92892159375},
+}
+
+// spoonUnits maps units that exist in both systems with the same size and
+// only need to be renamed.
+var spoonUnits = map[UnitSystem]map[string]string{
+	UnitSystemMetric: {
+		"tbsp":  "EL",
+		"tsp":   "TL",
+		"pinch": "Prise",
+	},
+	UnitSystemImperial: {
+		"EL":    "tbsp",
+		"TL":    "tsp",
+		"Prise": "pinch",
+	},
+}
+
+// ConvertQuantity converts a quantity of unit into the given unit system and
+// picks a unit of sensible size for the result, e.g. 2 lb become 910 g and
+// 1 kg becomes 2 1/4 lb. Units that cannot be converted, like "Bund" or
+// "Stück", are returned unchanged with ok == false.
+func ConvertQuantity(quantity float64, unit string, system UnitSystem) (float64, string, bool) {
+	if renamed, ok := spoonUnits[system][unit]; ok {
+		return quantity, renamed, true
+	}
+
+	c, ok := conversions[unit]
+	if !ok || unitSystemOf(unit) == system {
+		return quantity, unit, false
+	}
+
+	base := quantity * c.base
+	target := pickTargetUnit(base, c.dimension, system)
+	return roundConverted(base/conversions[target].base, target), target, true
+}
+
+func unitSystemOf(unit string) UnitSystem {
+	switch unit {
+	case "oz", "lb", "fl oz", "cup", "pint", "quart", "tbsp", "tsp", "pinch":
+		return UnitSystemImperial
+	}
+	return UnitSystemMetric
+}
+
+func pickTargetUnit(base float64, d dimension, system UnitSystem) string {
+	switch {
+	case system == UnitSystemMetric && d == dimensionMass:
+		if base >= 1000 {
+			return "kg"
+		}
+		return "g"
+	case system == UnitSystemMetric && d == dimensionVolume:
+		if base >= 1000 {
+			return "l"
+		}
+		return "ml"
+	case system == UnitSystemImperial && d == dimensionMass:
+		if base >= conversions["lb"].base {
+			return "lb"
+		}
+		return "oz"
+	default:
+		if base >= conversions["cup"].base/4 {
+			return "cup"
+		}
+		if base >= 14 {
+			return "tbsp"
+		}
+		return "tsp"
+	}
+}
+
+func roundConverted(quantity float64, unit string) float64 {
+	switch unit {
+	case "g", "ml":
+		switch {
+		case quantity < 10:
+			return math.Max(math.Round(quantity*2)/2, 0.5)
+		case quantity < 100:
+			return math.Round(quantity/5) * 5
+		default:
+			return math.Round(quantity/10) * 10
+		}
+	case "kg", "l":
+		return math.Round(quantity*20) / 20
+	case "oz", "cup", "lb":
+		return math.Max(math.Round(quantity*4)/4, 0.25)
+	default:
+		return math.Max(math.Round(quantity*2)/2, 0.5)
+	}
+}
+
+// ConvertMarkdown converts the amounts of all ingredient lines in the
+// Markdown source into the given unit system. Lines with units that cannot
+// be converted stay as they are.
+func ConvertMarkdown(markdown string, system UnitSystem) string {
+	return rewriteLines(markdown, func(l *line) {
+		if !l.ingredient.HasQuantity() || len(l.ingredient.Unit) == 0 {
+			return
+		}
+		quantity, unit, ok := ConvertQuantity(l.ingredient.Quantity, l.ingredient.Unit, system)
+		if !ok {
+			return
+		}
+		quantityMax := 0.0
+		if l.ingredient.IsRange() {
+			quantityMax, _, _ = convertQuantityTo(l.ingredient.QuantityMax, l.ingredient.Unit, unit)
+		}
+		l.quantity = FormatQuantityRange(quantity, quantityMax)
+		l.unit = " " + formatUnit(unit, math.Max(quantity, quantityMax))
+		if !strings.HasPrefix(l.rest, " ") {
+			l.rest = " " + l.rest
+		}
+	})
+}
+
+// Convert returns a copy of ingredients with all convertible amounts
+// converted into the given unit system.
+func Convert(ingredients []types.Ingredient, system UnitSystem) []types.Ingredient {
+	if ingredients == nil {
+		return nil
+	}
+	converted := make([]types.Ingredient, len(ingredients))
+	for i, ingredient := range ingredients {
+		if ingredient.HasQuantity() && len(ingredient.Unit) > 0 {
+			quantity, unit, ok := ConvertQuantity(ingredient.Quantity, ingredient.Unit, system)
+			if ok {
+				if ingredient.IsRange() {
+					ingredient.QuantityMax, _, _ = convertQuantityTo(ingredient.QuantityMax, ingredient.Unit, unit)
+				}
+				ingredient.Quantity = quantity
+				ingredient.Unit = unit
+			}
+		}
+		converted[i] = ingredient
+	}
+	return converted
+}
+
+// convertQuantityTo converts a quantity between two units of the same
+// dimension, e.g. the upper end of a range into the unit picked for the
+// lower end.
+func convertQuantityTo(quantity float64, from, to string) (float64, string, bool) {
+	if from == to || spoonUnits[UnitSystemMetric][from] == to || spoonUnits[UnitSystemImperial][from] == to {
+		return quantity, to, true
+	}
+	source, ok := conversions[from]
+	if !ok {
+		return quantity, from, false
+	}
+	target, ok := conversions[to]
+	if !ok || source.dimension != target.dimension {
+		return quantity, from, false
+	}
+	return roundConverted(quantity*source.base/target.base, to), to, true
+}
+
+func formatUnit(unit string, quantity float64) string {
+	if unit == "cup" && quantity > 1 {
+		return "cups"
+	}
+	return unit
+}
+
+var temperaturePattern = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*(?:°\s*([CF])\b|Grad\b(?:\s+(Celsius|Fahrenheit)\b)?)`)
+
+// ConvertTemperatures converts oven temperatures like "180 °C", "180°C",
+// "180 Grad" or "350 °F" in free text into the given unit system. Values are
+// rounded to the nearest 5 degrees, the way ovens are usually set.
+func ConvertTemperatures(text string, system UnitSystem) string {
+	return temperaturePattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := temperaturePattern.FindStringSubmatch(match)
+		value, err := strconv.ParseFloat(strings.Replace(groups[1], ",", ".", 1), 64)
+		if err != nil {
+			return match
+		}
+
+		isFahrenheit := groups[2] == "F" || groups[3] == "Fahrenheit"
+
+		switch {
+		case system == UnitSystemImperial && !isFahrenheit:
+			return strconv.Itoa(roundToFive(value*9/5+32)) + " °F"
+		case system == UnitSystemMetric && isFahrenheit:
+			return strconv.Itoa(roundToFive((value-32)*5/9)) + " °C"
+		}
+		return match
+	})
+}
+
+func roundToFive(value float64) int {
+	return int(math.Round(value/5) * 5)
+}
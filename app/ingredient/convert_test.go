@@ -0,0 +1,116 @@
+package ingredient
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertQuantity(t *testing.T) {
+	testCases := []struct {
+		quantity     float64
+		unit         string
+		system       UnitSystem
+		wantQuantity float64
+		wantUnit     string
+		wantOk       bool
+	}{
+		{2, "lb", UnitSystemMetric, 910, "g", true},
+		{3, "lb", UnitSystemMetric, 1.35, "kg", true},
+		{1, "oz", UnitSystemMetric, 30, "g", true},
+		{1, "cup", UnitSystemMetric, 240, "ml", true},
+		{0.25, "cup", UnitSystemMetric, 60, "ml", true},
+		{2, "tbsp", UnitSystemMetric, 2, "EL", true},
+		{1, "kg", UnitSystemImperial, 2.25, "lb", true},
+		{100, "g", UnitSystemImperial, 3.5, "oz", true},
+		{500, "ml", UnitSystemImperial, 2, "cup", true},
+		{10, "ml", UnitSystemImperial, 2, "tsp", true},
+		{1, "EL", UnitSystemImperial, 1, "tbsp", true},
+		{200, "g", UnitSystemMetric, 200, "g", false},
+		{1, "Bund", UnitSystemImperial, 1, "Bund", false},
+	}
+
+	for _, test := range testCases {
+		quantity, unit, ok := ConvertQuantity(test.quantity, test.unit, test.system)
+		assert.InDelta(t, test.wantQuantity, quantity, 0.0001, "%v %s", test.quantity, test.unit)
+		assert.Equal(t, test.wantUnit, unit)
+		assert.Equal(t, test.wantOk, ok)
+	}
+}
+
+func TestConvertMarkdown(t *testing.T) {
+	testCases := []struct {
+		markdown  string
+		system    UnitSystem
+		converted string
+	}{
+		{
+			markdown:  "- 2 cups flour\n- 1 tbsp sugar\n- 1 lb butter, soft\n- 2 eggs",
+			system:    UnitSystemMetric,
+			converted: "- 470 ml flour\n- 1 EL sugar\n- 450 g butter, soft\n- 2 eggs",
+		},
+		{
+			markdown:  "- 200g Mehl\n- 1-2 l Milch\n- 1 Bund Petersilie",
+			system:    UnitSystemImperial,
+			converted: "- 7 oz Mehl\n- 4 1/4-8 1/2 cups Milch\n- 1 Bund Petersilie",
+		},
+		{
+			markdown:  "- 200 g Mehl",
+			system:    UnitSystemMetric,
+			converted: "- 200 g Mehl",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.converted, ConvertMarkdown(test.markdown, test.system))
+	}
+}
+
+func TestConvert(t *testing.T) {
+	// Given
+	ingredients := []types.Ingredient{
+		{Quantity: 8, Unit: "oz", Name: "cream cheese"},
+		{Quantity: 2, Name: "eggs"},
+	}
+
+	// When
+	converted := Convert(ingredients, UnitSystemMetric)
+
+	// Then
+	assert.Equal(t, []types.Ingredient{
+		{Quantity: 230, Unit: "g", Name: "cream cheese"},
+		{Quantity: 2, Name: "eggs"},
+	}, converted)
+	assert.Equal(t, "oz", ingredients[0].Unit)
+}
+
+func TestConvertTemperatures(t *testing.T) {
+	testCases := []struct {
+		text      string
+		system    UnitSystem
+		converted string
+	}{
+		{"Bei 180 °C backen.", UnitSystemImperial, "Bei 355 °F backen."},
+		{"Auf 200°C vorheizen", UnitSystemImperial, "Auf 390 °F vorheizen"},
+		{"Bei 180 Grad backen", UnitSystemImperial, "Bei 355 °F backen"},
+		{"Bei 180 Grad Celsius backen", UnitSystemImperial, "Bei 355 °F backen"},
+		{"5 Gradienten und 2 Gradeinteilungen", UnitSystemImperial, "5 Gradienten und 2 Gradeinteilungen"},
+		{"Bake at 350 °F", UnitSystemMetric, "Bake at 175 °C"},
+		{"Bake at 350 degrees, 350°F", UnitSystemMetric, "Bake at 350 degrees, 175 °C"},
+		{"Bei 180 °C backen.", UnitSystemMetric, "Bei 180 °C backen."},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.converted, ConvertTemperatures(test.text, test.system))
+	}
+}
+
+func TestParseUnitSystem(t *testing.T) {
+	system, ok := ParseUnitSystem("imperial")
+	assert.True(t, ok)
+	assert.Equal(t, UnitSystemImperial, system)
+
+	_, ok = ParseUnitSystem("kelvin")
+	assert.False(t, ok)
+}
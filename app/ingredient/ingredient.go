@@ -0,0 +1,156 @@
+package ingredient
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+var (
+	listItemPattern     = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)(.*)$`)
+	headingPattern      = regexp.MustCompile(`^\s*#{1,6}\s+(.+?)\s*#*\s*$`)
+	boldHeadingPattern  = regexp.MustCompile(`^\s*(?:\*\*|__)(.+?)(?:\*\*|__)\s*:?\s*$`)
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	parenthesesPattern  = regexp.MustCompile(`\s*\(([^)]*)\)`)
+)
+
+// line is a single ingredient line of the Markdown source split into the
+// list marker, the quantity, the unit and the remaining text, so that the
+// amount can be rewritten without touching the rest of the line.
+type line struct {
+	marker     string
+	quantity   string
+	unit       string
+	rest       string
+	ingredient types.Ingredient
+}
+
+func (l *line) String() string {
+	return l.marker + l.quantity + l.unit + l.rest
+}
+
+// Parse extracts the structured ingredient list from the Markdown ingredients
+// of a recipe. List items like "- 200 g Mehl" become ingredients, headings
+// like "## Für den Teig", "**Für den Teig**" or "Für den Teig:" set the group
+// of the ingredients below them and everything else is ignored. Markdown
+// without any ingredients yields an empty list, it is stored as [] so that
+// the recipe isn't parsed again on the next start.
+func Parse(markdown string) []types.Ingredient {
+	ingredients := []types.Ingredient{}
+	group := ""
+
+	for _, text := range strings.Split(markdown, "\n") {
+		if heading, ok := parseGroupHeading(text); ok {
+			group = heading
+			continue
+		}
+		if l, ok := parseLine(text); ok {
+			l.ingredient.Group = group
+			ingredients = append(ingredients, l.ingredient)
+		}
+	}
+
+	return ingredients
+}
+
+func parseGroupHeading(text string) (string, bool) {
+	if match := headingPattern.FindStringSubmatch(text); match != nil {
+		return strings.TrimSuffix(match[1], ":"), true
+	}
+	if match := boldHeadingPattern.FindStringSubmatch(text); match != nil {
+		return strings.TrimSuffix(strings.TrimSpace(match[1]), ":"), true
+	}
+	trimmed := strings.TrimSpace(text)
+	if strings.HasSuffix(trimmed, ":") && !listItemPattern.MatchString(text) {
+		return strings.TrimSpace(strings.TrimSuffix(trimmed, ":")), true
+	}
+	return "", false
+}
+
+func parseLine(text string) (line, bool) {
+	match := listItemPattern.FindStringSubmatch(text)
+	if match == nil || len(strings.TrimSpace(match[2])) == 0 {
+		return line{}, false
+	}
+
+	l := line{marker: match[1]}
+	content := match[2]
+
+	quantity, quantityMax, quantityEnd := parseQuantity(content)
+	unit, unitEnd := "", 0
+	if quantityEnd > 0 {
+		unit, unitEnd = parseUnit(content[quantityEnd:])
+	}
+
+	l.quantity = content[:quantityEnd]
+	l.unit = content[quantityEnd : quantityEnd+unitEnd]
+	l.rest = content[quantityEnd+unitEnd:]
+
+	name, note := splitNameAndNote(l.rest)
+	if len(name) == 0 {
+		return line{}, false
+	}
+
+	l.ingredient = types.Ingredient{
+		Quantity:    quantity,
+		QuantityMax: quantityMax,
+		Unit:        unit,
+		Name:        name,
+		Note:        note,
+	}
+
+	return l, true
+}
+
+// rewriteLines applies rewrite to every ingredient line of the Markdown
+// source and leaves all other lines untouched.
+func rewriteLines(markdown string, rewrite func(l *line)) string {
+	lines := strings.Split(markdown, "\n")
+	for i, text := range lines {
+		if l, ok := parseLine(text); ok {
+			rewrite(&l)
+			lines[i] = l.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func splitNameAndNote(text string) (string, string) {
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = strings.NewReplacer("**", "", "__", "").Replace(text)
+
+	notes := []string{}
+	for _, match := range parenthesesPattern.FindAllStringSubmatch(text, -1) {
+		if note := strings.TrimSpace(match[1]); len(note) > 0 {
+			notes = append(notes, note)
+		}
+	}
+	text = parenthesesPattern.ReplaceAllString(text, "")
+
+	name, note, found := strings.Cut(text, ",")
+	if found {
+		if note := strings.TrimSpace(note); len(note) > 0 {
+			notes = append(notes, note)
+		}
+	}
+
+	return strings.TrimSpace(name), strings.Join(notes, ", ")
+}
+
+// Format returns a single line description of an ingredient like
+// "1 1/2 EL Zucker" or "Salz".
+func Format(i types.Ingredient) string {
+	parts := []string{}
+	if i.HasQuantity() {
+		parts = append(parts, FormatQuantityRange(i.Quantity, i.QuantityMax))
+		if len(i.Unit) > 0 {
+			parts = append(parts, formatUnit(i.Unit, upperEnd(i)))
+		}
+	}
+	parts = append(parts, i.Name)
+	if len(i.Note) > 0 {
+		parts = append(parts, "("+i.Note+")")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,96 @@
+package ingredient
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		markdown string
+		parsed   []types.Ingredient
+	}{
+		{
+			markdown: "",
+			parsed:   []types.Ingredient{},
+		},
+		{
+			markdown: "Nach Belieben",
+			parsed:   []types.Ingredient{},
+		},
+		{
+			markdown: "- 200 g Mehl",
+			parsed:   []types.Ingredient{{Quantity: 200, Unit: "g", Name: "Mehl"}},
+		},
+		{
+			markdown: "* 200g Mehl\n+ 2 Eier",
+			parsed: []types.Ingredient{
+				{Quantity: 200, Unit: "g", Name: "Mehl"},
+				{Quantity: 2, Name: "Eier"},
+			},
+		},
+		{
+			markdown: "- 1/2 TL Salz\n- 1 1/2 Esslöffel Zucker\n- ½ Zitrone\n- 0,5 l Milch",
+			parsed: []types.Ingredient{
+				{Quantity: 0.5, Unit: "TL", Name: "Salz"},
+				{Quantity: 1.5, Unit: "EL", Name: "Zucker"},
+				{Quantity: 0.5, Name: "Zitrone"},
+				{Quantity: 0.5, Unit: "l", Name: "Milch"},
+			},
+		},
+		{
+			markdown: "- 2-3 Zehen Knoblauch, fein gehackt\n- 100 g Butter (weich)",
+			parsed: []types.Ingredient{
+				{Quantity: 2, QuantityMax: 3, Unit: "Zehe", Name: "Knoblauch", Note: "fein gehackt"},
+				{Quantity: 100, Unit: "g", Name: "Butter", Note: "weich"},
+			},
+		},
+		{
+			markdown: "- Salz und Pfeffer\n- 1 Gurke\n- [Naan](https://lethimcook.de/recipe/1)",
+			parsed: []types.Ingredient{
+				{Name: "Salz und Pfeffer"},
+				{Quantity: 1, Name: "Gurke"},
+				{Name: "Naan"},
+			},
+		},
+		{
+			markdown: "## Für den Teig\n- 250 g Mehl\n\n**Für die Füllung:**\n- 1 Dose (400 g) Tomaten\n\nZum Servieren:\n- 1 Bund Petersilie",
+			parsed: []types.Ingredient{
+				{Quantity: 250, Unit: "g", Name: "Mehl", Group: "Für den Teig"},
+				{Quantity: 1, Unit: "Dose", Name: "Tomaten", Note: "400 g", Group: "Für die Füllung"},
+				{Quantity: 1, Unit: "Bund", Name: "Petersilie", Group: "Zum Servieren"},
+			},
+		},
+		{
+			markdown: "Ein Absatz ohne Liste\n- \n- 4 oz cream cheese",
+			parsed: []types.Ingredient{
+				{Quantity: 4, Unit: "oz", Name: "cream cheese"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.parsed, Parse(test.markdown))
+	}
+}
+
+func TestParseLine(t *testing.T) {
+	// When
+	l, ok := parseLine("  - 1 1/2 TL Salz, grob")
+
+	// Then
+	assert.True(t, ok)
+	assert.Equal(t, "  - ", l.marker)
+	assert.Equal(t, "1 1/2", l.quantity)
+	assert.Equal(t, " TL", l.unit)
+	assert.Equal(t, " Salz, grob", l.rest)
+	assert.Equal(t, "  - 1 1/2 TL Salz, grob", l.String())
+
+	// When
+	_, ok = parseLine("Kein Listenpunkt")
+
+	// Then
+	assert.False(t, ok)
+}
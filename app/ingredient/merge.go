@@ -0,0 +1,115 @@
+package ingredient
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+// Merge combines ingredients with the same name into a single entry and sums
+// their amounts. Amounts in different units of the same dimension, like
+// "500 g" and "1 kg", are added up in the unit system of the first entry,
+// amounts in units that cannot be converted into each other stay separate
+// entries. Notes and groups are dropped and the result is sorted by name.
+func Merge(ingredients []types.Ingredient) []types.Ingredient {
+	var merged []types.Ingredient
+
+	for _, next := range ingredients {
+		next.Note = ""
+		next.Group = ""
+
+		found := false
+		for i := range merged {
+			if mergeInto(&merged[i], next) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, next)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return strings.ToLower(merged[i].Name) < strings.ToLower(merged[j].Name)
+	})
+
+	return merged
+}
+
+func mergeInto(target *types.Ingredient, next types.Ingredient) bool {
+	if NormalizeName(target.Name) != NormalizeName(next.Name) {
+		return false
+	}
+
+	if !next.HasQuantity() {
+		return !target.HasQuantity()
+	}
+	if !target.HasQuantity() {
+		return false
+	}
+
+	if target.Unit == next.Unit {
+		target.QuantityMax = sumRangeEnds(target, next, 1)
+		target.Quantity += next.Quantity
+		return true
+	}
+
+	source, ok := conversions[next.Unit]
+	if !ok {
+		return false
+	}
+	destination, ok := conversions[target.Unit]
+	if !ok || source.dimension != destination.dimension {
+		return false
+	}
+
+	factor := source.base / destination.base
+	target.QuantityMax = sumRangeEnds(target, next, factor)
+	target.Quantity += next.Quantity * factor
+
+	// Switch to a larger unit once the sum gets big, e.g. 1500 g become 1,5 kg
+	base := target.Quantity * destination.base
+	unit := pickTargetUnit(base, destination.dimension, unitSystemOf(target.Unit))
+	if conversions[unit].base > destination.base {
+		scale := destination.base / conversions[unit].base
+		target.Quantity *= scale
+		target.QuantityMax *= scale
+		target.Unit = unit
+	}
+
+	return true
+}
+
+// sumRangeEnds returns the upper end of the range when next is added to
+// target, or 0 if neither of them is a range.
+func sumRangeEnds(target *types.Ingredient, next types.Ingredient, factor float64) float64 {
+	if !target.IsRange() && !next.IsRange() {
+		return 0
+	}
+	return upperEnd(*target) + upperEnd(next)*factor
+}
+
+func upperEnd(i types.Ingredient) float64 {
+	if i.IsRange() {
+		return i.QuantityMax
+	}
+	return i.Quantity
+}
+
+var pluralSuffixes = []string{"en", "er", "n", "e", "s"}
+
+// NormalizeName reduces an ingredient name to a key that is the same for
+// the singular and plural form, e.g. "zwiebel" for "Zwiebel" and "Zwiebeln"
+// or "ei" for "Ei" and "Eier".
+func NormalizeName(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	for _, suffix := range pluralSuffixes {
+		stem, found := strings.CutSuffix(name, suffix)
+		if found && len([]rune(stem)) >= 2 {
+			return stem
+		}
+	}
+	return name
+}
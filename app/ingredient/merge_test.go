@@ -0,0 +1,121 @@
+package ingredient
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	testCases := []struct {
+		ingredients []types.Ingredient
+		merged      []types.Ingredient
+	}{
+		{
+			ingredients: nil,
+			merged:      nil,
+		},
+		{
+			ingredients: []types.Ingredient{
+				{Quantity: 200, Unit: "g", Name: "Mehl", Group: "Teig"},
+				{Quantity: 2, Name: "Eier"},
+				{Quantity: 300, Unit: "g", Name: "mehl", Note: "gesiebt"},
+				{Quantity: 1, Name: "Ei"},
+			},
+			merged: []types.Ingredient{
+				{Quantity: 3, Name: "Eier"},
+				{Quantity: 500, Unit: "g", Name: "Mehl"},
+			},
+		},
+		{
+			ingredients: []types.Ingredient{
+				{Quantity: 800, Unit: "g", Name: "Kartoffeln"},
+				{Quantity: 0.5, Unit: "kg", Name: "Kartoffel"},
+				{Quantity: 500, Unit: "ml", Name: "Milch"},
+				{Quantity: 0.75, Unit: "l", Name: "Milch"},
+			},
+			merged: []types.Ingredient{
+				{Quantity: 1.3, Unit: "kg", Name: "Kartoffeln"},
+				{Quantity: 1.25, Unit: "l", Name: "Milch"},
+			},
+		},
+		{
+			ingredients: []types.Ingredient{
+				{Quantity: 2, QuantityMax: 3, Unit: "Zehe", Name: "Knoblauch"},
+				{Quantity: 1, Unit: "Zehe", Name: "Knoblauch"},
+				{Quantity: 1, Name: "Knoblauch"},
+				{Name: "Salz"},
+				{Name: "Salz"},
+				{Quantity: 1, Unit: "Prise", Name: "Salz"},
+			},
+			merged: []types.Ingredient{
+				{Quantity: 3, QuantityMax: 4, Unit: "Zehe", Name: "Knoblauch"},
+				{Quantity: 1, Name: "Knoblauch"},
+				{Name: "Salz"},
+				{Quantity: 1, Unit: "Prise", Name: "Salz"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		merged := Merge(test.ingredients)
+		assert.Equal(t, len(test.merged), len(merged))
+		for i := range test.merged {
+			assert.Equal(t, test.merged[i].Name, merged[i].Name)
+			assert.Equal(t, test.merged[i].Unit, merged[i].Unit)
+			assert.InDelta(t, test.merged[i].Quantity, merged[i].Quantity, 0.0001)
+			assert.InDelta(t, test.merged[i].QuantityMax, merged[i].QuantityMax, 0.0001)
+			assert.Empty(t, merged[i].Note)
+			assert.Empty(t, merged[i].Group)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	testCases := []struct {
+		names      []string
+		normalized string
+	}{
+		{[]string{"Zwiebel", "Zwiebeln", " zwiebeln "}, "zwiebel"},
+		{[]string{"Ei", "Eier"}, "ei"},
+		{[]string{"Tomate", "Tomaten"}, "tomat"},
+		{[]string{"Mehl"}, "mehl"},
+	}
+
+	for _, test := range testCases {
+		for _, name := range test.names {
+			assert.Equal(t, test.normalized, NormalizeName(name), name)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	testCases := []struct {
+		name     string
+		category string
+	}{
+		{"Weizenmehl", "Backen"},
+		{"Eier", "Milchprodukte & Eier"},
+		{"Ei", "Milchprodukte & Eier"},
+		{"Reis", "Nudeln, Reis & Brot"},
+		{"Paprikapulver", "Gewürze & Öle"},
+		{"rote Paprika", "Obst & Gemüse"},
+		{"Tomatenmark", "Konserven & Vorrat"},
+		{"Tomaten", "Obst & Gemüse"},
+		{"Olivenöl", "Gewürze & Öle"},
+		{"Rinderhackfleisch", "Fleisch & Fisch"},
+		{"Backpapier", CategoryOther},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.category, Category(test.name), test.name)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "1 1/2 EL Zucker", Format(types.Ingredient{Quantity: 1.5, Unit: "EL", Name: "Zucker"}))
+	assert.Equal(t, "2-3 Eier", Format(types.Ingredient{Quantity: 2, QuantityMax: 3, Name: "Eier"}))
+	assert.Equal(t, "2 cups Milch", Format(types.Ingredient{Quantity: 2, Unit: "cup", Name: "Milch"}))
+	assert.Equal(t, "Salz (grob)", Format(types.Ingredient{Name: "Salz", Note: "grob"}))
+}
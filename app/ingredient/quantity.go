@@ -0,0 +1,173 @@
+package ingredient
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var vulgarFractions = map[rune]float64{
+	'½': 1.0 / 2,
+	'⅓': 1.0 / 3,
+	'⅔': 2.0 / 3,
+	'¼': 1.0 / 4,
+	'¾': 3.0 / 4,
+	'⅕': 1.0 / 5,
+	'⅖': 2.0 / 5,
+	'⅗': 3.0 / 5,
+	'⅘': 4.0 / 5,
+	'⅙': 1.0 / 6,
+	'⅚': 5.0 / 6,
+	'⅛': 1.0 / 8,
+	'⅜': 3.0 / 8,
+	'⅝': 5.0 / 8,
+	'⅞': 7.0 / 8,
+}
+
+const vulgarFractionChars = "½⅓⅔¼¾⅕⅖⅗⅘⅙⅚⅛⅜⅝⅞"
+
+var (
+	numberPattern   = `(?:\d+\s+\d+/\d+|\d+\s*[` + vulgarFractionChars + `]|\d+/\d+|[` + vulgarFractionChars + `]|\d+(?:[.,]\d+)?)`
+	quantityPattern = regexp.MustCompile(`^(` + numberPattern + `)(?:\s*(?:-|–|bis)\s*(` + numberPattern + `))?`)
+)
+
+// parseQuantity reads a quantity or quantity range such as "200", "0,5",
+// "1/2", "1 1/2", "1½" or "2-3" from the start of text. It returns the
+// quantity, the upper end of a range (0 if there is none) and the number of
+// bytes consumed.
+func parseQuantity(text string) (float64, float64, int) {
+	match := quantityPattern.FindStringSubmatchIndex(text)
+	if match == nil {
+		return 0, 0, 0
+	}
+
+	quantity, ok := parseNumber(text[match[2]:match[3]])
+	if !ok {
+		return 0, 0, 0
+	}
+
+	if match[4] < 0 {
+		return quantity, 0, match[1]
+	}
+
+	quantityMax, ok := parseNumber(text[match[4]:match[5]])
+	if !ok || quantityMax <= quantity {
+		return quantity, 0, match[3]
+	}
+
+	return quantity, quantityMax, match[1]
+}
+
+func parseNumber(text string) (float64, bool) {
+	text = strings.TrimSpace(text)
+
+	for char, value := range vulgarFractions {
+		if whole, found := strings.CutSuffix(text, string(char)); found {
+			whole = strings.TrimSpace(whole)
+			if len(whole) == 0 {
+				return value, true
+			}
+			wholeValue, err := strconv.Atoi(whole)
+			if err != nil {
+				return 0, false
+			}
+			return float64(wholeValue) + value, true
+		}
+	}
+
+	if whole, fraction, found := strings.Cut(text, " "); found {
+		wholeValue, err := strconv.Atoi(whole)
+		if err != nil {
+			return 0, false
+		}
+		fractionValue, ok := parseFraction(strings.TrimSpace(fraction))
+		if !ok {
+			return 0, false
+		}
+		return float64(wholeValue) + fractionValue, true
+	}
+
+	if strings.Contains(text, "/") {
+		return parseFraction(text)
+	}
+
+	value, err := strconv.ParseFloat(strings.Replace(text, ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+func parseFraction(text string) (float64, bool) {
+	numerator, denominator, found := strings.Cut(text, "/")
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.Atoi(numerator)
+	if err != nil {
+		return 0, false
+	}
+	d, err := strconv.Atoi(denominator)
+	if err != nil || d == 0 {
+		return 0, false
+	}
+	return float64(n) / float64(d), true
+}
+
+var formattedFractions = []struct {
+	value float64
+	text  string
+}{
+	{1.0 / 8, "1/8"},
+	{1.0 / 4, "1/4"},
+	{1.0 / 3, "1/3"},
+	{3.0 / 8, "3/8"},
+	{1.0 / 2, "1/2"},
+	{5.0 / 8, "5/8"},
+	{2.0 / 3, "2/3"},
+	{3.0 / 4, "3/4"},
+	{7.0 / 8, "7/8"},
+}
+
+// FormatQuantity formats a quantity the way it is usually written in German
+// recipes: whole numbers stay whole, common fractions are written as "1/2" or
+// "1 1/2" and everything else uses a decimal comma with at most two decimals.
+func FormatQuantity(quantity float64) string {
+	if quantity <= 0 {
+		return ""
+	}
+
+	whole := math.Floor(quantity)
+	remainder := quantity - whole
+
+	if remainder < 0.01 {
+		return strconv.Itoa(int(whole))
+	}
+	if remainder > 0.99 {
+		return strconv.Itoa(int(whole) + 1)
+	}
+
+	if quantity < 10 {
+		for _, fraction := range formattedFractions {
+			if math.Abs(remainder-fraction.value) < 0.01 {
+				if whole == 0 {
+					return fraction.text
+				}
+				return strconv.Itoa(int(whole)) + " " + fraction.text
+			}
+		}
+	}
+
+	formatted := strconv.FormatFloat(math.Round(quantity*100)/100, 'f', -1, 64)
+	return strings.Replace(formatted, ".", ",", 1)
+}
+
+// FormatQuantityRange formats a quantity and an optional upper bound as
+// "2-3". An upper bound of 0 means the quantity is not a range.
+func FormatQuantityRange(quantity, quantityMax float64) string {
+	if quantityMax > quantity {
+		return FormatQuantity(quantity) + "-" + FormatQuantity(quantityMax)
+	}
+	return FormatQuantity(quantity)
+}
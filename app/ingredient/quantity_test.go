@@ -0,0 +1,65 @@
+package ingredient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuantity(t *testing.T) {
+	testCases := []struct {
+		text        string
+		quantity    float64
+		quantityMax float64
+		consumed    int
+	}{
+		{"Salz", 0, 0, 0},
+		{"200 g Mehl", 200, 0, 3},
+		{"200g Mehl", 200, 0, 3},
+		{"0,5 l Milch", 0.5, 0, 3},
+		{"1.25 kg", 1.25, 0, 4},
+		{"1/2 TL", 0.5, 0, 3},
+		{"1 1/2 EL", 1.5, 0, 5},
+		{"½ Zitrone", 0.5, 0, len("½")},
+		{"1½ Tassen", 1.5, 0, len("1½")},
+		{"2-3 Eier", 2, 3, 3},
+		{"2 – 3 Eier", 2, 3, len("2 – 3")},
+		{"2 bis 3 Eier", 2, 3, 7},
+		{"3-2 Eier", 3, 0, 1},
+	}
+
+	for _, test := range testCases {
+		quantity, quantityMax, consumed := parseQuantity(test.text)
+		assert.InDelta(t, test.quantity, quantity, 0.0001, test.text)
+		assert.InDelta(t, test.quantityMax, quantityMax, 0.0001, test.text)
+		assert.Equal(t, test.consumed, consumed, test.text)
+	}
+}
+
+func TestFormatQuantity(t *testing.T) {
+	testCases := []struct {
+		quantity  float64
+		formatted string
+	}{
+		{0, ""},
+		{200, "200"},
+		{0.5, "1/2"},
+		{1.5, "1 1/2"},
+		{1.0 / 3, "1/3"},
+		{2.0 / 3 * 2, "1 1/3"},
+		{0.75, "3/4"},
+		{0.2, "0,2"},
+		{1.999, "2"},
+		{12.5, "12,5"},
+		{133.333, "133,33"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.formatted, FormatQuantity(test.quantity))
+	}
+}
+
+func TestFormatQuantityRange(t *testing.T) {
+	assert.Equal(t, "2-3", FormatQuantityRange(2, 3))
+	assert.Equal(t, "2", FormatQuantityRange(2, 0))
+}
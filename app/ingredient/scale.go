@@ -0,0 +1,33 @@
+package ingredient
+
+import "github.com/kilianmandscharo/lethimcook/types"
+
+// ScaleMarkdown multiplies the quantity of every ingredient line in the
+// Markdown source by factor. Only the quantity is replaced, so units, names
+// and formatting are kept as written.
+func ScaleMarkdown(markdown string, factor float64) string {
+	return rewriteLines(markdown, func(l *line) {
+		if !l.ingredient.HasQuantity() {
+			return
+		}
+		l.quantity = FormatQuantityRange(
+			l.ingredient.Quantity*factor,
+			l.ingredient.QuantityMax*factor,
+		)
+	})
+}
+
+// Scale returns a copy of ingredients with every quantity multiplied by
+// factor.
+func Scale(ingredients []types.Ingredient, factor float64) []types.Ingredient {
+	if ingredients == nil {
+		return nil
+	}
+	scaled := make([]types.Ingredient, len(ingredients))
+	for i, ingredient := range ingredients {
+		ingredient.Quantity *= factor
+		ingredient.QuantityMax *= factor
+		scaled[i] = ingredient
+	}
+	return scaled
+}
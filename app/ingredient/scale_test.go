@@ -0,0 +1,65 @@
+package ingredient
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleMarkdown(t *testing.T) {
+	testCases := []struct {
+		markdown string
+		factor   float64
+		scaled   string
+	}{
+		{
+			markdown: "- 200 g Mehl\n- 2 Eier",
+			factor:   2,
+			scaled:   "- 400 g Mehl\n- 4 Eier",
+		},
+		{
+			markdown: "- 1/2 TL Salz\n- 1 1/2 Esslöffel Zucker\n- ½ Zitrone",
+			factor:   3,
+			scaled:   "- 1 1/2 TL Salz\n- 4 1/2 Esslöffel Zucker\n- 1 1/2 Zitrone",
+		},
+		{
+			markdown: "- 3 Eier\n- 200g Mehl",
+			factor:   0.5,
+			scaled:   "- 1 1/2 Eier\n- 100g Mehl",
+		},
+		{
+			markdown: "## Teig\n\n* 2-3 Zehen Knoblauch, gehackt\n- Salz\n\nDazu passt Reis.",
+			factor:   2,
+			scaled:   "## Teig\n\n* 4-6 Zehen Knoblauch, gehackt\n- Salz\n\nDazu passt Reis.",
+		},
+		{
+			markdown: "- 100 g Butter\r\n- 1 Prise Salz\r\n",
+			factor:   1.0 / 3,
+			scaled:   "- 33,33 g Butter\r\n- 1/3 Prise Salz\r\n",
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.scaled, ScaleMarkdown(test.markdown, test.factor))
+	}
+}
+
+func TestScale(t *testing.T) {
+	// Given
+	ingredients := []types.Ingredient{
+		{Quantity: 2, QuantityMax: 3, Unit: "Zehe", Name: "Knoblauch"},
+		{Name: "Salz"},
+	}
+
+	// When
+	scaled := Scale(ingredients, 2)
+
+	// Then
+	assert.Equal(t, []types.Ingredient{
+		{Quantity: 4, QuantityMax: 6, Unit: "Zehe", Name: "Knoblauch"},
+		{Name: "Salz"},
+	}, scaled)
+	assert.Equal(t, float64(2), ingredients[0].Quantity)
+	assert.Nil(t, Scale(nil, 2))
+}
@@ -0,0 +1,97 @@
+package ingredient
+
+import "strings"
+
+var unitAliases = map[string][]string{
+	"mg":       {"mg", "milligramm"},
+	"g":        {"g", "gr", "gr.", "gramm"},
+	"kg":       {"kg", "kilo", "kilogramm"},
+	"ml":       {"ml", "milliliter"},
+	"cl":       {"cl", "zentiliter"},
+	"dl":       {"dl", "deziliter"},
+	"l":        {"l", "liter"},
+	"TL":       {"tl", "tl.", "teelöffel"},
+	"EL":       {"el", "el.", "esslöffel"},
+	"Msp.":     {"msp", "msp.", "messerspitze", "messerspitzen"},
+	"Prise":    {"prise", "prisen"},
+	"Tasse":    {"tasse", "tassen"},
+	"Becher":   {"becher"},
+	"Dose":     {"dose", "dosen"},
+	"Glas":     {"glas", "gläser"},
+	"Pck.":     {"pck", "pck.", "pkg", "pkg.", "packung", "packungen", "päckchen", "pack"},
+	"Bund":     {"bund", "bd.", "bünde"},
+	"Stück":    {"stück", "stk", "stk.", "st."},
+	"Zehe":     {"zehe", "zehen"},
+	"Scheibe":  {"scheibe", "scheiben"},
+	"Handvoll": {"handvoll"},
+	"Zweig":    {"zweig", "zweige"},
+	"Blatt":    {"blatt", "blätter"},
+	"Spritzer": {"spritzer"},
+	"Schuss":   {"schuss"},
+	"Würfel":   {"würfel"},
+	"cup":      {"cup", "cups"},
+	"tbsp":     {"tbsp", "tbsp.", "tablespoon", "tablespoons"},
+	"tsp":      {"tsp", "tsp.", "teaspoon", "teaspoons"},
+	"oz":       {"oz", "oz.", "ounce", "ounces"},
+	"lb":       {"lb", "lb.", "lbs", "lbs.", "pound", "pounds"},
+	"fl oz":    {"fl oz", "fl. oz", "fl. oz."},
+	"pint":     {"pint", "pints", "pt"},
+	"quart":    {"quart", "quarts", "qt"},
+	"pinch":    {"pinch", "pinches"},
+}
+
+var unitLookup = buildUnitLookup()
+
+func buildUnitLookup() map[string]string {
+	lookup := make(map[string]string)
+	for unit, aliases := range unitAliases {
+		for _, alias := range aliases {
+			lookup[alias] = unit
+		}
+	}
+	return lookup
+}
+
+// CanonicalUnit returns the canonical spelling of a unit, e.g. "TL" for
+// "Teelöffel", and false if the unit is unknown.
+func CanonicalUnit(unit string) (string, bool) {
+	canonical, ok := unitLookup[strings.ToLower(strings.TrimSpace(unit))]
+	return canonical, ok
+}
+
+// parseUnit reads a known unit from the start of text and returns its
+// canonical spelling and the number of bytes consumed. Only whole words are
+// considered so that "Gurke" is not read as "g" followed by "urke".
+func parseUnit(text string) (string, int) {
+	first, firstEnd := nextWord(text, 0)
+	if len(first) == 0 {
+		return "", 0
+	}
+
+	if second, secondEnd := nextWord(text, firstEnd); len(second) > 0 {
+		if unit, ok := CanonicalUnit(first + " " + trimUnitPunctuation(second)); ok {
+			return unit, secondEnd - (len(second) - len(trimUnitPunctuation(second)))
+		}
+	}
+
+	if unit, ok := CanonicalUnit(trimUnitPunctuation(first)); ok {
+		return unit, firstEnd - (len(first) - len(trimUnitPunctuation(first)))
+	}
+
+	return "", 0
+}
+
+func nextWord(text string, start int) (string, int) {
+	for start < len(text) && (text[start] == ' ' || text[start] == '\t') {
+		start++
+	}
+	end := start
+	for end < len(text) && text[end] != ' ' && text[end] != '\t' {
+		end++
+	}
+	return text[start:end], end
+}
+
+func trimUnitPunctuation(word string) string {
+	return strings.TrimRight(word, ",:;")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/kilianmandscharo/lethimcook/auth"
 	"github.com/kilianmandscharo/lethimcook/env"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/mealplan"
 	"github.com/kilianmandscharo/lethimcook/recipe"
 	"github.com/kilianmandscharo/lethimcook/render"
 	"github.com/kilianmandscharo/lethimcook/server"
@@ -39,7 +40,14 @@ func main() {
 	recipeService := recipe.NewRecipeService(recipeDatabase, logger)
 	recipeController := recipe.NewRecipeController(recipeService, logger, renderer)
 
+	mealPlanDatabase := mealplan.NewMealPlanDatabase(logger)
+	mealPlanService := mealplan.NewMealPlanService(mealPlanDatabase, recipeService, logger)
+	mealPlanController := mealplan.NewMealPlanController(mealPlanService, logger, renderer)
+
+	authService.OnDeleteUser(recipeService.DetachUser)
+	recipeService.SetUserReader(authService.ReadUser)
 	authService.CreateAdminIfDoesNotExist(*password)
-	server := server.New(authController, recipeController, logger, renderer, *isProd)
+	recipeService.StartTrashPurge()
+	server := server.New(authController, recipeController, mealPlanController, logger, renderer, *isProd)
 	server.Start()
 }
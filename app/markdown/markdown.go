@@ -0,0 +1,39 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var converter = goldmark.New(
+	goldmark.WithParserOptions(
+		parser.WithASTTransformers(util.Prioritized(imageTransformer{}, 100)),
+	),
+)
+
+// Convert renders the Markdown of a recipe as HTML.
+func Convert(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := converter.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// imageTransformer lets the browser load the images of the instructions only
+// once they are scrolled into view.
+type imageTransformer struct{}
+
+func (imageTransformer) Transform(node *ast.Document, reader text.Reader, pc parser.Context) {
+	ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if image, ok := n.(*ast.Image); ok && entering {
+			image.SetAttributeString("loading", []byte("lazy"))
+		}
+		return ast.WalkContinue, nil
+	})
+}
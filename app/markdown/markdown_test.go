@@ -0,0 +1,35 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		source string
+		html   string
+	}{
+		{source: "", html: ""},
+		{source: "1. Teig **kneten**", html: "<ol>\n<li>Teig <strong>kneten</strong></li>\n</ol>\n"},
+		{
+			source: "![](/images/0123456789abcdef0123456789abcdef/large.jpg)",
+			html:   "<p><img src=\"/images/0123456789abcdef0123456789abcdef/large.jpg\" alt=\"\" loading=\"lazy\"></p>\n",
+		},
+		{
+			source: "1. Falten\n\n   ![Gefaltet](/images/0123456789abcdef0123456789abcdef/large.jpg)",
+			html:   "<ol>\n<li>\n<p>Falten</p>\n<p><img src=\"/images/0123456789abcdef0123456789abcdef/large.jpg\" alt=\"Gefaltet\" loading=\"lazy\"></p>\n</li>\n</ol>\n",
+		},
+		{source: "![](javascript:alert(1))", html: "<p><img src=\"\" alt=\"\" loading=\"lazy\"></p>\n"},
+	}
+
+	for _, test := range testCases {
+		// When
+		html, err := Convert(test.source)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, test.html, html, test.source)
+	}
+}
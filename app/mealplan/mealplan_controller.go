@@ -0,0 +1,159 @@
+package mealplan
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+type MealPlanController struct {
+	mealPlanService *mealPlanService
+	logger          *logging.Logger
+	renderer        *render.Renderer
+}
+
+func NewMealPlanController(mealPlanService *mealPlanService, logger *logging.Logger, renderer *render.Renderer) *MealPlanController {
+	return &MealPlanController{
+		mealPlanService: mealPlanService,
+		logger:          logger,
+		renderer:        renderer,
+	}
+}
+
+func (mc *MealPlanController) AttachHandlerFunctions(e *echo.Echo) {
+	// Pages
+	e.GET("/meal-plan", mc.RenderMealPlanPage)
+
+	// Actions
+	e.GET("/meal-plan/ics", mc.HandleDownloadMealPlanAsICalendar)
+	e.POST("/meal-plan", mc.HandleCreateEntry)
+	e.DELETE("/meal-plan/:id", mc.HandleDeleteEntry)
+}
+
+func (mc *MealPlanController) RenderMealPlanPage(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return mc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderMealPlanPage()"),
+		)
+	}
+	week, err := mc.mealPlanService.getQueryWeek(c)
+	if err != nil {
+		return mc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at RenderMealPlanPage()"),
+		)
+	}
+	return mc.renderMealPlanPageHelper(c, week, "")
+}
+
+func (mc *MealPlanController) renderMealPlanPageHelper(c echo.Context, start time.Time, message string) error {
+	week, err := mc.mealPlanService.readWeek(start)
+	if err != nil {
+		return mc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderMealPlanPageHelper()"),
+		)
+	}
+	return mc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.MealPlanPage(servutil.IsAuthorized(c), week),
+		Message:   message,
+	})
+}
+
+func (mc *MealPlanController) HandleCreateEntry(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return mc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleCreateEntry()"),
+		)
+	}
+
+	createError := func(err error) error {
+		return mc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleCreateEntry()"),
+		)
+	}
+
+	entry, err := mc.mealPlanService.getEntryFromFormData(c)
+	if err != nil {
+		return createError(err)
+	}
+	if err := mc.mealPlanService.createEntry(&entry); err != nil {
+		return createError(err)
+	}
+
+	date, err := time.ParseInLocation(dateLayout, entry.Date, time.Local)
+	if err != nil {
+		return createError(err)
+	}
+
+	mc.logger.Infof("planned recipe %d on %s for %s", entry.RecipeID, entry.Date, entry.Slot)
+	return mc.renderMealPlanPageHelper(c, startOfWeek(date), "Rezept eingeplant")
+}
+
+func (mc *MealPlanController) HandleDeleteEntry(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return mc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleDeleteEntry()"),
+		)
+	}
+
+	createError := func(err error) error {
+		return mc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleDeleteEntry()"),
+		)
+	}
+
+	id, err := mc.mealPlanService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+	week, err := mc.mealPlanService.getQueryWeek(c)
+	if err != nil {
+		return createError(err)
+	}
+	if err := mc.mealPlanService.deleteEntry(id); err != nil {
+		return createError(err)
+	}
+
+	mc.logger.Info("deleted meal plan entry", id)
+	return mc.renderMealPlanPageHelper(c, week, "Aus dem Essensplan entfernt")
+}
+
+func (mc *MealPlanController) HandleDownloadMealPlanAsICalendar(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return mc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleDownloadMealPlanAsICalendar()"),
+		)
+	}
+	calendar, err := mc.mealPlanService.getPlanAsICalendar(
+		c.Scheme()+"://"+c.Request().Host,
+		time.Now(),
+	)
+	if err != nil {
+		return mc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleDownloadMealPlanAsICalendar()"),
+		)
+	}
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		"attachment; filename=essensplan.ics",
+	)
+	c.Response().Header().Set(echo.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentLength, strconv.Itoa(len(calendar)))
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", calendar)
+}
@@ -0,0 +1,269 @@
+package mealplan
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMealPlanController() *MealPlanController {
+	logger := logging.New(logging.Debug, false)
+	renderer := render.New(logger)
+	mealPlanService, _ := newTestMealPlanService()
+	return NewMealPlanController(mealPlanService, logger, renderer)
+}
+
+func TestRenderMealPlanPage(t *testing.T) {
+	mealPlanController := newTestMealPlanController()
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: mealPlanController.RenderMealPlanPage,
+				Method:      http.MethodGet,
+				Route:       "/meal-plan",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: mealPlanController.RenderMealPlanPage,
+				Method:      http.MethodGet,
+				Route:       "/meal-plan",
+				StatusWant:  http.StatusForbidden,
+				Role:        types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("invalid week", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    mealPlanController.RenderMealPlanPage,
+				Method:         http.MethodGet,
+				Route:          "/meal-plan",
+				WithQueryParam: true,
+				QueryParam:     "?week=xx",
+				StatusWant:     http.StatusBadRequest,
+				Authorized:     true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    mealPlanController.RenderMealPlanPage,
+				Method:         http.MethodGet,
+				Route:          "/meal-plan",
+				WithQueryParam: true,
+				QueryParam:     "?week=2024-01-10",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+				AssertMessage:  true,
+				MessageWant:    "Montag, 08.01.",
+			},
+		)
+	})
+}
+
+func TestHandleCreateEntry(t *testing.T) {
+	mealPlanController := newTestMealPlanController()
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  mealPlanController.HandleCreateEntry,
+				Method:       http.MethodPost,
+				Route:        "/meal-plan",
+				WithFormData: true,
+				FormData:     "recipeId=1&date=2024-01-08&slot=dinner",
+				StatusWant:   http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  mealPlanController.HandleCreateEntry,
+				Method:       http.MethodPost,
+				Route:        "/meal-plan",
+				WithFormData: true,
+				FormData:     "recipeId=1&date=2024-01-08&slot=dinner",
+				StatusWant:   http.StatusForbidden,
+				Role:         types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("pending recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   mealPlanController.HandleCreateEntry,
+				Method:        http.MethodPost,
+				Route:         "/meal-plan",
+				WithFormData:  true,
+				FormData:      "recipeId=3&date=2024-01-08&slot=dinner",
+				StatusWant:    http.StatusBadRequest,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "Ausstehende Rezepte können nicht eingeplant werden",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   mealPlanController.HandleCreateEntry,
+				Method:        http.MethodPost,
+				Route:         "/meal-plan",
+				WithFormData:  true,
+				FormData:      "recipeId=1&date=2024-01-10&slot=dinner",
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "Rezept eingeplant",
+			},
+		)
+	})
+}
+
+func TestHandleDeleteEntry(t *testing.T) {
+	mealPlanController := newTestMealPlanController()
+	err := mealPlanController.mealPlanService.createEntry(&types.MealPlanEntry{
+		Date:     "2024-01-08",
+		Slot:     "dinner",
+		RecipeID: 1,
+	})
+	assert.NoError(t, err)
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    mealPlanController.HandleDeleteEntry,
+				Method:         http.MethodDelete,
+				Route:          "/meal-plan",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    mealPlanController.HandleDeleteEntry,
+				Method:         http.MethodDelete,
+				Route:          "/meal-plan",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusForbidden,
+				Role:           types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    mealPlanController.HandleDeleteEntry,
+				Method:         http.MethodDelete,
+				Route:          "/meal-plan",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithQueryParam: true,
+				QueryParam:     "?week=2024-01-08",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+				AssertMessage:  true,
+				MessageWant:    "Aus dem Essensplan entfernt",
+			},
+		)
+	})
+
+	t.Run("entry not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    mealPlanController.HandleDeleteEntry,
+				Method:         http.MethodDelete,
+				Route:          "/meal-plan",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusNotFound,
+				Authorized:     true,
+			},
+		)
+	})
+}
+
+func TestHandleDownloadMealPlanAsICalendar(t *testing.T) {
+	mealPlanController := newTestMealPlanController()
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: mealPlanController.HandleDownloadMealPlanAsICalendar,
+				Method:      http.MethodGet,
+				Route:       "/meal-plan/ics",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: mealPlanController.HandleDownloadMealPlanAsICalendar,
+				Method:      http.MethodGet,
+				Route:       "/meal-plan/ics",
+				StatusWant:  http.StatusForbidden,
+				Role:        types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: mealPlanController.HandleDownloadMealPlanAsICalendar,
+				Method:      http.MethodGet,
+				Route:       "/meal-plan/ics",
+				StatusWant:  http.StatusOK,
+				Authorized:  true,
+			},
+		)
+		assert.True(t, strings.HasPrefix(rr.Body.String(), "BEGIN:VCALENDAR"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "essensplan.ics")
+	})
+}
@@ -0,0 +1,119 @@
+package mealplan
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type mealPlanDatabase struct {
+	handler *gorm.DB
+	logger  *logging.Logger
+}
+
+func NewMealPlanDatabase(logger *logging.Logger) *mealPlanDatabase {
+	db, err := gorm.Open(sqlite.Open("./mealplan.db"), &gorm.Config{})
+	if err != nil {
+		logger.Fatal("failed to connect meal plan database: ", err)
+	}
+	db.AutoMigrate(&types.MealPlanEntry{})
+	return &mealPlanDatabase{handler: db, logger: logger}
+}
+
+func (db *mealPlanDatabase) createEntry(entry *types.MealPlanEntry) error {
+	if err := db.handler.Create(entry).Error; err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at createEntry() with entry %v, database failure: %w",
+				*entry,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return nil
+}
+
+// readEntriesBetween returns all entries with from <= date < to, dates are
+// compared as "2006-01-02" strings.
+func (db *mealPlanDatabase) readEntriesBetween(from, to string) ([]types.MealPlanEntry, error) {
+	var entries []types.MealPlanEntry
+	if err := db.handler.
+		Where("date >= ? AND date < ?", from, to).
+		Order("date, id").
+		Find(&entries).Error; err != nil {
+		return entries, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readEntriesBetween() from %s to %s, database failure: %w",
+				from,
+				to,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return entries, nil
+}
+
+func (db *mealPlanDatabase) readAllEntries() ([]types.MealPlanEntry, error) {
+	var entries []types.MealPlanEntry
+	if err := db.handler.Order("date, id").Find(&entries).Error; err != nil {
+		return entries, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readAllEntries(), database failure: %w",
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return entries, nil
+}
+
+func (db *mealPlanDatabase) deleteEntry(id uint) error {
+	result := db.handler.Delete(&types.MealPlanEntry{}, id)
+	if err := result.Error; err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at deleteEntry() with id %d, database failure: %w",
+				id,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	if result.RowsAffected == 0 {
+		return &errutil.AppError{
+			UserMessage: "Eintrag nicht gefunden",
+			Err: fmt.Errorf(
+				"failed at deleteEntry(), entry with id %d not found",
+				id,
+			),
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return nil
+}
+
+func (db *mealPlanDatabase) deleteEntriesByRecipe(recipeId uint) error {
+	if err := db.handler.Where("recipe_id = ?", recipeId).Delete(&types.MealPlanEntry{}).Error; err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at deleteEntriesByRecipe() with recipe id %d, database failure: %w",
+				recipeId,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return nil
+}
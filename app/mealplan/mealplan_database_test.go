@@ -0,0 +1,83 @@
+package mealplan
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestMealPlanDatabase() *mealPlanDatabase {
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		fmt.Println("failed to connect test database: ", err)
+		os.Exit(1)
+	}
+	db.Migrator().DropTable(&types.MealPlanEntry{})
+	db.AutoMigrate(&types.MealPlanEntry{})
+	return &mealPlanDatabase{handler: db, logger: logging.New(logging.Debug, false)}
+}
+
+func TestReadEntriesBetween(t *testing.T) {
+	// Given
+	db := newTestMealPlanDatabase()
+	for _, date := range []string{"2024-01-07", "2024-01-08", "2024-01-14", "2024-01-15"} {
+		assert.NoError(t, db.createEntry(&types.MealPlanEntry{Date: date, Slot: "lunch", RecipeID: 1}))
+	}
+
+	// When
+	entries, err := db.readEntriesBetween("2024-01-08", "2024-01-15")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "2024-01-08", entries[0].Date)
+	assert.Equal(t, "2024-01-14", entries[1].Date)
+}
+
+func TestDeleteEntry(t *testing.T) {
+	// Given
+	db := newTestMealPlanDatabase()
+	entry := types.MealPlanEntry{Date: "2024-01-08", Slot: "lunch", RecipeID: 1}
+	assert.NoError(t, db.createEntry(&entry))
+
+	// When
+	err := db.deleteEntry(entry.ID)
+
+	// Then
+	assert.NoError(t, err)
+
+	// When
+	err = db.deleteEntry(entry.ID)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestDeleteEntriesByRecipe(t *testing.T) {
+	// Given
+	db := newTestMealPlanDatabase()
+	assert.NoError(t, db.createEntry(&types.MealPlanEntry{Date: "2024-01-08", Slot: "lunch", RecipeID: 1}))
+	assert.NoError(t, db.createEntry(&types.MealPlanEntry{Date: "2024-01-09", Slot: "dinner", RecipeID: 1}))
+	assert.NoError(t, db.createEntry(&types.MealPlanEntry{Date: "2024-01-09", Slot: "lunch", RecipeID: 2}))
+
+	// When
+	err := db.deleteEntriesByRecipe(1)
+
+	// Then
+	assert.NoError(t, err)
+	entries, err := db.readAllEntries()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, uint(2), entries[0].RecipeID)
+}
@@ -0,0 +1,309 @@
+package mealplan
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+const dateLayout = "2006-01-02"
+
+type slot struct {
+	name  string
+	label string
+	hour  int
+}
+
+var slots = []slot{
+	{name: "breakfast", label: "Frühstück", hour: 8},
+	{name: "lunch", label: "Mittagessen", hour: 12},
+	{name: "dinner", label: "Abendessen", hour: 18},
+}
+
+var weekdays = map[time.Weekday]string{
+	time.Monday:    "Montag",
+	time.Tuesday:   "Dienstag",
+	time.Wednesday: "Mittwoch",
+	time.Thursday:  "Donnerstag",
+	time.Friday:    "Freitag",
+	time.Saturday:  "Samstag",
+	time.Sunday:    "Sonntag",
+}
+
+func findSlot(name string) (slot, bool) {
+	for _, s := range slots {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return slot{}, false
+}
+
+// recipeReader is the part of the recipe service the meal plan depends on.
+type recipeReader interface {
+	ReadRecipe(id uint) (types.Recipe, error)
+	ReadAllRecipes(isAdmin bool) ([]types.Recipe, error)
+	OnDeleteRecipe(hook func(id uint) error)
+}
+
+type mealPlanService struct {
+	db      *mealPlanDatabase
+	recipes recipeReader
+	logger  *logging.Logger
+}
+
+func NewMealPlanService(db *mealPlanDatabase, recipes recipeReader, logger *logging.Logger) *mealPlanService {
+	ms := &mealPlanService{
+		db:      db,
+		recipes: recipes,
+		logger:  logger,
+	}
+	recipes.OnDeleteRecipe(ms.db.deleteEntriesByRecipe)
+	return ms
+}
+
+// startOfWeek returns the Monday of the week of t at midnight.
+func startOfWeek(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+func (ms *mealPlanService) getQueryWeek(c echo.Context) (time.Time, error) {
+	week := c.QueryParam("week")
+	if len(week) == 0 {
+		return startOfWeek(time.Now()), nil
+	}
+	date, err := time.ParseInLocation(dateLayout, week, time.Local)
+	if err != nil {
+		return time.Time{}, &errutil.AppError{
+			UserMessage: "Ungültiges Datum",
+			Err:         fmt.Errorf("failed at getQueryWeek() with param %s: %w", week, err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return startOfWeek(date), nil
+}
+
+func (ms *mealPlanService) getPathId(c echo.Context) (uint, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, &errutil.AppError{
+			UserMessage: "Ungültiges Pfadparameter",
+			Err: fmt.Errorf(
+				"failed at getPathId() with parameter %s: %w",
+				c.Param("id"),
+				err,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return uint(id), nil
+}
+
+// getEntryFromFormData reads and validates a new entry from the form. Only
+// existing recipes that are not pending can be planned.
+func (ms *mealPlanService) getEntryFromFormData(c echo.Context) (types.MealPlanEntry, error) {
+	var entry types.MealPlanEntry
+
+	createError := func(message string, err error) error {
+		return &errutil.AppError{
+			UserMessage: message,
+			Err:         fmt.Errorf("failed at getEntryFromFormData(): %w", err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	date, err := time.ParseInLocation(dateLayout, strings.TrimSpace(c.FormValue("date")), time.Local)
+	if err != nil {
+		return entry, createError("Ungültiges Datum", err)
+	}
+	entry.Date = date.Format(dateLayout)
+
+	entry.Slot = c.FormValue("slot")
+	if _, ok := findSlot(entry.Slot); !ok {
+		return entry, createError("Ungültige Mahlzeit", fmt.Errorf("unknown slot %s", entry.Slot))
+	}
+
+	recipeId, err := strconv.Atoi(c.FormValue("recipeId"))
+	if err != nil || recipeId <= 0 {
+		return entry, createError("Ungültiges Rezept", fmt.Errorf("invalid recipe id %s", c.FormValue("recipeId")))
+	}
+	recipe, err := ms.recipes.ReadRecipe(uint(recipeId))
+	if err != nil {
+		return entry, errutil.AddMessageToAppError(err, "failed at getEntryFromFormData()")
+	}
+	if recipe.Pending {
+		return entry, createError(
+			"Ausstehende Rezepte können nicht eingeplant werden",
+			fmt.Errorf("recipe %d is pending", recipe.ID),
+		)
+	}
+	entry.RecipeID = recipe.ID
+
+	return entry, nil
+}
+
+func (ms *mealPlanService) createEntry(entry *types.MealPlanEntry) error {
+	return ms.db.createEntry(entry)
+}
+
+func (ms *mealPlanService) deleteEntry(id uint) error {
+	return ms.db.deleteEntry(id)
+}
+
+// readRecipesById maps the ids of all recipes to the recipes. Entries
+// pointing to recipes missing from the map are shown as deleted.
+func (ms *mealPlanService) readRecipesById() (map[uint]types.Recipe, error) {
+	recipes, err := ms.recipes.ReadAllRecipes(true)
+	if err != nil {
+		return nil, errutil.AddMessageToAppError(err, "failed at readRecipesById()")
+	}
+	byId := make(map[uint]types.Recipe, len(recipes))
+	for _, recipe := range recipes {
+		byId[recipe.ID] = recipe
+	}
+	return byId, nil
+}
+
+func (ms *mealPlanService) readWeek(start time.Time) (types.MealPlanWeek, error) {
+	week := types.MealPlanWeek{Start: start}
+
+	end := start.AddDate(0, 0, 7)
+	entries, err := ms.db.readEntriesBetween(start.Format(dateLayout), end.Format(dateLayout))
+	if err != nil {
+		return week, errutil.AddMessageToAppError(err, "failed at readWeek()")
+	}
+
+	recipes, err := ms.readRecipesById()
+	if err != nil {
+		return week, errutil.AddMessageToAppError(err, "failed at readWeek()")
+	}
+
+	for day := 0; day < 7; day++ {
+		date := start.AddDate(0, 0, day)
+		mealPlanDay := types.MealPlanDay{
+			Date:  date,
+			Label: fmt.Sprintf("%s, %s", weekdays[date.Weekday()], date.Format("02.01.")),
+		}
+		for _, s := range slots {
+			mealPlanSlot := types.MealPlanSlot{Name: s.name, Label: s.label}
+			for _, entry := range entries {
+				if entry.Date != date.Format(dateLayout) || entry.Slot != s.name {
+					continue
+				}
+				recipe, ok := recipes[entry.RecipeID]
+				mealPlanSlot.Items = append(mealPlanSlot.Items, types.MealPlanItem{
+					EntryID:  entry.ID,
+					RecipeID: entry.RecipeID,
+					Title:    recipe.Title,
+					Missing:  !ok,
+				})
+			}
+			mealPlanDay.Slots = append(mealPlanDay.Slots, mealPlanSlot)
+		}
+		week.Days = append(week.Days, mealPlanDay)
+	}
+
+	for _, recipe := range recipes {
+		if !recipe.Pending {
+			week.Recipes = append(week.Recipes, types.RecipeLinkData{ID: recipe.ID, Title: recipe.Title})
+		}
+	}
+	sort.Slice(week.Recipes, func(i, j int) bool {
+		return strings.ToLower(week.Recipes[i].Title) < strings.ToLower(week.Recipes[j].Title)
+	})
+
+	return week, nil
+}
+
+// getPlanAsICalendar exports all entries of the plan as events of an
+// iCalendar file. baseUrl is used to link each event to its recipe.
+func (ms *mealPlanService) getPlanAsICalendar(baseUrl string, now time.Time) ([]byte, error) {
+	entries, err := ms.db.readAllEntries()
+	if err != nil {
+		return nil, errutil.AddMessageToAppError(err, "failed at getPlanAsICalendar()")
+	}
+	recipes, err := ms.readRecipesById()
+	if err != nil {
+		return nil, errutil.AddMessageToAppError(err, "failed at getPlanAsICalendar()")
+	}
+
+	var builder strings.Builder
+	writeLine := func(line string) {
+		builder.WriteString(foldICalendarLine(line))
+		builder.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//lethimcook//Essensplan//DE")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("X-WR-CALNAME:Essensplan")
+
+	for _, entry := range entries {
+		recipe, ok := recipes[entry.RecipeID]
+		if !ok {
+			continue
+		}
+		s, ok := findSlot(entry.Slot)
+		if !ok {
+			continue
+		}
+		date, err := time.ParseInLocation(dateLayout, entry.Date, time.Local)
+		if err != nil {
+			ms.logger.Error(fmt.Errorf("failed at getPlanAsICalendar() with entry %d: %w", entry.ID, err))
+			continue
+		}
+		start := date.Add(time.Duration(s.hour) * time.Hour)
+
+		writeLine("BEGIN:VEVENT")
+		writeLine(fmt.Sprintf("UID:mealplan-%d@lethimcook", entry.ID))
+		writeLine("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+		writeLine("DTSTART:" + start.Format("20060102T150405"))
+		writeLine("DTEND:" + start.Add(time.Hour).Format("20060102T150405"))
+		writeLine("SUMMARY:" + escapeICalendarText(s.label+": "+recipe.Title))
+		writeLine(fmt.Sprintf("URL:%s/recipe/%d", baseUrl, recipe.ID))
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+
+	return []byte(builder.String()), nil
+}
+
+func escapeICalendarText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// foldICalendarLine splits lines longer than 75 bytes as required by
+// RFC 5545, without cutting multi-byte characters in half.
+func foldICalendarLine(line string) string {
+	const limit = 75
+	var builder strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > limit {
+			builder.WriteString("\r\n ")
+			length = 1
+		}
+		builder.WriteRune(r)
+		length += size
+	}
+	return builder.String()
+}
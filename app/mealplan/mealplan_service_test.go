@@ -0,0 +1,232 @@
+package mealplan
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRecipeReader struct {
+	recipes map[uint]types.Recipe
+	hooks   []func(id uint) error
+}
+
+func (f *fakeRecipeReader) ReadRecipe(id uint) (types.Recipe, error) {
+	recipe, ok := f.recipes[id]
+	if !ok {
+		return recipe, &errutil.AppError{
+			UserMessage: "Rezept nicht gefunden",
+			Err:         fmt.Errorf("recipe with id %d not found", id),
+			StatusCode:  http.StatusNotFound,
+		}
+	}
+	return recipe, nil
+}
+
+func (f *fakeRecipeReader) ReadAllRecipes(isAdmin bool) ([]types.Recipe, error) {
+	recipes := []types.Recipe{}
+	for _, recipe := range f.recipes {
+		if isAdmin || !recipe.Pending {
+			recipes = append(recipes, recipe)
+		}
+	}
+	return recipes, nil
+}
+
+func (f *fakeRecipeReader) OnDeleteRecipe(hook func(id uint) error) {
+	f.hooks = append(f.hooks, hook)
+}
+
+func (f *fakeRecipeReader) deleteRecipe(id uint) {
+	delete(f.recipes, id)
+	for _, hook := range f.hooks {
+		hook(id)
+	}
+}
+
+func newTestRecipeReader() *fakeRecipeReader {
+	return &fakeRecipeReader{
+		recipes: map[uint]types.Recipe{
+			1: {ID: 1, Title: "Pfannkuchen"},
+			2: {ID: 2, Title: "Brot"},
+			3: {ID: 3, Title: "Suppe", Pending: true},
+		},
+	}
+}
+
+func newTestMealPlanService() (*mealPlanService, *fakeRecipeReader) {
+	recipes := newTestRecipeReader()
+	return NewMealPlanService(newTestMealPlanDatabase(), recipes, logging.New(logging.Debug, false)), recipes
+}
+
+func newTestContext(t *testing.T, formData string, query string) echo.Context {
+	req, err := http.NewRequest(http.MethodPost, "/meal-plan"+query, bytes.NewBufferString(formData))
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func date(value string) time.Time {
+	parsed, _ := time.ParseInLocation(dateLayout, value, time.Local)
+	return parsed
+}
+
+func TestStartOfWeek(t *testing.T) {
+	testCases := []struct {
+		date  string
+		start string
+	}{
+		{"2024-01-08", "2024-01-08"},
+		{"2024-01-10", "2024-01-08"},
+		{"2024-01-14", "2024-01-08"},
+		{"2024-01-01", "2024-01-01"},
+		{"2024-03-03", "2024-02-26"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.start, startOfWeek(date(test.date)).Format(dateLayout), test.date)
+	}
+}
+
+func TestGetQueryWeek(t *testing.T) {
+	mealPlanService, _ := newTestMealPlanService()
+
+	// When
+	week, err := mealPlanService.getQueryWeek(newTestContext(t, "", "?week=2024-01-10"))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-01-08", week.Format(dateLayout))
+
+	// When
+	_, err = mealPlanService.getQueryWeek(newTestContext(t, "", "?week=xx"))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestGetEntryFromFormData(t *testing.T) {
+	mealPlanService, _ := newTestMealPlanService()
+
+	testCases := []struct {
+		formData   string
+		entry      types.MealPlanEntry
+		statusCode int
+	}{
+		{
+			formData: "recipeId=1&date=2024-01-08&slot=dinner",
+			entry:    types.MealPlanEntry{Date: "2024-01-08", Slot: "dinner", RecipeID: 1},
+		},
+		{formData: "recipeId=1&date=08.01.2024&slot=dinner", statusCode: http.StatusBadRequest},
+		{formData: "recipeId=1&date=2024-01-08&slot=snack", statusCode: http.StatusBadRequest},
+		{formData: "recipeId=x&date=2024-01-08&slot=dinner", statusCode: http.StatusBadRequest},
+		{formData: "recipeId=9&date=2024-01-08&slot=dinner", statusCode: http.StatusNotFound},
+		{formData: "recipeId=3&date=2024-01-08&slot=dinner", statusCode: http.StatusBadRequest},
+	}
+
+	for _, test := range testCases {
+		// When
+		entry, err := mealPlanService.getEntryFromFormData(newTestContext(t, test.formData, ""))
+
+		// Then
+		if test.statusCode == 0 {
+			assert.NoError(t, err, test.formData)
+			assert.Equal(t, test.entry, entry)
+		} else {
+			assert.Equal(t, test.statusCode, errutil.GetAppErrorStatusCode(err), test.formData)
+		}
+	}
+}
+
+func TestReadWeek(t *testing.T) {
+	// Given
+	mealPlanService, recipes := newTestMealPlanService()
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-08", Slot: "dinner", RecipeID: 1}))
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-14", Slot: "breakfast", RecipeID: 2}))
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-15", Slot: "lunch", RecipeID: 2}))
+	delete(recipes.recipes, 2)
+
+	// When
+	week, err := mealPlanService.readWeek(date("2024-01-08"))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 7, len(week.Days))
+	assert.Equal(t, "Montag, 08.01.", week.Days[0].Label)
+	assert.Equal(t, "Sonntag, 14.01.", week.Days[6].Label)
+	assert.Equal(t, []types.MealPlanItem{{EntryID: 1, RecipeID: 1, Title: "Pfannkuchen"}}, week.Days[0].Slots[2].Items)
+	assert.Equal(t, []types.MealPlanItem{{EntryID: 2, RecipeID: 2, Missing: true}}, week.Days[6].Slots[0].Items)
+	assert.Equal(t, []types.RecipeLinkData{{ID: 1, Title: "Pfannkuchen"}}, week.Recipes)
+}
+
+func TestDeletingRecipeRemovesEntries(t *testing.T) {
+	// Given
+	mealPlanService, recipes := newTestMealPlanService()
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-08", Slot: "dinner", RecipeID: 1}))
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-09", Slot: "dinner", RecipeID: 2}))
+
+	// When
+	recipes.deleteRecipe(1)
+
+	// Then
+	entries, err := mealPlanService.db.readAllEntries()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, uint(2), entries[0].RecipeID)
+}
+
+func TestGetPlanAsICalendar(t *testing.T) {
+	// Given
+	mealPlanService, recipes := newTestMealPlanService()
+	recipes.recipes[2] = types.Recipe{ID: 2, Title: "Brot, Butter; und Salz"}
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-08", Slot: "dinner", RecipeID: 2}))
+	assert.NoError(t, mealPlanService.createEntry(&types.MealPlanEntry{Date: "2024-01-09", Slot: "dinner", RecipeID: 9}))
+
+	// When
+	calendar, err := mealPlanService.getPlanAsICalendar(
+		"https://lethimcook.de",
+		time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"BEGIN:VCALENDAR\r\n"+
+			"VERSION:2.0\r\n"+
+			"PRODID:-//lethimcook//Essensplan//DE\r\n"+
+			"CALSCALE:GREGORIAN\r\n"+
+			"X-WR-CALNAME:Essensplan\r\n"+
+			"BEGIN:VEVENT\r\n"+
+			"UID:mealplan-1@lethimcook\r\n"+
+			"DTSTAMP:20240101T120000Z\r\n"+
+			"DTSTART:20240108T180000\r\n"+
+			"DTEND:20240108T190000\r\n"+
+			"SUMMARY:Abendessen: Brot\\, Butter\\; und Salz\r\n"+
+			"URL:https://lethimcook.de/recipe/2\r\n"+
+			"END:VEVENT\r\n"+
+			"END:VCALENDAR\r\n",
+		string(calendar),
+	)
+}
+
+func TestFoldICalendarLine(t *testing.T) {
+	// When
+	folded := foldICalendarLine("SUMMARY:" + strings.Repeat("ä", 40))
+
+	// Then
+	lines := strings.Split(folded, "\r\n ")
+	assert.Equal(t, 2, len(lines))
+	assert.LessOrEqual(t, len(lines[0]), 75)
+	assert.Equal(t, "SUMMARY:"+strings.Repeat("ä", 40), strings.Join(lines, ""))
+}
@@ -0,0 +1,34 @@
+package recipe
+
+import (
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderAuthorPage(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at RenderAuthorPage()"),
+		)
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	isAdmin := servutil.IsAuthorized(c)
+	name, recipes, err := rc.recipeService.readAuthorRecipes(id, isAdmin)
+	if err != nil {
+		return createError(err)
+	}
+
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.AuthorPage(isAdmin, name, recipes),
+	})
+}
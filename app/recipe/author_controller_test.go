@@ -0,0 +1,87 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderAuthorPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipeController.recipeService.SetUserReader(newTestUserReader(
+		types.User{ID: 2, Name: "Oma", Role: types.RoleEditor},
+		types.User{ID: 4, Name: "Opa", Role: types.RoleContributor},
+	))
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe", CreatedByID: 2, CreatedByName: "Oma"}))
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Brot"}))
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Kuchen", CreatedByID: 2, CreatedByName: "Oma", Pending: true}))
+
+	t.Run("invalid path id", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderAuthorPage,
+				Method:         http.MethodGet,
+				Route:          "/author",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "xx",
+				StatusWant:     http.StatusBadRequest,
+			},
+		)
+	})
+
+	t.Run("author not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderAuthorPage,
+				Method:         http.MethodGet,
+				Route:          "/author",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "3",
+				StatusWant:     http.StatusNotFound,
+				AssertMessage:  true,
+				MessageWant:    "Autor nicht gefunden",
+			},
+		)
+	})
+
+	t.Run("author without recipes", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderAuthorPage,
+				Method:         http.MethodGet,
+				Route:          "/author",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "4",
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "0 Rezepte",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderAuthorPage,
+				Method:         http.MethodGet,
+				Route:          "/author",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "Oma",
+			},
+		)
+	})
+}
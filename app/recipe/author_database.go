@@ -0,0 +1,33 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"gorm.io/gorm"
+)
+
+// detachUser sets the creator and last editor ids referring to the user to
+// zero, including the ones of recipes in the trash.
+func (db *recipeDatabase) detachUser(userId uint) error {
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&types.Recipe{}).Where("created_by_id = ?", userId).Update("created_by_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&types.Recipe{}).Where("last_edited_by_id = ?", userId).Update("last_edited_by_id", 0).Error
+	})
+	if err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at detachUser() with id %d, database failure: %w",
+				userId,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return nil
+}
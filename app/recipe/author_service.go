@@ -0,0 +1,67 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+// checkCanModify returns an error unless the logged in user may change the
+// recipe, editors may only change the recipes they created.
+func (rs *recipeService) checkCanModify(c echo.Context, recipeId uint) error {
+	recipe, err := rs.readRecipe(recipeId)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at checkCanModify()")
+	}
+	if !servutil.CanModify(c, recipe) {
+		return servutil.NewAppErrorNotAllowed(c, "checkCanModify()")
+	}
+	return nil
+}
+
+// DetachUser removes the links of the recipes to the deleted user, so that a
+// later user with the same id doesn't own them. The names stay on the
+// recipes.
+func (rs *recipeService) DetachUser(userId uint) error {
+	rs.recipeCache.Invalidate()
+	if err := rs.db.detachUser(userId); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at DetachUser()")
+	}
+	return nil
+}
+
+// readAuthorRecipes returns the name of the user and the recipes they
+// created, newest first. Only users without an account aren't found, the
+// list is empty for users who haven't created a recipe the visitor may see.
+func (rs *recipeService) readAuthorRecipes(userId uint, isAdmin bool) (string, []types.Recipe, error) {
+	authorRecipes := []types.Recipe{}
+
+	user, err := rs.readUser(userId)
+	if err != nil {
+		if errutil.GetAppErrorStatusCode(err) == http.StatusNotFound {
+			return "", authorRecipes, &errutil.AppError{
+				UserMessage: "Autor nicht gefunden",
+				Err:         fmt.Errorf("failed at readAuthorRecipes(): %w", err),
+				StatusCode:  http.StatusNotFound,
+			}
+		}
+		return "", authorRecipes, errutil.AddMessageToAppError(err, "failed at readAuthorRecipes()")
+	}
+
+	recipes, err := rs.readAllRecipes(isAdmin)
+	if err != nil {
+		return "", authorRecipes, errutil.AddMessageToAppError(err, "failed at readAuthorRecipes()")
+	}
+
+	for _, recipe := range recipes {
+		if recipe.CreatedByID == user.ID {
+			authorRecipes = append(authorRecipes, recipe)
+		}
+	}
+
+	return user.Name, authorRecipes, nil
+}
@@ -0,0 +1,74 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadAuthorRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipeService.SetUserReader(newTestUserReader(
+		types.User{ID: 2, Name: "Oma Anna", Role: types.RoleEditor},
+		types.User{ID: 3, Name: "Opa", Role: types.RoleEditor},
+	))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe", CreatedByID: 2, CreatedByName: "Oma"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Brot"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Kuchen", CreatedByID: 2, CreatedByName: "Oma", Pending: true}))
+
+	// When
+	name, recipes, err := recipeService.readAuthorRecipes(2, false)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Oma Anna", name)
+	assert.Equal(t, 1, len(recipes))
+	assert.Equal(t, "Linsensuppe", recipes[0].Title)
+
+	// When
+	_, recipes, err = recipeService.readAuthorRecipes(2, true)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(recipes))
+	assert.Equal(t, "Kuchen", recipes[0].Title)
+
+	// When
+	name, recipes, err = recipeService.readAuthorRecipes(3, true)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Opa", name)
+	assert.Empty(t, recipes)
+
+	// When
+	_, _, err = recipeService.readAuthorRecipes(4, true)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestDetachUser(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.Recipe{Title: "Linsensuppe", CreatedByID: 2, CreatedByName: "Oma"}
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	assert.NoError(t, recipeService.updateRecipe(&recipe, types.User{ID: 2, Name: "Oma", Role: types.RoleEditor}))
+	assert.NoError(t, recipeService.trashRecipe(recipe.ID))
+
+	// When
+	err := recipeService.DetachUser(2)
+
+	// Then
+	assert.NoError(t, err)
+	detached, err := recipeService.db.readRecipeWithTrashed(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(0), detached.CreatedByID)
+	assert.Equal(t, "Oma", detached.CreatedByName)
+	assert.Equal(t, uint(0), detached.LastEditedByID)
+	assert.Equal(t, "Oma", detached.LastEditedByName)
+}
@@ -0,0 +1,101 @@
+package recipe
+
+import (
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+func splitLines(text string) []string {
+	if len(text) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
+
+// maxDiffCells limits the size of the table diffLines needs for the lines
+// that differ, 250000 cells of 8 bytes are 2 MB. Larger changes are shown as
+// a whole.
+const maxDiffCells = 250000
+
+// diffLines compares two texts line by line based on their longest common
+// subsequence of lines. Removed lines come before the lines added in their
+// place. If too many lines differ, all of them are shown as replaced.
+func diffLines(oldText, newText string) []types.DiffLine {
+	oldLines, newLines := splitLines(oldText), splitLines(newText)
+
+	// The unchanged lines at the start and the end don't need the table
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	diff := []types.DiffLine{}
+	for _, line := range oldLines[:prefix] {
+		diff = append(diff, types.DiffLine{Kind: types.DiffEqual, Text: line})
+	}
+	oldMiddle := oldLines[prefix : len(oldLines)-suffix]
+	newMiddle := newLines[prefix : len(newLines)-suffix]
+	if len(oldMiddle)*len(newMiddle) > maxDiffCells {
+		diff = append(diff, replaceLines(oldMiddle, newMiddle)...)
+	} else {
+		diff = append(diff, diffLinesLcs(oldMiddle, newMiddle)...)
+	}
+	for _, line := range oldLines[len(oldLines)-suffix:] {
+		diff = append(diff, types.DiffLine{Kind: types.DiffEqual, Text: line})
+	}
+	return diff
+}
+
+// replaceLines shows all old lines as removed and all new lines as added.
+func replaceLines(oldLines, newLines []string) []types.DiffLine {
+	diff := make([]types.DiffLine, 0, len(oldLines)+len(newLines))
+	for _, line := range oldLines {
+		diff = append(diff, types.DiffLine{Kind: types.DiffRemoved, Text: line})
+	}
+	for _, line := range newLines {
+		diff = append(diff, types.DiffLine{Kind: types.DiffAdded, Text: line})
+	}
+	return diff
+}
+
+func diffLinesLcs(oldLines, newLines []string) []types.DiffLine {
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := []types.DiffLine{}
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			diff = append(diff, types.DiffLine{Kind: types.DiffEqual, Text: oldLines[i]})
+			i++
+			j++
+		case j == len(newLines) || (i < len(oldLines) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, types.DiffLine{Kind: types.DiffRemoved, Text: oldLines[i]})
+			i++
+		default:
+			diff = append(diff, types.DiffLine{Kind: types.DiffAdded, Text: newLines[j]})
+			j++
+		}
+	}
+	return diff
+}
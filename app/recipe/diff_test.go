@@ -0,0 +1,88 @@
+package recipe
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	testCases := []struct {
+		oldText string
+		newText string
+		diff    []types.DiffLine
+	}{
+		{oldText: "", newText: "", diff: []types.DiffLine{}},
+		{
+			oldText: "",
+			newText: "- 1 Ei",
+			diff:    []types.DiffLine{{Kind: types.DiffAdded, Text: "- 1 Ei"}},
+		},
+		{
+			oldText: "- 1 Ei\n- 200 g Mehl",
+			newText: "- 1 Ei\n- 200 g Mehl",
+			diff: []types.DiffLine{
+				{Kind: types.DiffEqual, Text: "- 1 Ei"},
+				{Kind: types.DiffEqual, Text: "- 200 g Mehl"},
+			},
+		},
+		{
+			oldText: "- 1 Ei\n- 200 g Mehl\n- Salz",
+			newText: "- 2 Eier\n- 200 g Mehl\n- Salz\n- Pfeffer",
+			diff: []types.DiffLine{
+				{Kind: types.DiffRemoved, Text: "- 1 Ei"},
+				{Kind: types.DiffAdded, Text: "- 2 Eier"},
+				{Kind: types.DiffEqual, Text: "- 200 g Mehl"},
+				{Kind: types.DiffEqual, Text: "- Salz"},
+				{Kind: types.DiffAdded, Text: "- Pfeffer"},
+			},
+		},
+		{
+			oldText: "1. Kneten\n2. Gehen lassen\n3. Backen",
+			newText: "1. Kneten\n2. Ruhen lassen\n3. Backen",
+			diff: []types.DiffLine{
+				{Kind: types.DiffEqual, Text: "1. Kneten"},
+				{Kind: types.DiffRemoved, Text: "2. Gehen lassen"},
+				{Kind: types.DiffAdded, Text: "2. Ruhen lassen"},
+				{Kind: types.DiffEqual, Text: "3. Backen"},
+			},
+		},
+		{
+			oldText: "1. Kneten\r\n2. Backen",
+			newText: "1. Backen",
+			diff: []types.DiffLine{
+				{Kind: types.DiffRemoved, Text: "1. Kneten"},
+				{Kind: types.DiffRemoved, Text: "2. Backen"},
+				{Kind: types.DiffAdded, Text: "1. Backen"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.diff, diffLines(test.oldText, test.newText), test.newText)
+	}
+}
+
+func TestDiffLinesTooManyChanges(t *testing.T) {
+	// Given
+	oldLines, newLines := []string{"Anfang"}, []string{"Anfang"}
+	for i := 0; i < 1000; i++ {
+		oldLines = append(oldLines, fmt.Sprintf("Alt %d", i))
+		newLines = append(newLines, fmt.Sprintf("Neu %d", i))
+	}
+	oldLines, newLines = append(oldLines, "Ende"), append(newLines, "Ende")
+
+	// When
+	diff := diffLines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+
+	// Then
+	assert.Equal(t, 2002, len(diff))
+	assert.Equal(t, types.DiffLine{Kind: types.DiffEqual, Text: "Anfang"}, diff[0])
+	assert.Equal(t, types.DiffLine{Kind: types.DiffRemoved, Text: "Alt 0"}, diff[1])
+	assert.Equal(t, types.DiffLine{Kind: types.DiffRemoved, Text: "Alt 999"}, diff[1000])
+	assert.Equal(t, types.DiffLine{Kind: types.DiffAdded, Text: "Neu 0"}, diff[1001])
+	assert.Equal(t, types.DiffLine{Kind: types.DiffEqual, Text: "Ende"}, diff[2001])
+}
@@ -0,0 +1,128 @@
+package recipe
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/ingredient"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+const (
+	minTitleSimilarity    = 0.85
+	minIngredientOverlap  = 0.8
+	minIngredientsToMatch = 3
+)
+
+// duplicateCandidate holds the normalized parts of a recipe that are
+// compared to find duplicates.
+type duplicateCandidate struct {
+	recipe      types.Recipe
+	title       []rune
+	ingredients map[string]bool
+	source      string
+}
+
+func newDuplicateCandidate(recipe types.Recipe) duplicateCandidate {
+	parsed := recipe.ParsedIngredients
+	if parsed == nil {
+		parsed = ingredient.Parse(recipe.Ingredients)
+	}
+	ingredients := make(map[string]bool)
+	for _, item := range parsed {
+		if name := ingredient.NormalizeName(item.Name); len(name) > 0 {
+			ingredients[name] = true
+		}
+	}
+	return duplicateCandidate{
+		recipe:      recipe,
+		title:       []rune(normalizeSearchText(recipe.Title)),
+		ingredients: ingredients,
+		source:      normalizeSourceUrl(recipe.Source),
+	}
+}
+
+// normalizeSourceUrl returns the comparable form of a source that is a web
+// address, e.g. "chefkoch.de/rezepte/123" for
+// "https://www.chefkoch.de/rezepte/123/#kommentare", and an empty string for
+// any other source.
+func normalizeSourceUrl(source string) string {
+	parsed, err := url.Parse(strings.TrimSpace(source))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(parsed.Host), "www.")
+	normalized := host + strings.TrimSuffix(parsed.Path, "/")
+	if len(parsed.RawQuery) > 0 {
+		normalized += "?" + parsed.RawQuery
+	}
+	return normalized
+}
+
+// titleSimilarity is 1 minus the edit distance of the titles relative to
+// the longer one.
+func titleSimilarity(a, b []rune) float64 {
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// ingredientOverlap is the share of ingredients both recipes have in common.
+// Recipes with only a few ingredients are not compared, they overlap too
+// easily.
+func ingredientOverlap(a, b map[string]bool) float64 {
+	if len(a) < minIngredientsToMatch || len(b) < minIngredientsToMatch {
+		return 0
+	}
+	common := 0
+	for name := range a {
+		if b[name] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%d %%", int(math.Round(value*100)))
+}
+
+// compareDuplicates returns why the two recipes are likely the same, or
+// nothing if they are not.
+func compareDuplicates(a, b duplicateCandidate) []string {
+	reasons := []string{}
+	if len(a.source) > 0 && a.source == b.source {
+		reasons = append(reasons, "Gleiche Quelle")
+	}
+	if similarity := titleSimilarity(a.title, b.title); similarity >= minTitleSimilarity {
+		reasons = append(reasons, "Ähnlicher Titel ("+formatPercent(similarity)+")")
+	}
+	if overlap := ingredientOverlap(a.ingredients, b.ingredients); overlap >= minIngredientOverlap {
+		reasons = append(reasons, "Ähnliche Zutaten ("+formatPercent(overlap)+")")
+	}
+	return reasons
+}
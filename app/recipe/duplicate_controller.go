@@ -0,0 +1,64 @@
+package recipe
+
+import (
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderDuplicatesPage(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderDuplicatesPage()"),
+		)
+	}
+	return rc.renderDuplicatesPage(c, "")
+}
+
+func (rc *RecipeController) renderDuplicatesPage(c echo.Context, message string) error {
+	pairs, err := rc.recipeService.findDuplicatePairs()
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderDuplicatesPage()"),
+		)
+	}
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.DuplicatesPage(true, pairs),
+		Message:   message,
+	})
+}
+
+func (rc *RecipeController) HandleMergeDuplicates(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleMergeDuplicates()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleMergeDuplicates()"))
+	}
+
+	keepId, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	removeId, err := rc.recipeService.getPathOtherId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if _, err := rc.recipeService.mergeDuplicates(keepId, removeId, rc.recipeService.getEditor(c)); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("merged recipe %d into recipe %d", removeId, keepId)
+	return rc.renderDuplicatesPage(c, "Rezepte zusammengeführt")
+}
@@ -0,0 +1,117 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderDuplicatesPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensupe"}))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderDuplicatesPage,
+				Method:      http.MethodGet,
+				Route:       "/admin/duplicates",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.RenderDuplicatesPage,
+				Method:        http.MethodGet,
+				Route:         "/admin/duplicates",
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "Ähnlicher Titel (90 %)",
+			},
+		)
+	})
+}
+
+func TestHandleMergeDuplicates(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensupe", Tags: "Suppe"}))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleMergeDuplicates,
+				Method:          http.MethodPost,
+				Route:           "/admin/duplicates/:id/merge/:other",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "other"},
+				PathParamValues: []string{"1", "2"},
+				StatusWant:      http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleMergeDuplicates,
+				Method:          http.MethodPost,
+				Route:           "/admin/duplicates/:id/merge/:other",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "other"},
+				PathParamValues: []string{"1", "2"},
+				StatusWant:      http.StatusForbidden,
+				Role:            types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("invalid other id", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleMergeDuplicates,
+				Method:          http.MethodPost,
+				Route:           "/admin/duplicates/:id/merge/:other",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "other"},
+				PathParamValues: []string{"1", "abc"},
+				StatusWant:      http.StatusBadRequest,
+				Authorized:      true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleMergeDuplicates,
+				Method:          http.MethodPost,
+				Route:           "/admin/duplicates/:id/merge/:other",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "other"},
+				PathParamValues: []string{"1", "2"},
+				StatusWant:      http.StatusOK,
+				Authorized:      true,
+				AssertMessage:   true,
+				MessageWant:     "Rezepte zusammengeführt",
+			},
+		)
+		recipe, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Suppe", recipe.Tags)
+	})
+}
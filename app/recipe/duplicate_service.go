@@ -0,0 +1,143 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+// findDuplicates returns the existing recipes that are likely the same as
+// the given one. Pending recipes are only compared for admins, the matches
+// are shown to the user.
+func (rs *recipeService) findDuplicates(recipe types.Recipe, isAdmin bool) ([]types.DuplicateMatch, error) {
+	matches := []types.DuplicateMatch{}
+
+	recipes, err := rs.readAllRecipes(isAdmin)
+	if err != nil {
+		return matches, errutil.AddMessageToAppError(err, "failed at findDuplicates()")
+	}
+
+	candidate := newDuplicateCandidate(recipe)
+	for _, other := range recipes {
+		if other.ID == recipe.ID {
+			continue
+		}
+		if reasons := compareDuplicates(candidate, newDuplicateCandidate(other)); len(reasons) > 0 {
+			matches = append(matches, types.DuplicateMatch{ID: other.ID, Title: other.Title, Reasons: reasons})
+		}
+	}
+	return matches, nil
+}
+
+// findDuplicatePairs compares all recipes with each other and returns the
+// pairs that are likely the same recipe.
+func (rs *recipeService) findDuplicatePairs() ([]types.DuplicatePair, error) {
+	pairs := []types.DuplicatePair{}
+
+	recipes, err := rs.readAllRecipes(true)
+	if err != nil {
+		return pairs, errutil.AddMessageToAppError(err, "failed at findDuplicatePairs()")
+	}
+
+	candidates := make([]duplicateCandidate, len(recipes))
+	for i, recipe := range recipes {
+		candidates[i] = newDuplicateCandidate(recipe)
+	}
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			reasons := compareDuplicates(candidates[i], candidates[j])
+			if len(reasons) == 0 {
+				continue
+			}
+			pairs = append(pairs, types.DuplicatePair{
+				First:   types.DuplicateMatch{ID: recipes[i].ID, Title: recipes[i].Title},
+				Second:  types.DuplicateMatch{ID: recipes[j].ID, Title: recipes[j].Title},
+				Reasons: reasons,
+			})
+		}
+	}
+	return pairs, nil
+}
+
+func (rs *recipeService) getPathOtherId(c echo.Context) (uint, error) {
+	id, err := strconv.Atoi(c.Param("other"))
+	if err != nil {
+		return 0, &errutil.AppError{
+			UserMessage: "Ungültiges Pfadparameter",
+			Err: fmt.Errorf(
+				"failed at getPathOtherId() with parameter %s: %w",
+				c.Param("other"),
+				err,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return uint(id), nil
+}
+
+// mergeDuplicates keeps the first recipe and moves the other one to the
+// trash. The kept recipe gets the tags of the other one and its description,
+// author, source and servings where it has none, the editor is recorded for
+// these changes.
+func (rs *recipeService) mergeDuplicates(keepId, removeId uint, editor types.User) (types.Recipe, error) {
+	keep, err := rs.readRecipe(keepId)
+	if err != nil {
+		return keep, errutil.AddMessageToAppError(err, "failed at mergeDuplicates()")
+	}
+	if keepId == removeId {
+		return keep, &errutil.AppError{
+			UserMessage: "Ein Rezept kann nicht mit sich selbst zusammengeführt werden",
+			Err:         fmt.Errorf("failed at mergeDuplicates(), same recipe %d", keepId),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	remove, err := rs.readRecipe(removeId)
+	if err != nil {
+		return keep, errutil.AddMessageToAppError(err, "failed at mergeDuplicates()")
+	}
+
+	before := types.NewRecipeRevision(keep, "", "")
+	tags := keep.ParseTags()
+	for _, tag := range remove.ParseTags() {
+		if !containsTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	keep.Tags = strings.Join(tags, ", ")
+	if len(keep.Description) == 0 {
+		keep.Description = remove.Description
+	}
+	if len(keep.Author) == 0 {
+		keep.Author = remove.Author
+	}
+	if len(keep.Source) == 0 {
+		keep.Source = remove.Source
+	}
+	if keep.Servings == 0 {
+		keep.Servings = remove.Servings
+	}
+
+	if len(getChangedFields(before, types.NewRecipeRevision(keep, "", ""))) > 0 {
+		if err := rs.updateRecipe(&keep, editor); err != nil {
+			return keep, errutil.AddMessageToAppError(err, "failed at mergeDuplicates()")
+		}
+	}
+	if err := rs.trashRecipe(removeId); err != nil {
+		return keep, errutil.AddMessageToAppError(err, "failed at mergeDuplicates()")
+	}
+	return keep, nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, existing := range tags {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
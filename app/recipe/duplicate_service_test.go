@@ -0,0 +1,110 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Tomatensuppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Brot", Source: "https://example.com/brot"}))
+
+	// When
+	duplicates, err := recipeService.findDuplicates(types.Recipe{Title: "Linsensupe", Source: "https://www.example.com/brot/"}, true)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []types.DuplicateMatch{
+		{ID: 3, Title: "Brot", Reasons: []string{"Gleiche Quelle"}},
+		{ID: 1, Title: "Linsensuppe", Reasons: []string{"Ähnlicher Titel (90 %)"}},
+	}, duplicates)
+
+	// When
+	duplicates, err = recipeService.findDuplicates(types.Recipe{ID: 1, Title: "Linsensuppe"}, true)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+
+	// When
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Kuchen", Pending: true}))
+	duplicates, err = recipeService.findDuplicates(types.Recipe{Title: "Kuchen"}, false)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, duplicates)
+
+	// When
+	duplicates, err = recipeService.findDuplicates(types.Recipe{Title: "Kuchen"}, true)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(duplicates))
+}
+
+func TestFindDuplicatePairs(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Tomatensuppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensupe"}))
+
+	// When
+	pairs, err := recipeService.findDuplicatePairs()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []types.DuplicatePair{{
+		First:   types.DuplicateMatch{ID: 3, Title: "Linsensupe"},
+		Second:  types.DuplicateMatch{ID: 1, Title: "Linsensuppe"},
+		Reasons: []string{"Ähnlicher Titel (90 %)"},
+	}}, pairs)
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe", Tags: "Suppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{
+		Title:       "Linsensupe",
+		Description: "Deftig",
+		Author:      "Oma",
+		Servings:    4,
+		Tags:        "suppe, Vegan",
+	}))
+
+	// When
+	_, err := recipeService.mergeDuplicates(1, 1, newTestEditor())
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	_, err = recipeService.mergeDuplicates(1, 3, newTestEditor())
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	recipe, err := recipeService.mergeDuplicates(1, 2, newTestEditor())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Linsensuppe", recipe.Title)
+	assert.Equal(t, "Deftig", recipe.Description)
+	assert.Equal(t, "Oma", recipe.Author)
+	assert.Equal(t, 4, recipe.Servings)
+	assert.Equal(t, "Suppe, Vegan", recipe.Tags)
+	_, err = recipeService.readRecipe(2)
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+	trash, err := recipeService.readTrash()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(trash))
+}
@@ -0,0 +1,51 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSourceUrl(t *testing.T) {
+	assert.Equal(t, "chefkoch.de/rezepte/123", normalizeSourceUrl("https://www.chefkoch.de/rezepte/123/#kommentare"))
+	assert.Equal(t, "chefkoch.de/rezepte/123", normalizeSourceUrl(" http://chefkoch.de/rezepte/123 "))
+	assert.Equal(t, "example.com/rezept?id=5", normalizeSourceUrl("https://example.com/rezept?id=5"))
+	assert.Equal(t, "", normalizeSourceUrl("Omas Kochbuch"))
+	assert.Equal(t, "", normalizeSourceUrl("ftp://example.com/rezept"))
+	assert.Equal(t, "", normalizeSourceUrl(""))
+}
+
+func TestTitleSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, titleSimilarity([]rune("linsensupp"), []rune("linsensupp")))
+	assert.InDelta(t, 0.9, titleSimilarity([]rune("linsensupp"), []rune("linsensup")), 0.001)
+	assert.Equal(t, 0.0, titleSimilarity([]rune(""), []rune("")))
+	assert.Less(t, titleSimilarity([]rune("linsensupp"), []rune("tomatensupp")), minTitleSimilarity)
+}
+
+func TestIngredientOverlap(t *testing.T) {
+	a := map[string]bool{"mehl": true, "wasser": true, "salz": true, "hefe": true}
+	b := map[string]bool{"mehl": true, "wasser": true, "salz": true, "zucker": true}
+	assert.InDelta(t, 0.6, ingredientOverlap(a, b), 0.001)
+	assert.Equal(t, 1.0, ingredientOverlap(a, a))
+	assert.Equal(t, 0.0, ingredientOverlap(map[string]bool{"mehl": true}, map[string]bool{"mehl": true}))
+}
+
+func TestCompareDuplicates(t *testing.T) {
+	brot := newDuplicateCandidate(types.Recipe{
+		Title:       "Einfaches Brot",
+		Source:      "https://www.chefkoch.de/rezepte/123",
+		Ingredients: "- 500 g Mehl\n- 300 ml Wasser\n- 1 TL Salz\n- 1 Pck. Hefe",
+	})
+
+	assert.Equal(t, []string{"Gleiche Quelle", "Ähnlicher Titel (100 %)", "Ähnliche Zutaten (100 %)"}, compareDuplicates(brot, brot))
+	assert.Equal(t, []string{"Ähnliche Zutaten (100 %)"}, compareDuplicates(brot, newDuplicateCandidate(types.Recipe{
+		Title:       "Weißbrot",
+		Ingredients: "- 1 kg Mehl\n- 600 ml Wasser\n- 2 TL Salz\n- 2 Pck. Hefe",
+	})))
+	assert.Equal(t, []string{"Gleiche Quelle"}, compareDuplicates(brot, newDuplicateCandidate(types.Recipe{
+		Title:  "Brötchen",
+		Source: "http://chefkoch.de/rezepte/123/",
+	})))
+	assert.Empty(t, compareDuplicates(brot, newDuplicateCandidate(types.Recipe{Title: "Linsensuppe"})))
+}
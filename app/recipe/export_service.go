@@ -0,0 +1,169 @@
+package recipe
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+// exportedRecipe has the schema of getRecipeAsJson plus the fields that are
+// hidden there but needed to restore a backup. DeletedAt is only set for
+// recipes in the trash.
+type exportedRecipe struct {
+	types.Recipe
+	Pending        bool   `json:"pending"`
+	LastModifiedAt string `json:"lastModifiedAt"`
+	DeletedAt      string `json:"deletedAt,omitempty"`
+}
+
+type exportManifest struct {
+	ExportedAt   string `json:"exportedAt"`
+	RecipeCount  int    `json:"recipeCount"`
+	PendingCount int    `json:"pendingCount"`
+	TrashedCount int    `json:"trashedCount"`
+}
+
+// exportRecipes writes a ZIP archive with a JSON and a Markdown file per
+// recipe and a manifest to w. Recipes in the trash are exported as well, so
+// that the import can put them back into the trash. The recipes are streamed from the database and
+// written to the archive one by one.
+func (rs *recipeService) exportRecipes(w io.Writer, now time.Time) error {
+	createError := func(err error) error {
+		return &errutil.AppError{
+			UserMessage: "Fehler beim Export",
+			Err:         fmt.Errorf("failed at exportRecipes(): %w", err),
+			StatusCode:  http.StatusInternalServerError,
+		}
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := exportManifest{ExportedAt: now.Format(time.RFC3339)}
+
+	writeFile := func(name string, content []byte) error {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(content)
+		return err
+	}
+
+	err := rs.db.streamRecipes(func(recipe types.Recipe) error {
+		exported := exportedRecipe{
+			Recipe:         recipe,
+			Pending:        recipe.Pending,
+			LastModifiedAt: recipe.LastModifiedAt,
+		}
+		if recipe.DeletedAt.Valid {
+			exported.DeletedAt = recipe.DeletedAt.Time.Format(time.RFC3339)
+		}
+		jsonRecipe, err := json.MarshalIndent(exported, "", "  ")
+		if err != nil {
+			return createError(err)
+		}
+
+		name := exportFileName(recipe)
+		for _, file := range []struct {
+			name    string
+			content []byte
+		}{
+			{"json/" + name + ".json", jsonRecipe},
+			{"markdown/" + name + ".md", []byte(formatRecipeAsMarkdown(recipe))},
+		} {
+			if err := writeFile(file.name, file.content); err != nil {
+				return createError(err)
+			}
+		}
+
+		manifest.RecipeCount++
+		if recipe.Pending {
+			manifest.PendingCount++
+		}
+		if recipe.DeletedAt.Valid {
+			manifest.TrashedCount++
+		}
+		return nil
+	})
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at exportRecipes()")
+	}
+
+	jsonManifest, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return createError(err)
+	}
+	if err := writeFile("manifest.json", jsonManifest); err != nil {
+		return createError(err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return createError(err)
+	}
+	return nil
+}
+
+// exportFileName returns a name like "0012-kaesespaetzle" that keeps the
+// files of an export sorted by id and readable.
+func exportFileName(recipe types.Recipe) string {
+	slug := strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss").
+		Replace(strings.ToLower(recipe.Title))
+	slug = strings.Join(strings.FieldsFunc(slug, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	}), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	if len(slug) == 0 {
+		return fmt.Sprintf("%04d", recipe.ID)
+	}
+	return fmt.Sprintf("%04d-%s", recipe.ID, slug)
+}
+
+func formatRecipeAsMarkdown(recipe types.Recipe) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("# %s\n\n", recipe.Title))
+	if len(recipe.Description) > 0 {
+		builder.WriteString(fmt.Sprintf("%s\n\n", recipe.Description))
+	}
+
+	servings := ""
+	if recipe.Servings > 0 {
+		servings = strconv.Itoa(recipe.Servings)
+	}
+
+	details := []struct {
+		label string
+		value string
+	}{
+		{"Autor", recipe.Author},
+		{"Quelle", recipe.Source},
+		{"Kochzeit", fmt.Sprintf("%d Minuten", recipe.Duration)},
+		{"Gesamtzeit", fmt.Sprintf("%d Minuten", recipe.GetTotalDuration())},
+		{"Portionen", servings},
+		{"Tags", strings.Join(recipe.ParseTags(), ", ")},
+	}
+	for _, detail := range details {
+		if len(detail.value) > 0 {
+			builder.WriteString(fmt.Sprintf("- **%s:** %s\n", detail.label, detail.value))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("\n## Zutaten\n\n%s\n", strings.TrimSpace(recipe.Ingredients)))
+	builder.WriteString(fmt.Sprintf("\n## Zubereitung\n\n%s\n", strings.TrimSpace(recipe.Instructions)))
+
+	return builder.String()
+}
@@ -0,0 +1,104 @@
+package recipe
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func readTestZip(t *testing.T, data []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		content, err := file.Open()
+		assert.NoError(t, err)
+		data, err := io.ReadAll(content)
+		assert.NoError(t, err)
+		content.Close()
+		files[file.Name] = string(data)
+	}
+	return files
+}
+
+func TestExportRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{
+		Title:          "Käsespätzle",
+		Description:    "Mit Röstzwiebeln",
+		Duration:       30,
+		Servings:       4,
+		Ingredients:    "- 500 g Spätzle",
+		Instructions:   "Kochen",
+		Tags:           "Hauptgericht, Vegetarisch",
+		LastModifiedAt: "2024-01-02T10:00:00Z",
+	}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Geheim", Pending: true}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Alt"}))
+	assert.NoError(t, recipeService.trashRecipeAt(3, time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)))
+	now := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	// When
+	var buffer bytes.Buffer
+	err := recipeService.exportRecipes(&buffer, now)
+
+	// Then
+	assert.NoError(t, err)
+	files := readTestZip(t, buffer.Bytes())
+	assert.Equal(t, 7, len(files))
+
+	var manifest exportManifest
+	assert.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Equal(t, exportManifest{ExportedAt: "2024-01-03T12:00:00Z", RecipeCount: 3, PendingCount: 1, TrashedCount: 1}, manifest)
+
+	var exported map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(files["json/0001-kaesespaetzle.json"]), &exported))
+	assert.Equal(t, "Käsespätzle", exported["title"])
+	assert.Equal(t, false, exported["pending"])
+	assert.Equal(t, "2024-01-02T10:00:00Z", exported["lastModifiedAt"])
+	assert.NotContains(t, exported, "deletedAt")
+
+	assert.NoError(t, json.Unmarshal([]byte(files["json/0002-geheim.json"]), &exported))
+	assert.Equal(t, true, exported["pending"])
+
+	exported = map[string]any{}
+	assert.NoError(t, json.Unmarshal([]byte(files["json/0003-alt.json"]), &exported))
+	assert.Equal(t, "Alt", exported["title"])
+	deletedAt, err := time.Parse(time.RFC3339, exported["deletedAt"].(string))
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC).Equal(deletedAt))
+
+	assert.Equal(
+		t,
+		"# Käsespätzle\n\nMit Röstzwiebeln\n\n"+
+			"- **Kochzeit:** 30 Minuten\n"+
+			"- **Gesamtzeit:** 30 Minuten\n"+
+			"- **Portionen:** 4\n"+
+			"- **Tags:** Hauptgericht, Vegetarisch\n\n"+
+			"## Zutaten\n\n- 500 g Spätzle\n\n"+
+			"## Zubereitung\n\nKochen\n",
+		files["markdown/0001-kaesespaetzle.md"],
+	)
+}
+
+func TestExportFileName(t *testing.T) {
+	testCases := []struct {
+		recipe types.Recipe
+		name   string
+	}{
+		{types.Recipe{ID: 12, Title: "Käsespätzle"}, "0012-kaesespaetzle"},
+		{types.Recipe{ID: 1, Title: "Chili sin Carne (vegan!)"}, "0001-chili-sin-carne-vegan"},
+		{types.Recipe{ID: 3, Title: "???"}, "0003"},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.name, exportFileName(test.recipe))
+	}
+}
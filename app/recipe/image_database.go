@@ -0,0 +1,36 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+// imageReference holds the columns that can refer to stored images.
+type imageReference struct {
+	Image        string
+	Instructions string
+}
+
+// readImageReferences returns the image references of all recipes, including
+// the ones in the trash, and of their revisions and suggestions.
+func (db *recipeDatabase) readImageReferences() ([]imageReference, error) {
+	references := []imageReference{}
+	for _, model := range []any{&types.Recipe{}, &types.RecipeRevision{}, &types.RecipeSuggestion{}} {
+		var rows []imageReference
+		if err := db.handler.Unscoped().Model(model).Select("image", "instructions").Find(&rows).Error; err != nil {
+			return references, &errutil.AppError{
+				UserMessage: "Datenbankfehler",
+				Err: fmt.Errorf(
+					"failed at readImageReferences(), database failure: %w",
+					err,
+				),
+				StatusCode: http.StatusInternalServerError,
+			}
+		}
+		references = append(references, rows...)
+	}
+	return references, nil
+}
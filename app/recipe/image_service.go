@@ -0,0 +1,77 @@
+package recipe
+
+import (
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/imageutil"
+)
+
+// unusedImageGracePeriod is how long an uploaded image may stay unused, it
+// covers the time between uploading an image and saving the recipe.
+const unusedImageGracePeriod = 24 * time.Hour
+
+// addImageKeys adds the image and the images embedded in the instructions to
+// keys.
+func addImageKeys(keys map[string]bool, image, instructions string) {
+	if len(image) > 0 {
+		keys[image] = true
+	}
+	for _, key := range imageutil.KeysInText(instructions) {
+		keys[key] = true
+	}
+}
+
+// readReferencedImages returns the keys of all images a recipe, a revision or
+// a suggestion refers to.
+func (rs *recipeService) readReferencedImages() (map[string]bool, error) {
+	keys := make(map[string]bool)
+	references, err := rs.db.readImageReferences()
+	if err != nil {
+		return keys, errutil.AddMessageToAppError(err, "failed at readReferencedImages()")
+	}
+	for _, reference := range references {
+		addImageKeys(keys, reference.Image, reference.Instructions)
+	}
+	return keys, nil
+}
+
+// deleteUnreferencedImages deletes the candidates no recipe, revision or
+// suggestion refers to anymore.
+func (rs *recipeService) deleteUnreferencedImages(candidates map[string]bool) error {
+	referenced, err := rs.readReferencedImages()
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteUnreferencedImages()")
+	}
+	for key := range candidates {
+		if !referenced[key] {
+			rs.deleteImage(key)
+		}
+	}
+	return nil
+}
+
+// purgeUnusedImages deletes the images that were saved before the grace
+// period and are not referred to, like uploads for recipes that were never
+// saved, and returns their number.
+func (rs *recipeService) purgeUnusedImages(now time.Time) (int, error) {
+	images, err := rs.images.List()
+	if err != nil {
+		return 0, errutil.AddMessageToAppError(err, "failed at purgeUnusedImages()")
+	}
+	referenced, err := rs.readReferencedImages()
+	if err != nil {
+		return 0, errutil.AddMessageToAppError(err, "failed at purgeUnusedImages()")
+	}
+
+	purged := 0
+	deadline := now.Add(-unusedImageGracePeriod)
+	for _, image := range images {
+		if referenced[image.Key] || image.SavedAt.After(deadline) {
+			continue
+		}
+		rs.deleteImage(image.Key)
+		purged++
+	}
+	return purged, nil
+}
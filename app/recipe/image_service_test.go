@@ -0,0 +1,70 @@
+package recipe
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/imageutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStoredImage(t *testing.T, recipeService *recipeService) string {
+	data, img, err := imageutil.Validate(bytes.NewReader(newTestPng(t)))
+	assert.NoError(t, err)
+	key, err := recipeService.images.Save(data, img)
+	assert.NoError(t, err)
+	return key
+}
+
+func newTestInstructionsWithImage(key string) string {
+	return "Teig kneten\n\n![](" + imageutil.Url(key, imageutil.VariantLarge) + ")"
+}
+
+func TestPurgeUnusedImages(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	cover := newTestStoredImage(t, recipeService)
+	inline := newTestStoredImage(t, recipeService)
+	unused := newTestStoredImage(t, recipeService)
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Brot", Image: cover}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Kuchen", Instructions: newTestInstructionsWithImage(inline)}))
+	assert.NoError(t, recipeService.trashRecipe(2))
+
+	// When
+	purged, err := recipeService.purgeUnusedImages(time.Now())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	// When
+	purged, err = recipeService.purgeUnusedImages(time.Now().Add(unusedImageGracePeriod + time.Minute))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.DirExists(t, filepath.Join(recipeService.images.Dir(), cover))
+	assert.DirExists(t, filepath.Join(recipeService.images.Dir(), inline))
+	assert.NoDirExists(t, filepath.Join(recipeService.images.Dir(), unused))
+}
+
+func TestDeleteRecipeDeletesInlineImages(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	inline := newTestStoredImage(t, recipeService)
+	shared := newTestStoredImage(t, recipeService)
+	recipe := types.Recipe{Title: "Brot", Instructions: newTestInstructionsWithImage(inline) + newTestInstructionsWithImage(shared)}
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Kuchen", Instructions: newTestInstructionsWithImage(shared)}))
+
+	// When
+	err := recipeService.deleteRecipe(recipe.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.NoDirExists(t, filepath.Join(recipeService.images.Dir(), inline))
+	assert.DirExists(t, filepath.Join(recipeService.images.Dir(), shared))
+}
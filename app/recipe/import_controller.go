@@ -0,0 +1,96 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderRecipeImportPage(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderRecipeImportPage()"),
+		)
+	}
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.RecipeImportPage(true, []types.RecipeImportResult{}),
+	})
+}
+
+func (rc *RecipeController) HandleImportRecipes(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleImportRecipes()"),
+		)
+	}
+
+	files, err := rc.recipeService.getImportFiles(c)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleImportRecipes()"),
+		)
+	}
+
+	pending := c.FormValue("pending") == "true" || c.FormValue("pending") == "on"
+	allowDuplicates := c.FormValue("allowDuplicates") == "true" || c.FormValue("allowDuplicates") == "on"
+	results := rc.recipeService.importRecipes(files, pending, allowDuplicates, rc.recipeService.getEditor(c))
+
+	imported := 0
+	for _, result := range results {
+		if result.Success() {
+			imported++
+		}
+	}
+	rc.logger.Infof("imported %d of %d recipes", imported, len(results))
+
+	options := render.RenderComponentOptions{
+		Context:   c,
+		Component: components.RecipeImportPage(true, results),
+		Message:   fmt.Sprintf("%d von %d Rezepten importiert", imported, len(results)),
+	}
+	if imported == 0 {
+		options.Err = &errutil.AppError{
+			UserMessage: "Kein Rezept importiert",
+			Err:         fmt.Errorf("failed at HandleImportRecipes(), no valid recipes: %v", results),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return rc.renderer.RenderComponent(options)
+}
+
+// HandleImportRecipeFromHtml fills the form of the new recipe page with the
+// recipe of a saved web page.
+func (rc *RecipeController) HandleImportRecipeFromHtml(c echo.Context) error {
+	recipe, err := rc.recipeService.getRecipeFromHtml(c)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleImportRecipeFromHtml()"),
+		)
+	}
+
+	duplicates, err := rc.recipeService.findDuplicates(recipe, servutil.IsAuthorized(c))
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleImportRecipeFromHtml()"),
+		)
+	}
+
+	formElements := rc.recipeService.createRecipeForm(c, recipe, make(map[string]error))
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.RecipeNewPage(formElements, servutil.GetRole(c), true, duplicates, ""),
+		Message:   "Rezept übernommen",
+	})
+}
@@ -0,0 +1,210 @@
+package recipe
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderRecipeImportPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderRecipeImportPage,
+				Method:      http.MethodGet,
+				Route:       "/recipe/import",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderRecipeImportPage,
+				Method:      http.MethodGet,
+				Route:       "/recipe/import",
+				StatusWant:  http.StatusForbidden,
+				Role:        types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderRecipeImportPage,
+				Method:      http.MethodGet,
+				Route:       "/recipe/import",
+				StatusWant:  http.StatusOK,
+				Authorized:  true,
+			},
+		)
+	})
+}
+
+func TestHandleImportRecipes(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleImportRecipes,
+				Method:       http.MethodPost,
+				Route:        "/recipe/import",
+				WithJSONData: true,
+				JSONData:     `{}`,
+				StatusWant:   http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleImportRecipes,
+				Method:       http.MethodPost,
+				Route:        "/recipe/import",
+				WithJSONData: true,
+				JSONData:     `{}`,
+				StatusWant:   http.StatusForbidden,
+				Role:         types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("no files", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleImportRecipes,
+				Method:        http.MethodPost,
+				Route:         "/recipe/import",
+				WithFormData:  true,
+				FormData:      "pending=true",
+				StatusWant:    http.StatusBadRequest,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "Keine Dateien ausgewählt",
+			},
+		)
+	})
+
+	t.Run("invalid recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleImportRecipes,
+				Method:        http.MethodPost,
+				Route:         "/recipe/import",
+				WithJSONData:  true,
+				JSONData:      `{"title": "title"}`,
+				StatusWant:    http.StatusBadRequest,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "Bitte trage eine Rezeptbeschreibung ein",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleImportRecipes,
+				Method:       http.MethodPost,
+				Route:        "/recipe/import",
+				WithJSONData: true,
+				JSONData: `[
+					{"title": "a", "description": "d", "ingredients": "i", "instructions": "i"},
+					{"title": "b", "description": "d", "ingredients": "i", "instructions": "i"}
+				]`,
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "2 von 2 Rezepten importiert",
+			},
+		)
+	})
+}
+
+func TestHandleImportRecipeFromHtml(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("no html", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleImportRecipeFromHtml,
+				Method:        http.MethodPost,
+				Route:         "/recipe/new/html",
+				WithFormData:  true,
+				FormData:      "html=",
+				StatusWant:    http.StatusBadRequest,
+				AssertMessage: true,
+				MessageWant:   "Keine Webseite ausgewählt",
+			},
+		)
+	})
+
+	t.Run("no recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleImportRecipeFromHtml,
+				Method:        http.MethodPost,
+				Route:         "/recipe/new/html",
+				WithFormData:  true,
+				FormData:      "html=" + url.QueryEscape("<html><body>Kein Rezept</body></html>"),
+				StatusWant:    http.StatusBadRequest,
+				AssertMessage: true,
+				MessageWant:   "Kein Rezept auf der Webseite gefunden",
+			},
+		)
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		// Given
+		page := `<link rel="canonical" href="https://example.com/rezept">
+		<script type="application/ld+json">{
+			"@type": "Recipe",
+			"name": "Imported Title",
+			"cookTime": "PT1H5M",
+			"recipeIngredient": ["100 g Mehl"]
+		}</script>`
+
+		// When
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleImportRecipeFromHtml,
+				Method:        http.MethodPost,
+				Route:         "/recipe/new/html",
+				WithFormData:  true,
+				FormData:      "html=" + url.QueryEscape(page),
+				StatusWant:    http.StatusOK,
+				AssertMessage: true,
+				MessageWant:   "Rezept übernommen",
+			},
+		)
+
+		// Then
+		body := rr.Body.String()
+		assert.Contains(t, body, `value="Imported Title"`)
+		assert.Contains(t, body, `value="https://example.com/rezept"`)
+		assert.Contains(t, body, `value="65"`)
+		assert.Contains(t, body, "- 100 g Mehl")
+		assert.Contains(t, body, `id="recipe-new-imported"`)
+	})
+}
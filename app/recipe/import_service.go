@@ -0,0 +1,303 @@
+package recipe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/cooklang"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/schemaorg"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+const maxImportFileSize = 5 << 20
+
+// validationOrder is the order in which validation errors of an imported
+// recipe are reported, the same order as the fields of the form.
+var validationOrder = []string{
+	"title",
+	"description",
+	"servings",
+	"ingredients",
+	"instructions",
+}
+
+// importedRecipe is a recipe of an import file, DeletedAt is set for recipes
+// that were in the trash when they were exported.
+type importedRecipe struct {
+	types.Recipe
+	DeletedAt string `json:"deletedAt"`
+}
+
+type importFile struct {
+	name     string
+	data     []byte
+	tooLarge bool
+}
+
+// getImportFiles reads the uploaded files of the import form. A request with
+// a JSON body is treated as a single file, so that the endpoint can also be
+// used without the form.
+func (rs *recipeService) getImportFiles(c echo.Context) ([]importFile, error) {
+	createError := func(message string, statusCode int, err error) error {
+		return &errutil.AppError{
+			UserMessage: message,
+			Err:         fmt.Errorf("failed at getImportFiles(): %w", err),
+			StatusCode:  statusCode,
+		}
+	}
+
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		data, err := io.ReadAll(io.LimitReader(c.Request().Body, maxImportFileSize+1))
+		if err != nil {
+			return nil, createError("Fehler beim Lesen der Anfrage", http.StatusBadRequest, err)
+		}
+		if len(data) > maxImportFileSize {
+			return nil, createError("Anfrage zu groß", http.StatusRequestEntityTooLarge, fmt.Errorf("body exceeds %d bytes", maxImportFileSize))
+		}
+		return []importFile{{name: "Anfrage", data: data}}, nil
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, createError("Keine Dateien ausgewählt", http.StatusBadRequest, err)
+	}
+	headers := form.File["files"]
+	if len(headers) == 0 {
+		return nil, createError("Keine Dateien ausgewählt", http.StatusBadRequest, fmt.Errorf("no files"))
+	}
+
+	files := make([]importFile, 0, len(headers))
+	for _, header := range headers {
+		if header.Size > maxImportFileSize {
+			files = append(files, importFile{name: header.Filename, tooLarge: true})
+			continue
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, createError("Fehler beim Lesen der Dateien", http.StatusBadRequest, err)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, createError("Fehler beim Lesen der Dateien", http.StatusBadRequest, err)
+		}
+		files = append(files, importFile{name: header.Filename, data: data})
+	}
+
+	return files, nil
+}
+
+// importRecipes creates the recipes of all files and reports the outcome
+// per recipe. A file can contain a single recipe in the format of
+// getRecipeAsJson or an array of them, files ending in ".cook" a recipe in
+// the Cooklang format. Recipes exported from the trash are put back into the
+// trash. Invalid recipes and, unless allowed, likely
+// duplicates of existing recipes are skipped, the others are created anyway
+// with creator as their creator.
+func (rs *recipeService) importRecipes(files []importFile, pending bool, allowDuplicates bool, creator types.User) []types.RecipeImportResult {
+	results := []types.RecipeImportResult{}
+
+	for _, file := range files {
+		if file.tooLarge {
+			results = append(results, types.RecipeImportResult{
+				Source: file.name,
+				Errors: []string{fmt.Sprintf("Die Datei ist größer als %d MB", maxImportFileSize>>20)},
+			})
+			continue
+		}
+
+		if strings.EqualFold(filepath.Ext(file.name), ".cook") {
+			recipe, err := cooklang.Parse(file.name, string(file.data))
+			if err != nil {
+				results = append(results, types.RecipeImportResult{
+					Source: file.name,
+					Errors: []string{"Ungültiges Cooklang: " + err.Error()},
+				})
+				continue
+			}
+			results = append(results, rs.importRecipe(file.name, importedRecipe{Recipe: recipe}, pending, allowDuplicates, creator))
+			continue
+		}
+
+		recipes, err := rs.decodeImportFile(file.data)
+		if err != nil {
+			results = append(results, types.RecipeImportResult{
+				Source: file.name,
+				Errors: []string{"Ungültiges JSON: " + err.Error()},
+			})
+			continue
+		}
+
+		for i, recipe := range recipes {
+			source := file.name
+			if len(recipes) > 1 {
+				source = fmt.Sprintf("%s [%d]", file.name, i+1)
+			}
+			results = append(results, rs.importRecipe(source, recipe, pending, allowDuplicates, creator))
+		}
+	}
+
+	return results
+}
+
+func (rs *recipeService) decodeImportFile(data []byte) ([]importedRecipe, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("leere Datei")
+	}
+
+	if trimmed[0] == '[' {
+		var recipes []importedRecipe
+		if err := json.Unmarshal(trimmed, &recipes); err != nil {
+			return nil, err
+		}
+		if len(recipes) == 0 {
+			return nil, fmt.Errorf("leere Liste")
+		}
+		return recipes, nil
+	}
+
+	var recipe importedRecipe
+	if err := json.Unmarshal(trimmed, &recipe); err != nil {
+		return nil, err
+	}
+	return []importedRecipe{recipe}, nil
+}
+
+func (rs *recipeService) importRecipe(source string, imported importedRecipe, pending bool, allowDuplicates bool, creator types.User) types.RecipeImportResult {
+	recipe := imported.Recipe
+	recipe.ID = 0
+	recipe.Author = strings.TrimSpace(recipe.Author)
+	recipe.Source = strings.TrimSpace(recipe.Source)
+	recipe.Title = strings.TrimSpace(recipe.Title)
+	recipe.Description = strings.TrimSpace(recipe.Description)
+	recipe.Tags = strings.TrimSpace(recipe.Tags)
+	recipe.Ingredients = strings.TrimSpace(recipe.Ingredients)
+	recipe.Instructions = strings.TrimSpace(recipe.Instructions)
+	recipe.Pending = pending
+	recipe.LastModifiedAt = ""
+	recipe.CreatedByID = creator.ID
+	recipe.CreatedByName = creator.Name
+	if _, err := time.Parse(time.RFC3339, recipe.CreatedAt); err != nil {
+		recipe.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+
+	result := types.RecipeImportResult{Source: source, Title: recipe.Title}
+
+	var deletedAt time.Time
+	if len(imported.DeletedAt) > 0 {
+		parsed, err := time.Parse(time.RFC3339, imported.DeletedAt)
+		if err != nil {
+			result.Errors = append(result.Errors, "Ungültiges Löschdatum: "+imported.DeletedAt)
+			return result
+		}
+		deletedAt = parsed
+	}
+
+	validationErrors := rs.validateRecipe(&recipe)
+	for _, key := range validationOrder {
+		if err, ok := validationErrors[key]; ok {
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result
+	}
+
+	if !allowDuplicates {
+		duplicates, err := rs.findDuplicates(recipe, true)
+		if err != nil {
+			rs.logger.Error(errutil.AddMessageToAppError(err, "failed at importRecipe()"))
+			result.Errors = append(result.Errors, errutil.GetAppErrorUserMessage(err))
+			return result
+		}
+		for _, duplicate := range duplicates {
+			result.Errors = append(result.Errors, fmt.Sprintf(
+				"Mögliches Duplikat von '%s': %s",
+				duplicate.Title,
+				strings.Join(duplicate.Reasons, ", "),
+			))
+		}
+		if len(result.Errors) > 0 {
+			return result
+		}
+	}
+
+	if err := rs.createRecipe(&recipe); err != nil {
+		rs.logger.Error(errutil.AddMessageToAppError(err, "failed at importRecipe()"))
+		result.Errors = append(result.Errors, errutil.GetAppErrorUserMessage(err))
+		return result
+	}
+
+	if !deletedAt.IsZero() {
+		if err := rs.trashRecipeAt(recipe.ID, deletedAt); err != nil {
+			rs.logger.Error(errutil.AddMessageToAppError(err, "failed at importRecipe()"))
+			result.Errors = append(result.Errors, errutil.GetAppErrorUserMessage(err))
+			return result
+		}
+		result.Trashed = true
+	}
+
+	result.ID = recipe.ID
+	return result
+}
+
+// getRecipeFromHtml reads a recipe from the schema.org data of a saved web
+// page, either uploaded as the file "file" or pasted into the field "html".
+// The recipe is only used to fill the form and is not created.
+func (rs *recipeService) getRecipeFromHtml(c echo.Context) (types.Recipe, error) {
+	createError := func(message string, statusCode int, err error) error {
+		return &errutil.AppError{
+			UserMessage: message,
+			Err:         fmt.Errorf("failed at getRecipeFromHtml(): %w", err),
+			StatusCode:  statusCode,
+		}
+	}
+
+	var data []byte
+	if header, err := c.FormFile("file"); err == nil {
+		if header.Size > maxImportFileSize {
+			return types.Recipe{}, createError(
+				fmt.Sprintf("Die Datei ist größer als %d MB", maxImportFileSize>>20),
+				http.StatusRequestEntityTooLarge,
+				fmt.Errorf("file exceeds %d bytes", maxImportFileSize),
+			)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return types.Recipe{}, createError("Fehler beim Lesen der Datei", http.StatusBadRequest, err)
+		}
+		defer file.Close()
+		data, err = io.ReadAll(file)
+		if err != nil {
+			return types.Recipe{}, createError("Fehler beim Lesen der Datei", http.StatusBadRequest, err)
+		}
+	} else {
+		data = []byte(c.FormValue("html"))
+		if len(data) > maxImportFileSize {
+			return types.Recipe{}, createError(
+				"Anfrage zu groß",
+				http.StatusRequestEntityTooLarge,
+				fmt.Errorf("html exceeds %d bytes", maxImportFileSize),
+			)
+		}
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return types.Recipe{}, createError("Keine Webseite ausgewählt", http.StatusBadRequest, fmt.Errorf("no html"))
+	}
+
+	recipe, err := schemaorg.ParseRecipe(bytes.NewReader(data))
+	if err != nil {
+		return types.Recipe{}, createError("Kein Rezept auf der Webseite gefunden", http.StatusBadRequest, err)
+	}
+	return recipe, nil
+}
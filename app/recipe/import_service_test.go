@@ -0,0 +1,170 @@
+package recipe
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMultipartContext(t *testing.T, files map[string]string) echo.Context {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		assert.NoError(t, err)
+		_, err = part.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "/recipe/import", &body)
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestGetImportFiles(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	// When
+	files, err := recipeService.getImportFiles(newTestMultipartContext(t, map[string]string{
+		"recipe.json": `{"title": "title"}`,
+	}))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []importFile{{name: "recipe.json", data: []byte(`{"title": "title"}`)}}, files)
+
+	// When
+	_, err = recipeService.getImportFiles(newTestMultipartContext(t, map[string]string{}))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestImportRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	valid := `{"id": 7, "title": "Brot", "description": "description", "duration": 10, "totalDuration": 60,
+		"ingredients": "- 500 g Mehl", "instructions": "Backen", "createdAt": "2024-01-01T10:00:00Z"}`
+
+	// When
+	results := recipeService.importRecipes([]importFile{
+		{name: "brot.json", data: []byte(valid)},
+		{name: "liste.json", data: []byte(`[` + valid + `, {"title": "Suppe", "servings": -1}]`)},
+		{name: "kaputt.json", data: []byte(`{"title": `)},
+		{name: "leer.json", data: []byte(`[]`)},
+		{name: "riesig.json", tooLarge: true},
+	}, true, true, newTestEditor())
+
+	// Then
+	assert.Equal(t, 6, len(results))
+	assert.Equal(t, types.RecipeImportResult{Source: "brot.json", Title: "Brot", ID: 1}, results[0])
+	assert.Equal(t, types.RecipeImportResult{Source: "liste.json [1]", Title: "Brot", ID: 2}, results[1])
+	assert.Equal(t, types.RecipeImportResult{
+		Source: "liste.json [2]",
+		Title:  "Suppe",
+		Errors: []string{
+			errutil.FormErrorNoDescription.Error(),
+			errutil.FormErrorInvalidServings.Error(),
+			errutil.FormErrorNoIngredients.Error(),
+			errutil.FormErrorNoInstructions.Error(),
+		},
+	}, results[2])
+	assert.Equal(t, "kaputt.json", results[3].Source)
+	assert.False(t, results[3].Success())
+	assert.Equal(t, "leer.json", results[4].Source)
+	assert.False(t, results[4].Success())
+	assert.Equal(t, "riesig.json", results[5].Source)
+	assert.False(t, results[5].Success())
+
+	recipe, err := recipeService.readRecipe(1)
+	assert.NoError(t, err)
+	assert.True(t, recipe.Pending)
+	assert.Equal(t, "2024-01-01T10:00:00Z", recipe.CreatedAt)
+	assert.Equal(t, uint(1), recipe.CreatedByID)
+	assert.Equal(t, "admin", recipe.CreatedByName)
+	assert.Equal(t, []types.Ingredient{{Quantity: 500, Unit: "g", Name: "Mehl"}}, recipe.ParsedIngredients)
+}
+
+func TestImportRecipesSkipsDuplicates(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Brot"}))
+	brot := `{"title": "Brot", "description": "description", "duration": 10, "totalDuration": 60,
+		"ingredients": "- 500 g Mehl", "instructions": "Backen"}`
+
+	// When
+	results := recipeService.importRecipes([]importFile{{name: "brot.json", data: []byte(brot)}}, false, false, newTestEditor())
+
+	// Then
+	assert.Equal(t, []types.RecipeImportResult{{
+		Source: "brot.json",
+		Title:  "Brot",
+		Errors: []string{"Mögliches Duplikat von 'Brot': Ähnlicher Titel (100 %)"},
+	}}, results)
+
+	// When
+	results = recipeService.importRecipes([]importFile{{name: "brot.json", data: []byte(brot)}}, false, true, newTestEditor())
+
+	// Then
+	assert.Equal(t, []types.RecipeImportResult{{Source: "brot.json", Title: "Brot", ID: 2}}, results)
+}
+
+func TestImportRecipesRestoresTrash(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := `"title": "Brot", "description": "description", "duration": 10, "totalDuration": 60,
+		"ingredients": "- 500 g Mehl", "instructions": "Backen"`
+
+	// When
+	results := recipeService.importRecipes([]importFile{
+		{name: "brot.json", data: []byte(`{` + recipe + `, "deletedAt": "2024-01-01T10:00:00Z"}`)},
+		{name: "kaputt.json", data: []byte(`{` + recipe + `, "deletedAt": "gestern"}`)},
+	}, false, true, newTestEditor())
+
+	// Then
+	assert.Equal(t, []types.RecipeImportResult{
+		{Source: "brot.json", Title: "Brot", ID: 1, Trashed: true},
+		{Source: "kaputt.json", Title: "Brot", Errors: []string{"Ungültiges Löschdatum: gestern"}},
+	}, results)
+
+	_, err := recipeService.readRecipe(1)
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	trashed, err := recipeService.db.readTrashedRecipe(1)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC).Equal(trashed.DeletedAt.Time))
+}
+
+func TestImportCooklangRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	valid := "---\ntitle: Brot\ndescription: Einfach\n---\n\n@Mehl{500%g} mit @Wasser{300%ml} kneten.\n"
+
+	// When
+	results := recipeService.importRecipes([]importFile{
+		{name: "brot.cook", data: []byte(valid)},
+		{name: "leer.COOK", data: []byte("-- nichts\n")},
+	}, false, false, newTestEditor())
+
+	// Then
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, types.RecipeImportResult{Source: "brot.cook", Title: "Brot", ID: 1}, results[0])
+	assert.Equal(t, "leer.COOK", results[1].Source)
+	assert.False(t, results[1].Success())
+
+	recipe, err := recipeService.readRecipe(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "- 500 g Mehl\n- 300 ml Wasser", recipe.Ingredients)
+	assert.Equal(t, "1. Mehl mit Wasser kneten.", recipe.Instructions)
+}
@@ -0,0 +1,91 @@
+package recipe
+
+import (
+	"fmt"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderModerationPage(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderModerationPage()"),
+		)
+	}
+	return rc.renderModerationPage(c, "")
+}
+
+func (rc *RecipeController) renderModerationPage(c echo.Context, message string) error {
+	pending, err := rc.recipeService.readPendingRecipes()
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderModerationPage()"),
+		)
+	}
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.ModerationPage(true, pending),
+		Message:   message,
+	})
+}
+
+func (rc *RecipeController) HandleAcceptPendingRecipes(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleAcceptPendingRecipes()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleAcceptPendingRecipes()"))
+	}
+
+	ids, err := rc.recipeService.getModerationIds(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.acceptPendingRecipes(ids); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("accepted pending recipes %v", ids)
+	return rc.renderModerationPage(c, fmt.Sprintf("%d Rezept(e) angenommen", len(ids)))
+}
+
+func (rc *RecipeController) HandleRejectPendingRecipes(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleRejectPendingRecipes()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleRejectPendingRecipes()"))
+	}
+
+	ids, err := rc.recipeService.getModerationIds(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	reason, err := rc.recipeService.getRejectionReason(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.rejectPendingRecipes(ids, reason); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("rejected pending recipes %v", ids)
+	return rc.renderModerationPage(c, fmt.Sprintf("%d Rezept(e) abgelehnt", len(ids)))
+}
@@ -0,0 +1,182 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderModerationPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+	createTestPendingRecipes(t, recipeController.recipeService)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderModerationPage,
+				Method:      http.MethodGet,
+				Route:       "/admin/moderation",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.RenderModerationPage,
+				Method:        http.MethodGet,
+				Route:         "/admin/moderation",
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "von Anna",
+			},
+		)
+	})
+}
+
+func TestHandleAcceptPendingRecipes(t *testing.T) {
+	recipeController := newTestRecipeController()
+	createTestPendingRecipes(t, recipeController.recipeService)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleAcceptPendingRecipes,
+				Method:       http.MethodPost,
+				Route:        "/admin/moderation/accept",
+				StatusWant:   http.StatusUnauthorized,
+				WithFormData: true,
+				FormData:     "ids=1&ids=3",
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleAcceptPendingRecipes,
+				Method:       http.MethodPost,
+				Route:        "/admin/moderation/accept",
+				StatusWant:   http.StatusForbidden,
+				Role:         types.RoleEditor,
+				WithFormData: true,
+				FormData:     "ids=1&ids=3",
+			},
+		)
+	})
+
+	t.Run("nothing selected", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleAcceptPendingRecipes,
+				Method:        http.MethodPost,
+				Route:         "/admin/moderation/accept",
+				StatusWant:    http.StatusBadRequest,
+				Authorized:    true,
+				WithFormData:  true,
+				FormData:      "reason=",
+				AssertMessage: true,
+				MessageWant:   "Keine Rezepte ausgewählt",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleAcceptPendingRecipes,
+				Method:        http.MethodPost,
+				Route:         "/admin/moderation/accept",
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				WithFormData:  true,
+				FormData:      "ids=1&ids=3",
+				AssertMessage: true,
+				MessageWant:   "2 Rezept(e) angenommen",
+			},
+		)
+		recipe, err := recipeController.recipeService.readRecipe(3)
+		assert.NoError(t, err)
+		assert.False(t, recipe.Pending)
+	})
+}
+
+func TestHandleRejectPendingRecipes(t *testing.T) {
+	recipeController := newTestRecipeController()
+	createTestPendingRecipes(t, recipeController.recipeService)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleRejectPendingRecipes,
+				Method:       http.MethodPost,
+				Route:        "/admin/moderation/reject",
+				StatusWant:   http.StatusUnauthorized,
+				WithFormData: true,
+				FormData:     "ids=1",
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleRejectPendingRecipes,
+				Method:       http.MethodPost,
+				Route:        "/admin/moderation/reject",
+				StatusWant:   http.StatusForbidden,
+				Role:         types.RoleEditor,
+				WithFormData: true,
+				FormData:     "ids=1",
+			},
+		)
+	})
+
+	t.Run("recipe not pending", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleRejectPendingRecipes,
+				Method:       http.MethodPost,
+				Route:        "/admin/moderation/reject",
+				StatusWant:   http.StatusBadRequest,
+				Authorized:   true,
+				WithFormData: true,
+				FormData:     "ids=2",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleRejectPendingRecipes,
+				Method:        http.MethodPost,
+				Route:         "/admin/moderation/reject",
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				WithFormData:  true,
+				FormData:      "ids=1&reason=Unvollst%C3%A4ndig",
+				AssertMessage: true,
+				MessageWant:   "1 Rezept(e) abgelehnt",
+			},
+		)
+		trash, err := recipeController.recipeService.readTrash()
+		assert.NoError(t, err)
+		assert.Equal(t, "Unvollständig", trash[0].RejectionReason)
+	})
+}
@@ -0,0 +1,178 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	maxSubmitterLen       = 100
+	maxRejectionReasonLen = 500
+)
+
+// readPendingRecipes returns the submitted recipes waiting for moderation,
+// the oldest submission first.
+func (rs *recipeService) readPendingRecipes() ([]types.PendingRecipe, error) {
+	pending := []types.PendingRecipe{}
+
+	recipes, err := rs.readAllRecipes(true)
+	if err != nil {
+		return pending, errutil.AddMessageToAppError(err, "failed at readPendingRecipes()")
+	}
+
+	for _, recipe := range recipes {
+		if !recipe.Pending {
+			continue
+		}
+		submittedAt := recipe.CreatedAt
+		if createdAt, err := time.Parse(time.RFC3339, recipe.CreatedAt); err == nil {
+			submittedAt = createdAt.Local().Format("02.01.2006 15:04")
+		}
+		submitter := recipe.Submitter
+		if len(submitter) == 0 {
+			submitter = "Anonym"
+		}
+		pending = append(pending, types.PendingRecipe{
+			ID:          recipe.ID,
+			Title:       recipe.Title,
+			Description: recipe.Description,
+			Author:      recipe.Author,
+			Source:      recipe.Source,
+			Submitter:   submitter,
+			SubmittedAt: submittedAt,
+		})
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+	return pending, nil
+}
+
+// getSubmitter returns the name of the logged in user, or the optional name
+// the visitor left with a submitted recipe, cut to a reasonable length.
+func (rs *recipeService) getSubmitter(c echo.Context) string {
+	if user, ok := servutil.GetUser(c); ok {
+		return user.Name
+	}
+	submitter := strings.TrimSpace(c.Request().FormValue("submitter"))
+	if utf8.RuneCountInString(submitter) > maxSubmitterLen {
+		submitter = string([]rune(submitter)[:maxSubmitterLen])
+	}
+	return submitter
+}
+
+// getModerationIds returns the ids of the recipes selected in the moderation
+// queue.
+func (rs *recipeService) getModerationIds(c echo.Context) ([]uint, error) {
+	if err := rs.parseForm(c); err != nil {
+		return nil, errutil.AddMessageToAppError(err, "failed at getModerationIds()")
+	}
+
+	ids := []uint{}
+	for _, value := range c.Request().Form["ids"] {
+		id, err := strconv.Atoi(value)
+		if err != nil || id < 1 {
+			return nil, &errutil.AppError{
+				UserMessage: "Ungültige Auswahl",
+				Err:         fmt.Errorf("failed at getModerationIds() with id %s", value),
+				StatusCode:  http.StatusBadRequest,
+			}
+		}
+		ids = append(ids, uint(id))
+	}
+	if len(ids) == 0 {
+		return nil, &errutil.AppError{
+			UserMessage: "Keine Rezepte ausgewählt",
+			Err:         errors.New("failed at getModerationIds(), no ids"),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return ids, nil
+}
+
+func (rs *recipeService) getRejectionReason(c echo.Context) (string, error) {
+	reason := strings.TrimSpace(c.Request().FormValue("reason"))
+	if utf8.RuneCountInString(reason) > maxRejectionReasonLen {
+		return "", &errutil.AppError{
+			UserMessage: fmt.Sprintf("Der Grund darf höchstens %d Zeichen lang sein", maxRejectionReasonLen),
+			Err:         errors.New("failed at getRejectionReason(), reason too long"),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return reason, nil
+}
+
+func (rs *recipeService) readPendingRecipe(id uint) (types.Recipe, error) {
+	recipe, err := rs.readRecipe(id)
+	if err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at readPendingRecipe()")
+	}
+	if !recipe.Pending {
+		return recipe, &errutil.AppError{
+			UserMessage: fmt.Sprintf("Rezept '%s' ist nicht ausstehend", recipe.Title),
+			Err:         fmt.Errorf("failed at readPendingRecipe(), recipe %d is not pending", id),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return recipe, nil
+}
+
+// readPendingRecipesById reads the selected submissions, failing if one of
+// them is not pending, so that a bulk action is not left half done.
+func (rs *recipeService) readPendingRecipesById(ids []uint) ([]types.Recipe, error) {
+	recipes := []types.Recipe{}
+	for _, id := range ids {
+		recipe, err := rs.readPendingRecipe(id)
+		if err != nil {
+			return recipes, errutil.AddMessageToAppError(err, "failed at readPendingRecipesById()")
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, nil
+}
+
+// acceptPendingRecipes publishes the selected submissions.
+func (rs *recipeService) acceptPendingRecipes(ids []uint) error {
+	recipes, err := rs.readPendingRecipesById(ids)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at acceptPendingRecipes()")
+	}
+	for _, recipe := range recipes {
+		if err := rs.updatePending(recipe.ID, false); err != nil {
+			return errutil.AddMessageToAppError(err, "failed at acceptPendingRecipes()")
+		}
+	}
+	return nil
+}
+
+// rejectPendingRecipes stores the reason with the selected submissions and
+// deletes them like the delete button does, by moving them to the trash.
+// There the reason stays visible until they are purged.
+func (rs *recipeService) rejectPendingRecipes(ids []uint, reason string) error {
+	recipes, err := rs.readPendingRecipesById(ids)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at rejectPendingRecipes()")
+	}
+	for _, recipe := range recipes {
+		recipe.RejectionReason = reason
+		rs.recipeCache.Invalidate()
+		if err := rs.db.updateRecipe(&recipe, nil); err != nil {
+			return errutil.AddMessageToAppError(err, "failed at rejectPendingRecipes()")
+		}
+		if err := rs.trashRecipe(recipe.ID); err != nil {
+			return errutil.AddMessageToAppError(err, "failed at rejectPendingRecipes()")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package recipe
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestPendingRecipes(t *testing.T, recipeService *recipeService) {
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe", Pending: true, Submitter: "Anna"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Tomatensuppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Kürbissuppe", Pending: true}))
+}
+
+func TestReadPendingRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	createTestPendingRecipes(t, recipeService)
+
+	// When
+	pending, err := recipeService.readPendingRecipes()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(pending))
+	assert.Equal(t, "Linsensuppe", pending[0].Title)
+	assert.Equal(t, "Anna", pending[0].Submitter)
+	assert.Equal(t, "Kürbissuppe", pending[1].Title)
+	assert.Equal(t, "Anonym", pending[1].Submitter)
+}
+
+func TestGetModerationIds(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	ids, err := recipeService.getModerationIds(newTestContext(t, newTestContextOptions{formData: "ids=1&ids=3"}))
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{1, 3}, ids)
+
+	_, err = recipeService.getModerationIds(newTestContext(t, newTestContextOptions{formData: "ids=abc"}))
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	_, err = recipeService.getModerationIds(newTestContext(t, newTestContextOptions{formData: "reason=abc"}))
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestGetRejectionReason(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	reason, err := recipeService.getRejectionReason(newTestContext(t, newTestContextOptions{formData: "reason=+Doppelt+"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "Doppelt", reason)
+
+	_, err = recipeService.getRejectionReason(newTestContext(t, newTestContextOptions{
+		formData: "reason=" + strings.Repeat("a", maxRejectionReasonLen+1),
+	}))
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestAcceptPendingRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	createTestPendingRecipes(t, recipeService)
+
+	// When
+	err := recipeService.acceptPendingRecipes([]uint{1, 2})
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+	recipe, err := recipeService.readRecipe(1)
+	assert.NoError(t, err)
+	assert.True(t, recipe.Pending)
+
+	// When
+	err = recipeService.acceptPendingRecipes([]uint{1, 3})
+
+	// Then
+	assert.NoError(t, err)
+	pending, err := recipeService.readPendingRecipes()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestRejectPendingRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	createTestPendingRecipes(t, recipeService)
+
+	// When
+	err := recipeService.rejectPendingRecipes([]uint{2}, "Doppelt")
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = recipeService.rejectPendingRecipes([]uint{1, 3}, "Doppelt")
+
+	// Then
+	assert.NoError(t, err)
+	pending, err := recipeService.readPendingRecipes()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	trash, err := recipeService.readTrash()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(trash))
+	assert.Equal(t, "Doppelt", trash[0].RejectionReason)
+
+	// When
+	assert.NoError(t, recipeService.restoreRecipe(1))
+	err = recipeService.acceptPendingRecipes([]uint{1})
+
+	// Then
+	assert.NoError(t, err)
+	recipe, err := recipeService.readRecipe(1)
+	assert.NoError(t, err)
+	assert.False(t, recipe.Pending)
+	assert.Empty(t, recipe.RejectionReason)
+}
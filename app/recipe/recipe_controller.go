@@ -35,16 +35,48 @@ func (rc *RecipeController) AttachHandlerFunctions(e *echo.Echo) {
 	e.GET("/", rc.RenderRecipeListPage)
 	e.GET("/recipe/:id/edit", rc.RenderRecipeEditPage)
 	e.GET("/recipe/new", rc.RenderRecipeNewPage)
+	e.GET("/recipe/import", rc.RenderRecipeImportPage)
 	e.GET("/recipe/:id", rc.RenderRecipePage)
+	e.GET("/recipe/:id/history", rc.RenderRecipeHistoryPage)
+	e.GET("/recipe/:id/suggest", rc.RenderRecipeSuggestPage)
+	e.GET("/author/:id", rc.RenderAuthorPage)
+	e.GET("/shopping-list", rc.RenderShoppingListPage)
+	e.GET("/admin/tags", rc.RenderTagAdminPage)
+	e.GET("/admin/trash", rc.RenderTrashPage)
+	e.GET("/admin/moderation", rc.RenderModerationPage)
+	e.GET("/admin/duplicates", rc.RenderDuplicatesPage)
 
 	// Actions
+	e.Static("/images", rc.recipeService.images.Dir())
 	e.GET("/recipe/:id/json", rc.HandleDownloadRecipeAsJson)
+	e.GET("/recipe/:id/cook", rc.HandleDownloadRecipeAsCooklang)
+	e.GET("/admin/export", rc.HandleExportRecipes)
+	e.PUT("/admin/tags/:id", rc.HandleRenameTag)
+	e.POST("/admin/tags/:id/merge", rc.HandleMergeTags)
+	e.DELETE("/admin/tags/:id", rc.HandleDeleteTag)
+	e.POST("/admin/trash/:id/restore", rc.HandleRestoreTrashedRecipe)
+	e.DELETE("/admin/trash/:id", rc.HandleDeleteTrashedRecipe)
+	e.POST("/admin/moderation/accept", rc.HandleAcceptPendingRecipes)
+	e.POST("/admin/moderation/reject", rc.HandleRejectPendingRecipes)
+	e.POST("/admin/duplicates/:id/merge/:other", rc.HandleMergeDuplicates)
+	e.GET("/recipe/tag-suggestions", rc.HandleGetTagSuggestions)
 	e.POST("/recipe", rc.HandleCreateRecipe)
+	e.POST("/recipe/import", rc.HandleImportRecipes)
+	e.POST("/recipe/new/html", rc.HandleImportRecipeFromHtml)
 	e.PUT("/recipe/:id", rc.HandleUpdateRecipe)
 	e.PUT("/recipe/:id/pending/:pending", rc.HandleUpdatePending)
+	e.POST("/recipe/:id/history/:revision/restore", rc.HandleRestoreRevision)
+	e.POST("/recipe/:id/suggestions", rc.HandleCreateSuggestion)
+	e.POST("/recipe/:id/suggestions/:suggestion/accept", rc.HandleAcceptSuggestion)
+	e.DELETE("/recipe/:id/suggestions/:suggestion", rc.HandleRejectSuggestion)
 	e.DELETE("/recipe/:id", rc.HandleDeleteRecipe)
 	e.GET("/recipe/link", rc.HandleGetRecipeLinks)
 	e.POST("/recipe/preview", rc.HandlePostRecipePreview)
+	e.POST("/recipe/instructions-image", rc.HandlePostInstructionsImage)
+	e.GET("/shopping-list/txt", rc.HandleDownloadShoppingListAsText)
+	e.POST("/shopping-list/:id", rc.HandleAddToShoppingList)
+	e.DELETE("/shopping-list/:id", rc.HandleRemoveFromShoppingList)
+	e.DELETE("/shopping-list", rc.HandleClearShoppingList)
 }
 
 func (rc *RecipeController) RenderRecipeListPage(c echo.Context) error {
@@ -55,9 +87,8 @@ func (rc *RecipeController) RenderRecipeListPage(c echo.Context) error {
 }
 
 func (rc *RecipeController) renderRecipeListPageHelper(c echo.Context, message string) error {
-	recipes, paginationInfo, err := rc.recipeService.readRecipes(
-		rc.recipeService.getReadRecipeOptionsFromRequest(c),
-	)
+	options := rc.recipeService.getReadRecipeOptionsFromRequest(c)
+	recipes, paginationInfo, tagFilter, err := rc.recipeService.readRecipes(options)
 	if err != nil {
 		return rc.renderer.RenderError(
 			c,
@@ -70,25 +101,26 @@ func (rc *RecipeController) renderRecipeListPageHelper(c echo.Context, message s
 			servutil.IsAuthorized(c),
 			recipes,
 			paginationInfo,
+			tagFilter,
+			options.sort,
 		),
 		Message: message,
 	})
 }
 
 func (rc *RecipeController) RenderRecipeNewPage(c echo.Context) error {
-	formElements := rc.recipeService.createRecipeForm(types.Recipe{}, make(map[string]error))
+	formElements := rc.recipeService.createRecipeForm(c, types.Recipe{}, make(map[string]error))
 	return rc.renderer.RenderComponent(render.RenderComponentOptions{
 		Context:   c,
-		Component: components.RecipeNewPage(formElements, servutil.IsAuthorized(c)),
+		Component: components.RecipeNewPage(formElements, servutil.GetRole(c), false, nil, ""),
 	})
 }
 
 func (rc *RecipeController) RenderRecipeEditPage(c echo.Context) error {
-	isAdmin := servutil.IsAuthorized(c)
-	if !isAdmin {
+	if !servutil.CanEdit(c) {
 		return rc.renderer.RenderError(
 			c,
-			errutil.NewAppErrorNotAuthorized("RenderRecipeEditPage()"),
+			servutil.NewAppErrorNotAllowed(c, "RenderRecipeEditPage()"),
 		)
 	}
 
@@ -100,10 +132,17 @@ func (rc *RecipeController) RenderRecipeEditPage(c echo.Context) error {
 		)
 	}
 
-	formElements := rc.recipeService.createRecipeForm(recipe, make(map[string]error))
+	if !servutil.CanModify(c, recipe) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderRecipeEditPage()"),
+		)
+	}
+
+	formElements := rc.recipeService.createRecipeForm(c, recipe, make(map[string]error))
 	return rc.renderer.RenderComponent(render.RenderComponentOptions{
 		Context:   c,
-		Component: components.RecipeEditPage(isAdmin, recipe.ID, formElements),
+		Component: components.RecipeEditPage(servutil.IsAuthorized(c), recipe.ID, formElements),
 	})
 }
 
@@ -118,19 +157,38 @@ func (rc *RecipeController) RenderRecipePage(c echo.Context) error {
 	if err != nil {
 		return createError(err)
 	}
+	servings, err := rc.recipeService.getQueryServings(c)
+	if err != nil {
+		return createError(err)
+	}
+	units, err := rc.recipeService.getQueryUnitSystem(c)
+	if err != nil {
+		return createError(err)
+	}
+	var suggestions []types.RecipeSuggestionEntry
+	if servutil.CanModify(c, recipe) {
+		suggestions, err = rc.recipeService.getSuggestions(recipe)
+		if err != nil {
+			return createError(err)
+		}
+	}
+	meta := rc.recipeService.getRecipePageMeta(c.Scheme()+"://"+c.Request().Host, recipe)
+	rc.recipeService.scaleRecipe(&recipe, servings)
+	rc.recipeService.convertRecipeUnits(&recipe, units)
 	if err := recipe.RenderMarkdown(); err != nil {
 		return createError(err)
 	}
+	user, _ := servutil.GetUser(c)
 	return rc.renderer.RenderComponent(render.RenderComponentOptions{
 		Context:   c,
-		Component: components.RecipePage(servutil.IsAuthorized(c), recipe, recipe.ParseTags()),
+		Component: components.RecipePage(user, recipe, recipe.ParseTags(), string(units), suggestions),
+		Meta:      meta,
 	})
 }
 
 func (rc *RecipeController) HandleGetPaginatedRecipes(c echo.Context) error {
-	recipes, paginationInfo, err := rc.recipeService.readRecipes(
-		rc.recipeService.getReadRecipeOptionsFromRequest(c),
-	)
+	options := rc.recipeService.getReadRecipeOptionsFromRequest(c)
+	recipes, paginationInfo, tagFilter, err := rc.recipeService.readRecipes(options)
 	if err != nil {
 		return rc.renderer.RenderError(
 			c,
@@ -141,6 +199,8 @@ func (rc *RecipeController) HandleGetPaginatedRecipes(c echo.Context) error {
 		Context: c,
 		Component: components.Joiner(
 			components.RecipeCount(paginationInfo.TotalRecipes, true),
+			components.RecipeSortControl(options.sort, true),
+			components.TagFacets(tagFilter, true),
 			components.RecipeList(servutil.IsAuthorized(c), recipes, paginationInfo),
 			components.PageControl(paginationInfo, true),
 		),
@@ -149,15 +209,25 @@ func (rc *RecipeController) HandleGetPaginatedRecipes(c echo.Context) error {
 
 func (rc *RecipeController) HandleCreateRecipe(c echo.Context) error {
 	pending := c.QueryParam("pending") == "true"
-	isAdmin := servutil.IsAuthorized(c)
+	role := servutil.GetRole(c)
 
-	if !isAdmin && !pending {
+	if !role.CanEdit() && !pending {
 		return rc.renderer.RenderError(
 			c,
-			errutil.NewAppErrorNotAuthorized("HandleCreateRecipe()"),
+			servutil.NewAppErrorNotAllowed(c, "HandleCreateRecipe()"),
 		)
 	}
 
+	// Logged in users are known, only anonymous submissions are checked
+	if !servutil.IsLoggedIn(c) {
+		if err := rc.recipeService.checkSpam(c); err != nil {
+			return rc.renderer.RenderError(
+				c,
+				errutil.AddMessageToAppError(err, "failed at HandleCreateRecipe()"),
+			)
+		}
+	}
+
 	var recipe types.Recipe
 
 	formErrors, err := rc.recipeService.updateRecipeWithFormData(c, &recipe)
@@ -169,10 +239,10 @@ func (rc *RecipeController) HandleCreateRecipe(c echo.Context) error {
 	}
 
 	if len(formErrors) > 0 {
-		formElements := rc.recipeService.createRecipeForm(recipe, formErrors)
+		formElements := rc.recipeService.createRecipeForm(c, recipe, formErrors)
 		return rc.renderer.RenderComponent(render.RenderComponentOptions{
 			Context:   c,
-			Component: components.RecipeNewPage(formElements, servutil.IsAuthorized(c)),
+			Component: components.RecipeNewPage(formElements, role, false, nil, rc.recipeService.getFormStartedAt(c)),
 			Err: &errutil.AppError{
 				UserMessage: "Fehlerhaftes Formular",
 				StatusCode:  http.StatusBadRequest,
@@ -181,15 +251,54 @@ func (rc *RecipeController) HandleCreateRecipe(c echo.Context) error {
 		})
 	}
 
+	// Possible duplicates are shown once, submitting the form again saves
+	// the recipe anyway
+	if c.Request().FormValue("ignoreDuplicates") != "true" {
+		duplicates, err := rc.recipeService.findDuplicates(recipe, servutil.IsAuthorized(c))
+		if err != nil {
+			rc.recipeService.deleteImage(recipe.Image)
+			return rc.renderer.RenderError(
+				c,
+				errutil.AddMessageToAppError(err, "failed at HandleCreateRecipe()"),
+			)
+		}
+		if len(duplicates) > 0 {
+			// The image has to be selected again, so it must not be kept
+			rc.recipeService.deleteImage(recipe.Image)
+			recipe.Image = ""
+			formElements := rc.recipeService.createRecipeForm(c, recipe, make(map[string]error))
+			return rc.renderer.RenderComponent(render.RenderComponentOptions{
+				Context:   c,
+				Component: components.RecipeNewPage(formElements, role, false, duplicates, rc.recipeService.getFormStartedAt(c)),
+				Err: &errutil.AppError{
+					UserMessage: "Mögliches Duplikat gefunden",
+					StatusCode:  http.StatusConflict,
+					Err:         fmt.Errorf("failed at HandleCreateRecipe(), possible duplicates: %v", duplicates),
+				},
+			})
+		}
+	}
+
 	recipe.Pending = pending
 	recipe.CreatedAt = time.Now().Format(time.RFC3339)
+	if user, ok := servutil.GetUser(c); ok {
+		recipe.CreatedByID = user.ID
+		recipe.CreatedByName = user.Name
+	}
+	if pending && !role.CanEdit() {
+		recipe.Submitter = rc.recipeService.getSubmitter(c)
+	}
 
 	if err := rc.recipeService.createRecipe(&recipe); err != nil {
+		rc.recipeService.deleteImage(recipe.Image)
 		return rc.renderer.RenderError(
 			c,
 			errutil.AddMessageToAppError(err, "failed at HandleCreateRecipe()"),
 		)
 	}
+	if !servutil.IsLoggedIn(c) {
+		rc.recipeService.recordSubmission(c)
+	}
 
 	if pending {
 		rc.logger.Info("created pending recipe", recipe.ID)
@@ -204,7 +313,7 @@ func (rc *RecipeController) HandleUpdatePending(c echo.Context) error {
 	if !isAdmin {
 		return rc.renderer.RenderError(
 			c,
-			errutil.NewAppErrorNotAuthorized("HandleUpdatePending()"),
+			servutil.NewAppErrorNotAllowed(c, "HandleUpdatePending()"),
 		)
 	}
 
@@ -240,10 +349,9 @@ func (rc *RecipeController) HandleUpdatePending(c echo.Context) error {
 }
 
 func (rc *RecipeController) HandleUpdateRecipe(c echo.Context) error {
-	isAdmin := servutil.IsAuthorized(c)
-	if !isAdmin {
+	if !servutil.CanEdit(c) {
 		return rc.renderer.RenderError(
-			c, errutil.NewAppErrorNotAuthorized("HandleCreateRecipe()"),
+			c, servutil.NewAppErrorNotAllowed(c, "HandleCreateRecipe()"),
 		)
 	}
 
@@ -263,7 +371,10 @@ func (rc *RecipeController) HandleUpdateRecipe(c echo.Context) error {
 	if err != nil {
 		return createError(err)
 	}
-	rc.logger.Info("old recipe:", recipe.String())
+	if !servutil.CanModify(c, recipe) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleUpdateRecipe()"))
+	}
+	oldImage := recipe.Image
 
 	formErrors, err := rc.recipeService.updateRecipeWithFormData(c, &recipe)
 	if err != nil {
@@ -271,10 +382,10 @@ func (rc *RecipeController) HandleUpdateRecipe(c echo.Context) error {
 	}
 
 	if len(formErrors) > 0 {
-		formElements := rc.recipeService.createRecipeForm(recipe, formErrors)
+		formElements := rc.recipeService.createRecipeForm(c, recipe, formErrors)
 		return rc.renderer.RenderComponent(render.RenderComponentOptions{
 			Context:   c,
-			Component: components.RecipeEditPage(isAdmin, recipe.ID, formElements),
+			Component: components.RecipeEditPage(servutil.IsAuthorized(c), recipe.ID, formElements),
 			Err: &errutil.AppError{
 				UserMessage: "Fehlerhaftes Formular",
 				StatusCode:  http.StatusBadRequest,
@@ -283,23 +394,14 @@ func (rc *RecipeController) HandleUpdateRecipe(c echo.Context) error {
 		})
 	}
 
-	recipe.LastModifiedAt = time.Now().Format(time.RFC3339)
-	if err := rc.recipeService.updateRecipe(&recipe); err != nil {
+	if err := rc.recipeService.updateRecipe(&recipe, rc.recipeService.getEditor(c)); err != nil {
+		if recipe.Image != oldImage {
+			rc.recipeService.deleteImage(recipe.Image)
+		}
 		return createError(err)
 	}
 
-	if err := recipe.RenderMarkdown(); err != nil {
-		return rc.renderer.RenderError(
-			c,
-			errutil.AddMessageToAppError(err, "failed at RenderRecipePageHelper()"),
-		)
-	}
-
-	return rc.renderer.RenderComponent(render.RenderComponentOptions{
-		Context:   c,
-		Component: components.RecipePage(servutil.IsAuthorized(c), recipe, recipe.ParseTags()),
-		Message:   "Rezept aktualisiert",
-	})
+	return rc.renderUpdatedRecipePage(c, recipe, "Rezept aktualisiert")
 }
 
 func (rc *RecipeController) HandleDeleteRecipe(c echo.Context) error {
@@ -307,7 +409,7 @@ func (rc *RecipeController) HandleDeleteRecipe(c echo.Context) error {
 	if !isAdmin {
 		return rc.renderer.RenderError(
 			c,
-			errutil.NewAppErrorNotAuthorized("HandleDeleteRecipe()"),
+			servutil.NewAppErrorNotAllowed(c, "HandleDeleteRecipe()"),
 		)
 	}
 
@@ -323,23 +425,22 @@ func (rc *RecipeController) HandleDeleteRecipe(c echo.Context) error {
 		return createError(err)
 	}
 
-	err = rc.recipeService.deleteRecipe(id)
+	err = rc.recipeService.trashRecipe(id)
 	if err != nil {
 		return createError(err)
 	}
 
-	recipes, paginationInfo, err := rc.recipeService.readRecipes(
-		rc.recipeService.getReadRecipeOptionsFromRequest(c),
-	)
+	options := rc.recipeService.getReadRecipeOptionsFromRequest(c)
+	recipes, paginationInfo, tagFilter, err := rc.recipeService.readRecipes(options)
 	if err != nil {
 		return createError(err)
 	}
 
-	rc.logger.Info("deleted recipe", id)
+	rc.logger.Info("moved recipe to the trash", id)
 	return rc.renderer.RenderComponent(render.RenderComponentOptions{
 		Context:   c,
-		Component: components.RecipesPage(isAdmin, recipes, paginationInfo),
-		Message:   "Rezept entfernt",
+		Component: components.RecipesPage(isAdmin, recipes, paginationInfo, tagFilter, options.sort),
+		Message:   "Rezept in den Papierkorb verschoben",
 	})
 }
 
@@ -370,6 +471,32 @@ func (rc *RecipeController) HandleDownloadRecipeAsJson(c echo.Context) error {
 	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, jsonRecipe)
 }
 
+func (rc *RecipeController) HandleDownloadRecipeAsCooklang(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(
+				err,
+				"failed at HandleDownloadRecipeAsCooklang()",
+			),
+		)
+	}
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+	cooklangRecipe, err := rc.recipeService.getRecipeAsCooklang(id)
+	if err != nil {
+		return createError(err)
+	}
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=recipe_%d.cook", id),
+	)
+	c.Response().Header().Set(echo.HeaderContentLength, strconv.Itoa(len(cooklangRecipe)))
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, cooklangRecipe)
+}
+
 func (rc *RecipeController) HandleGetRecipeLinks(c echo.Context) error {
 	recipes, err := rc.recipeService.getRecipeLinks(
 		servutil.IsAuthorized(c),
@@ -426,3 +553,50 @@ func (rc *RecipeController) HandlePostRecipePreview(c echo.Context) error {
 		OnlyComponent: true,
 	})
 }
+
+// HandlePostInstructionsImage stores an image for the instructions and
+// responds with the Markdown snippet that is inserted into the textarea.
+func (rc *RecipeController) HandlePostInstructionsImage(c echo.Context) error {
+	if !servutil.IsLoggedIn(c) {
+		return rc.renderer.RenderError(
+			c,
+			errutil.NewAppErrorNotAuthorized("HandlePostInstructionsImage()"),
+		)
+	}
+
+	snippet, err := rc.recipeService.saveInstructionsImage(c)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandlePostInstructionsImage()"),
+		)
+	}
+	return c.String(http.StatusOK, snippet)
+}
+
+func (rc *RecipeController) HandleExportRecipes(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "HandleExportRecipes()"),
+		)
+	}
+
+	now := time.Now()
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=lethimcook_%s.zip", now.Format("20060102_150405")),
+	)
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().WriteHeader(http.StatusOK)
+
+	// The status is already sent at this point, so a failure can only be
+	// logged and leaves the client with a broken archive
+	if err := rc.recipeService.exportRecipes(c.Response(), now); err != nil {
+		rc.logger.Error(errutil.AddMessageToAppError(err, "failed at HandleExportRecipes()"))
+		return nil
+	}
+
+	rc.logger.Info("exported all recipes")
+	return nil
+}
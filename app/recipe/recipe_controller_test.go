@@ -1,11 +1,15 @@
 package recipe
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/kilianmandscharo/lethimcook/logging"
 	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/spam"
 	"github.com/kilianmandscharo/lethimcook/testutil"
 	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +36,47 @@ func TestRenderRecipeListPage(t *testing.T) {
 			},
 		)
 	})
+	t.Run("valid request with tags", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeListPage,
+				Method:         http.MethodGet,
+				Route:          "/",
+				WithQueryParam: true,
+				QueryParam:     "?tag=Vegan&tag=Suppe&tagMode=or",
+				StatusWant:     http.StatusOK,
+			},
+		)
+	})
+	t.Run("valid request with random sort", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeListPage,
+				Method:         http.MethodGet,
+				Route:          "/",
+				WithQueryParam: true,
+				QueryParam:     "?sort=random&seed=42&page=2",
+				StatusWant:     http.StatusOK,
+			},
+		)
+		assert.Contains(t, rr.Body.String(), `name="seed" value="42"`)
+	})
+	t.Run("invalid search query", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeListPage,
+				Method:         http.MethodGet,
+				Route:          "/",
+				WithQueryParam: true,
+				QueryParam:     "?search=farbe:rot",
+				StatusWant:     http.StatusBadRequest,
+			},
+		)
+		assert.Contains(t, rr.Body.String(), "Ungültige Suche: Unbekanntes Feld")
+	})
 }
 
 func TestRenderRecipeNewPage(t *testing.T) {
@@ -154,11 +199,11 @@ func TestRenderRecipePage(t *testing.T) {
 		)
 	})
 
-	err := recipeController.recipeService.createRecipe(&types.Recipe{})
+	err := recipeController.recipeService.createRecipe(&types.Recipe{Title: "Naan"})
 	assert.NoError(t, err)
 
 	t.Run("valid request", func(t *testing.T) {
-		testutil.AssertRequest(
+		rr, _ := testutil.AssertRequest(
 			t,
 			testutil.RequestOptions{
 				HandlerFunc:    recipeController.RenderRecipePage,
@@ -170,6 +215,89 @@ func TestRenderRecipePage(t *testing.T) {
 				StatusWant:     http.StatusOK,
 			},
 		)
+		assert.Contains(t, rr.Body.String(), "<title>Naan | Let Him Cook</title>")
+	})
+
+	err = recipeController.recipeService.createRecipe(&types.Recipe{
+		Servings:    2,
+		Ingredients: "- 200 g Mehl",
+	})
+	assert.NoError(t, err)
+
+	t.Run("scaled to servings", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipePage,
+				Method:         http.MethodGet,
+				Route:          "/recipe",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithQueryParam: true,
+				QueryParam:     "?servings=3",
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "300 g Mehl",
+			},
+		)
+	})
+
+	t.Run("invalid servings", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipePage,
+				Method:         http.MethodGet,
+				Route:          "/recipe",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithQueryParam: true,
+				QueryParam:     "?servings=0",
+				StatusWant:     http.StatusBadRequest,
+				AssertMessage:  true,
+				MessageWant:    "Ungültige Portionsanzahl",
+			},
+		)
+	})
+
+	t.Run("converted to imperial units", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipePage,
+				Method:         http.MethodGet,
+				Route:          "/recipe",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithQueryParam: true,
+				QueryParam:     "?servings=4&units=imperial",
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "14 oz Mehl",
+			},
+		)
+	})
+
+	t.Run("invalid unit system", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipePage,
+				Method:         http.MethodGet,
+				Route:          "/recipe",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithQueryParam: true,
+				QueryParam:     "?units=kelvin",
+				StatusWant:     http.StatusBadRequest,
+				AssertMessage:  true,
+				MessageWant:    "Ungültiges Einheitensystem",
+			},
+		)
 	})
 }
 
@@ -246,7 +374,41 @@ func TestHandleCreateRecipe(t *testing.T) {
 				Authorized:    true,
 				StatusWant:    http.StatusOK,
 				WithFormData:  true,
-				FormData:      "title=title&description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30",
+				FormData:      "title=Linsensuppe&description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30",
+				AssertMessage: true,
+				MessageWant:   "Rezept erstellt",
+			},
+		)
+	})
+
+	t.Run("possible duplicate", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleCreateRecipe,
+				Method:        http.MethodPost,
+				Route:         "/recipe",
+				Authorized:    true,
+				StatusWant:    http.StatusConflict,
+				WithFormData:  true,
+				FormData:      "title=Linsensupe&description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30",
+				AssertMessage: true,
+				MessageWant:   "Mögliches Duplikat gefunden",
+			},
+		)
+	})
+
+	t.Run("ignore duplicates", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleCreateRecipe,
+				Method:        http.MethodPost,
+				Route:         "/recipe",
+				Authorized:    true,
+				StatusWant:    http.StatusOK,
+				WithFormData:  true,
+				FormData:      "title=Linsensupe&description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30&ignoreDuplicates=true",
 				AssertMessage: true,
 				MessageWant:   "Rezept erstellt",
 			},
@@ -254,6 +416,109 @@ func TestHandleCreateRecipe(t *testing.T) {
 	})
 }
 
+func TestCreateRecipeHidesPendingDuplicates(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Geheime Linsensuppe", Pending: true}))
+	formData := "title=Geheime Linsensupe&description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30"
+
+	t.Run("visitor", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateRecipe,
+				Method:         http.MethodPost,
+				Route:          "/recipe",
+				StatusWant:     http.StatusOK,
+				WithQueryParam: true,
+				QueryParam:     "?pending=true",
+				WithFormData:   true,
+				FormData:       formData,
+				AssertMessage:  true,
+				MessageWant:    "Rezept eingereicht",
+			},
+		)
+		assert.NotContains(t, rr.Body.String(), "Geheime Linsensuppe")
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleCreateRecipe,
+				Method:        http.MethodPost,
+				Route:         "/recipe",
+				Authorized:    true,
+				StatusWant:    http.StatusConflict,
+				WithFormData:  true,
+				FormData:      formData,
+				AssertMessage: true,
+				MessageWant:   "Geheime Linsensuppe",
+			},
+		)
+	})
+}
+
+func TestCreateRecipeSpamRateLimit(t *testing.T) {
+	recipeController := newTestRecipeController()
+	config := spam.DefaultConfig()
+	config.RateLimit = 1
+	recipeController.recipeService.spamFilter = spam.NewFilter(config, recipeController.logger)
+	startedAt := fmt.Sprint(time.Now().Add(-time.Minute).Unix())
+	formData := "description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30&formStartedAt=" + startedAt
+
+	t.Run("invalid form keeps start time and doesn't count", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			testutil.AssertRequest(
+				t,
+				testutil.RequestOptions{
+					HandlerFunc:    recipeController.HandleCreateRecipe,
+					Method:         http.MethodPost,
+					Route:          "/recipe",
+					StatusWant:     http.StatusBadRequest,
+					WithQueryParam: true,
+					QueryParam:     "?pending=true",
+					WithFormData:   true,
+					FormData:       formData,
+					AssertMessage:  true,
+					MessageWant:    fmt.Sprintf(`name="formStartedAt" value="%s"`, startedAt),
+				},
+			)
+		}
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateRecipe,
+				Method:         http.MethodPost,
+				Route:          "/recipe",
+				StatusWant:     http.StatusOK,
+				WithQueryParam: true,
+				QueryParam:     "?pending=true",
+				WithFormData:   true,
+				FormData:       "title=Linsensuppe&" + formData,
+			},
+		)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateRecipe,
+				Method:         http.MethodPost,
+				Route:          "/recipe",
+				StatusWant:     http.StatusTooManyRequests,
+				WithQueryParam: true,
+				QueryParam:     "?pending=true",
+				WithFormData:   true,
+				FormData:       "title=Brot&" + formData,
+			},
+		)
+	})
+}
+
 func TestHandleUpdatePending(t *testing.T) {
 	recipeController := newTestRecipeController()
 
@@ -539,7 +804,172 @@ func TestHandleDeleteRecipe(t *testing.T) {
 				StatusWant:     http.StatusOK,
 				Authorized:     true,
 				AssertMessage:  true,
-				MessageWant:    "Rezept entfernt",
+				MessageWant:    "Rezept in den Papierkorb verschoben",
+			},
+		)
+	})
+}
+
+func TestRecipeRoles(t *testing.T) {
+	recipeController := newTestRecipeController()
+	formData := "description=description&ingredients=ingredients&instructions=instructions&cookingDuration=30&totalDuration=30"
+
+	t.Run("editor creates recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandleCreateRecipe,
+				Method:        http.MethodPost,
+				Route:         "/recipe",
+				Role:          types.RoleEditor,
+				StatusWant:    http.StatusOK,
+				WithFormData:  true,
+				FormData:      "title=Linsensuppe&" + formData,
+				AssertMessage: true,
+				MessageWant:   "Rezept erstellt",
+			},
+		)
+		recipe, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.False(t, recipe.Pending)
+		assert.Equal(t, uint(1), recipe.CreatedByID)
+		assert.Equal(t, "editor", recipe.CreatedByName)
+	})
+
+	t.Run("contributor can't create recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:  recipeController.HandleCreateRecipe,
+				Method:       http.MethodPost,
+				Route:        "/recipe",
+				Role:         types.RoleContributor,
+				StatusWant:   http.StatusForbidden,
+				WithFormData: true,
+				FormData:     "title=Brot&" + formData,
+			},
+		)
+	})
+
+	t.Run("contributor submits recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateRecipe,
+				Method:         http.MethodPost,
+				Route:          "/recipe",
+				Role:           types.RoleContributor,
+				StatusWant:     http.StatusOK,
+				WithQueryParam: true,
+				QueryParam:     "?pending=true",
+				WithFormData:   true,
+				FormData:       "title=Brot&submitter=Jemand&" + formData,
+				AssertMessage:  true,
+				MessageWant:    "Rezept eingereicht",
+			},
+		)
+		recipe, err := recipeController.recipeService.readRecipe(2)
+		assert.NoError(t, err)
+		assert.True(t, recipe.Pending)
+		assert.Equal(t, "contributor", recipe.Submitter)
+	})
+
+	t.Run("editor updates recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleUpdateRecipe,
+				Method:         http.MethodPut,
+				Route:          "/recipe",
+				Role:           types.RoleEditor,
+				StatusWant:     http.StatusOK,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithFormData:   true,
+				FormData:       "title=Rote Linsensuppe&" + formData,
+			},
+		)
+		history, err := recipeController.recipeService.getRevisionHistory(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "editor", history[0].Revision.Editor)
+		recipe, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), recipe.LastEditedByID)
+		assert.Equal(t, "editor", recipe.LastEditedByName)
+	})
+
+	t.Run("editor can't update recipe of others", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleUpdateRecipe,
+				Method:         http.MethodPut,
+				Route:          "/recipe",
+				Role:           types.RoleEditor,
+				UserID:         2,
+				StatusWant:     http.StatusForbidden,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithFormData:   true,
+				FormData:       "title=Gelbe Linsensuppe&" + formData,
+			},
+		)
+	})
+
+	t.Run("editor can't open the edit page of others", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeEditPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/edit",
+				Role:           types.RoleEditor,
+				UserID:         2,
+				StatusWant:     http.StatusForbidden,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+			},
+		)
+	})
+
+	t.Run("admin updates recipe of others", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleUpdateRecipe,
+				Method:         http.MethodPut,
+				Route:          "/recipe",
+				Role:           types.RoleAdmin,
+				UserID:         3,
+				StatusWant:     http.StatusOK,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithFormData:   true,
+				FormData:       "title=Gelbe Linsensuppe&" + formData,
+			},
+		)
+		recipe, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), recipe.CreatedByID)
+		assert.Equal(t, "admin", recipe.LastEditedByName)
+	})
+
+	t.Run("editor can't delete recipe", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteRecipe,
+				Method:         http.MethodDelete,
+				Route:          "/recipe",
+				Role:           types.RoleEditor,
+				StatusWant:     http.StatusForbidden,
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
 			},
 		)
 	})
@@ -600,6 +1030,53 @@ func TestHandleDownloadRecipeAsJson(t *testing.T) {
 	})
 }
 
+func TestHandleDownloadRecipeAsCooklang(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("recipe not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDownloadRecipeAsCooklang,
+				Method:         http.MethodGet,
+				Route:          "/recipe/cook",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusNotFound,
+				AssertMessage:  true,
+				MessageWant:    "Rezept nicht gefunden",
+			},
+		)
+	})
+
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{
+		Title:        "Brot",
+		Ingredients:  "- 500 g Mehl",
+		Instructions: "1. Mehl kneten.",
+	}))
+
+	t.Run("valid request", func(t *testing.T) {
+		// When
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDownloadRecipeAsCooklang,
+				Method:         http.MethodGet,
+				Route:          "/recipe/cook",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+			},
+		)
+
+		// Then
+		assert.Equal(t, "attachment; filename=recipe_1.cook", rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "---\ntitle: Brot\n---\n\n@Mehl{500%g} kneten.\n", rr.Body.String())
+	})
+}
+
 func TestHandleGetPaginatedRecipe(t *testing.T) {
 	recipeController := newTestRecipeController()
 
@@ -677,6 +1154,22 @@ func TestHandlePostRecipePreview(t *testing.T) {
 		)
 	})
 
+	t.Run("valid request with image", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.HandlePostRecipePreview,
+				Method:        http.MethodPost,
+				Route:         "/recipe/preview",
+				StatusWant:    http.StatusOK,
+				WithFormData:  true,
+				FormData:      "instructions=" + url.QueryEscape("![](/images/0123456789abcdef0123456789abcdef/large.jpg)"),
+				AssertMessage: true,
+				MessageWant:   `<img src="/images/0123456789abcdef0123456789abcdef/large.jpg" alt="" loading="lazy">`,
+			},
+		)
+	})
+
 	t.Run("empty form", func(t *testing.T) {
 		testutil.AssertRequest(
 			t,
@@ -719,3 +1212,54 @@ func TestHandlePostRecipePreview(t *testing.T) {
 		)
 	})
 }
+
+func TestHandlePostInstructionsImage(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("not logged in", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.HandlePostInstructionsImage,
+				Method:      http.MethodPost,
+				Route:       "/recipe/instructions-image",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+}
+
+func TestHandleExportRecipes(t *testing.T) {
+	recipeController := newTestRecipeController()
+	r := types.NewTestRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&r))
+
+	t.Run("not authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.HandleExportRecipes,
+				Method:      http.MethodGet,
+				Route:       "/admin/export",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.HandleExportRecipes,
+				Method:      http.MethodGet,
+				Route:       "/admin/export",
+				StatusWant:  http.StatusOK,
+				Authorized:  true,
+			},
+		)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+		files := readTestZip(t, rr.Body.Bytes())
+		assert.Contains(t, files, "manifest.json")
+		assert.Contains(t, files, "json/0001-test-title.json")
+	})
+}
@@ -15,6 +15,9 @@ import (
 type recipeDatabase struct {
 	handler *gorm.DB
 	logger  *logging.Logger
+	// fullTextSearch is false if SQLite was built without FTS5, searches then
+	// fall back to matchesSearch.
+	fullTextSearch bool
 }
 
 func NewRecipeDatabase(logger *logging.Logger) *recipeDatabase {
@@ -22,12 +25,118 @@ func NewRecipeDatabase(logger *logging.Logger) *recipeDatabase {
 	if err != nil {
 		logger.Fatal("failed to connect recipe database: ", err)
 	}
-	db.AutoMigrate(&types.Recipe{})
-	return &recipeDatabase{handler: db, logger: logger}
+	db.AutoMigrate(&types.Recipe{}, &types.RecipeRevision{}, &types.RecipeSuggestion{})
+	recipeDb := &recipeDatabase{handler: db, logger: logger}
+	if err := recipeDb.migrateTags(); err != nil {
+		logger.Fatal("failed to migrate tags: ", err)
+	}
+	recipeDb.initSearchIndex()
+	return recipeDb
+}
+
+// initSearchIndex creates the FTS5 table that holds the normalized texts of
+// all recipes and fills it from scratch, so that recipes created before the
+// index existed or with an older normalization are found as well.
+func (db *recipeDatabase) initSearchIndex() {
+	err := db.handler.Exec(
+		"CREATE VIRTUAL TABLE IF NOT EXISTS recipe_search USING fts5(" +
+			"title, description, author, tags, ingredients, instructions, " +
+			"tokenize = 'unicode61 remove_diacritics 2')",
+	).Error
+	if err != nil {
+		db.logger.Warn("full-text search unavailable, falling back to matching in memory, build with -tags sqlite_fts5: ", err)
+		db.fullTextSearch = false
+		return
+	}
+	db.fullTextSearch = true
+
+	err = db.handler.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM recipe_search").Error; err != nil {
+			return err
+		}
+		var recipes []types.Recipe
+		if err := tx.Find(&recipes).Error; err != nil {
+			return err
+		}
+		for _, recipe := range recipes {
+			if err := db.indexRecipe(tx, &recipe); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.logger.Error(fmt.Errorf("failed at initSearchIndex(): %w", err))
+	}
+}
+
+// indexRecipe writes the normalized texts of the recipe to the search index
+// within the transaction tx.
+func (db *recipeDatabase) indexRecipe(tx *gorm.DB, recipe *types.Recipe) error {
+	if !db.fullTextSearch {
+		return nil
+	}
+	if err := db.removeRecipeFromIndex(tx, recipe.ID); err != nil {
+		return err
+	}
+	values := []any{recipe.ID}
+	for _, field := range searchFields(*recipe) {
+		values = append(values, normalizeSearchText(field))
+	}
+	return tx.Exec(
+		"INSERT INTO recipe_search "+
+			"(rowid, title, description, author, tags, ingredients, instructions) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?)",
+		values...,
+	).Error
+}
+
+func (db *recipeDatabase) removeRecipeFromIndex(tx *gorm.DB, id uint) error {
+	if !db.fullTextSearch {
+		return nil
+	}
+	return tx.Exec("DELETE FROM recipe_search WHERE rowid = ?", id).Error
+}
+
+// searchRecipeIds returns the ids of the recipes matching the FTS5 query,
+// best match first. Matches in the title weigh the most, matches in the
+// instructions the least.
+func (db *recipeDatabase) searchRecipeIds(matchQuery string) ([]uint, error) {
+	var ids []uint
+	err := db.handler.Raw(
+		"SELECT rowid FROM recipe_search WHERE recipe_search MATCH ? "+
+			"ORDER BY bm25(recipe_search, 10.0, 4.0, 3.0, 5.0, 2.0, 1.0)",
+		matchQuery,
+	).Scan(&ids).Error
+	if err != nil {
+		return ids, &errutil.AppError{
+			UserMessage: "Fehler bei der Suche",
+			Err: fmt.Errorf(
+				"failed at searchRecipeIds() with query %s, database failure: %w",
+				matchQuery,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return ids, nil
 }
 
 func (db *recipeDatabase) createRecipe(recipe *types.Recipe) error {
-	if err := db.handler.Create(recipe).Error; err != nil {
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		tags, err := db.resolveTags(tx, recipe)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(recipe).Error; err != nil {
+			return err
+		}
+		if err := db.linkTags(tx, recipe.ID, tags); err != nil {
+			return err
+		}
+		return db.indexRecipe(tx, recipe)
+	})
+	if err != nil {
 		return &errutil.AppError{
 			UserMessage: "Datenbankfehler",
 			Err: fmt.Errorf(
@@ -96,9 +205,67 @@ func (db *recipeDatabase) readAllRecipesWithPending() ([]types.Recipe, error) {
 	return recipes, nil
 }
 
+// streamRecipes calls handle for every recipe, including pending and trashed
+// ones, in order of their id. The recipes are read one row at a time, so the whole
+// catalog is never held in memory.
+func (db *recipeDatabase) streamRecipes(handle func(recipe types.Recipe) error) error {
+	createError := func(err error) error {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at streamRecipes(), database failure: %w",
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	rows, err := db.handler.Unscoped().Model(&types.Recipe{}).Order("id").Rows()
+	if err != nil {
+		return createError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipe types.Recipe
+		if err := db.handler.ScanRows(rows, &recipe); err != nil {
+			return createError(err)
+		}
+		if err := handle(recipe); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return createError(err)
+	}
+	return nil
+}
+
+// deleteRecipe permanently deletes the recipe, whether it is in the trash or
+// not, see trashRecipe for the soft delete.
 func (db *recipeDatabase) deleteRecipe(id uint) error {
-	result := db.handler.Delete(&types.Recipe{}, id)
-	if err := result.Error; err != nil {
+	var result *gorm.DB
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		result = tx.Unscoped().Delete(&types.Recipe{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := tx.Where("recipe_id = ?", id).Delete(&types.RecipeTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("recipe_id = ?", id).Delete(&types.RecipeRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("recipe_id = ?", id).Delete(&types.RecipeSuggestion{}).Error; err != nil {
+			return err
+		}
+		if err := db.pruneTags(tx); err != nil {
+			return err
+		}
+		return db.removeRecipeFromIndex(tx, id)
+	})
+	if err != nil {
 		return &errutil.AppError{
 			UserMessage: "Datenbankfehler",
 			Err: fmt.Errorf(
@@ -122,8 +289,31 @@ func (db *recipeDatabase) deleteRecipe(id uint) error {
 	return nil
 }
 
-func (db *recipeDatabase) updateRecipe(recipe *types.Recipe) error {
-	if err := db.handler.Save(recipe).Error; err != nil {
+// updateRecipe saves the recipe. Unless revision is nil, it is stored as a
+// new revision of the recipe together with the update.
+func (db *recipeDatabase) updateRecipe(recipe *types.Recipe, revision *types.RecipeRevision) error {
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		tags, err := db.resolveTags(tx, recipe)
+		if err != nil {
+			return err
+		}
+		if revision != nil {
+			if err := db.createRevision(tx, recipe, revision); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(recipe).Error; err != nil {
+			return err
+		}
+		if err := db.linkTags(tx, recipe.ID, tags); err != nil {
+			return err
+		}
+		if err := db.pruneTags(tx); err != nil {
+			return err
+		}
+		return db.indexRecipe(tx, recipe)
+	})
+	if err != nil {
 		return &errutil.AppError{
 			UserMessage: "Datenbankfehler",
 			Err: fmt.Errorf(
@@ -136,6 +326,36 @@ func (db *recipeDatabase) updateRecipe(recipe *types.Recipe) error {
 	return nil
 }
 
+func (db *recipeDatabase) readRecipesWithoutParsedIngredients() ([]types.Recipe, error) {
+	var recipes []types.Recipe
+	if err := db.handler.Where("parsed_ingredients IS NULL").Find(&recipes).Error; err != nil {
+		return recipes, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readRecipesWithoutParsedIngredients(), database failure: %w",
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return recipes, nil
+}
+
+func (db *recipeDatabase) updateParsedIngredients(recipe *types.Recipe) error {
+	if err := db.handler.Model(recipe).Select("parsed_ingredients").Updates(recipe).Error; err != nil {
+		return &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at updateParsedIngredients() with id %d, database failure: %w",
+				recipe.ID,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return nil
+}
+
 func (db *recipeDatabase) updatePending(id uint, pending bool) error {
 	createError := func(err error) error {
 		return errutil.AddMessageToAppError(
@@ -148,7 +368,10 @@ func (db *recipeDatabase) updatePending(id uint, pending bool) error {
 		return createError(err)
 	}
 	recipe.Pending = pending
-	err = db.updateRecipe(&recipe)
+	if !pending {
+		recipe.RejectionReason = ""
+	}
+	err = db.updateRecipe(&recipe, nil)
 	if err != nil {
 		return createError(err)
 	}
@@ -23,9 +23,13 @@ func newTestRecipeDatabase() *recipeDatabase {
 		fmt.Println("failed to connect test database: ", err)
 		os.Exit(1)
 	}
-	db.Migrator().DropTable(&types.Recipe{})
-	db.AutoMigrate(&types.Recipe{})
-	return &recipeDatabase{handler: db, logger: logging.New(logging.Debug, false)}
+	db.Migrator().DropTable(&types.Recipe{}, &types.Tag{}, &types.RecipeTag{}, &types.RecipeRevision{}, &types.RecipeSuggestion{})
+	db.Exec("DROP TABLE IF EXISTS recipe_search")
+	db.AutoMigrate(&types.Recipe{}, &types.RecipeRevision{}, &types.RecipeSuggestion{})
+	recipeDb := &recipeDatabase{handler: db, logger: logging.New(logging.Debug, false)}
+	recipeDb.migrateTags()
+	recipeDb.initSearchIndex()
+	return recipeDb
 }
 
 func TestCreateRecipe(t *testing.T) {
@@ -72,7 +76,7 @@ func TestReadRecipe(t *testing.T) {
 
 	// Then
 	assert.NoError(t, err)
-	assert.True(t, r == retrievedRecipe)
+	assert.Equal(t, r, retrievedRecipe)
 }
 
 func TestReadAllRecipes(t *testing.T) {
@@ -96,7 +100,7 @@ func TestReadAllRecipes(t *testing.T) {
 	// Then
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(recipes))
-	assert.True(t, r == recipes[0])
+	assert.Equal(t, r, recipes[0])
 
 	// Given
 	r = types.NewTestRecipe()
@@ -133,7 +137,7 @@ func TestReadAllRecipesWithPending(t *testing.T) {
 	// Then
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(recipes))
-	assert.True(t, r == recipes[0])
+	assert.Equal(t, r, recipes[0])
 
 	// Given
 	r = types.NewTestRecipe()
@@ -189,12 +193,12 @@ func TestUpdateRecipe(t *testing.T) {
 
 	// When
 	r.Title = "Test recipe title modified"
-	err := db.updateRecipe(&r)
+	err := db.updateRecipe(&r, nil)
 	retrievedRecipe, _ := db.readRecipe(r.ID)
 
 	// Then
 	assert.NoError(t, err)
-	assert.True(t, r == retrievedRecipe)
+	assert.Equal(t, r, retrievedRecipe)
 }
 
 func TestUpdatePending(t *testing.T) {
@@ -221,3 +225,33 @@ func TestUpdatePending(t *testing.T) {
 	// Then
 	assert.NoError(t, err)
 }
+
+func TestStreamRecipes(t *testing.T) {
+	// Given
+	db := newTestRecipeDatabase()
+	r1 := types.NewTestRecipe()
+	r1.ParsedIngredients = []types.Ingredient{{Quantity: 1, Name: "Ei"}}
+	assert.NoError(t, db.createRecipe(&r1))
+	r2 := types.NewTestRecipe()
+	r2.Pending = true
+	assert.NoError(t, db.createRecipe(&r2))
+
+	// When
+	recipes := []types.Recipe{}
+	err := db.streamRecipes(func(recipe types.Recipe) error {
+		recipes = append(recipes, recipe)
+		return nil
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Recipe{r1, r2}, recipes)
+
+	// When
+	err = db.streamRecipes(func(recipe types.Recipe) error {
+		return fmt.Errorf("stop")
+	})
+
+	// Then
+	assert.Error(t, err)
+}
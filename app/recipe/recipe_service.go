@@ -1,41 +1,89 @@
 package recipe
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
 	"math"
+	"math/rand"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kilianmandscharo/lethimcook/cache"
 	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/cooklang"
+	"github.com/kilianmandscharo/lethimcook/env"
 	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/imageutil"
+	"github.com/kilianmandscharo/lethimcook/ingredient"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/markdown"
+	"github.com/kilianmandscharo/lethimcook/schemaorg"
 	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/spam"
 	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/labstack/echo/v4"
-	"github.com/yuin/goldmark"
 )
 
+// maxFormTextSize is the size a form may have in addition to an image.
+const maxFormTextSize = 1 << 20
+
 type recipeService struct {
 	db          *recipeDatabase
 	logger      *logging.Logger
 	recipeCache *cache.RecipeCache
+	images      *imageutil.Store
+	deleteHooks []func(id uint) error
+	// readUser looks up user accounts, they are stored by the auth package
+	readUser func(id uint) (types.User, error)
+	// trashRetention is how long deleted recipes stay in the trash
+	trashRetention time.Duration
+	// spamFilter checks the forms submitted by visitors, nil turns the
+	// checks off
+	spamFilter *spam.Filter
 }
 
 func NewRecipeService(db *recipeDatabase, logger *logging.Logger) *recipeService {
-	return &recipeService{
+	rs := &recipeService{
 		db:          db,
 		logger:      logger,
 		recipeCache: cache.NewRecipeCache(logger),
+		images:      imageutil.NewStore(env.Get(env.EnvKeyImageDir)),
+		spamFilter:  spam.NewFilter(spam.LoadConfig(logger), logger),
 	}
+	rs.trashRetention = rs.parseTrashRetention(env.Get(env.EnvKeyTrashRetentionDays))
+	rs.parseMissingIngredients()
+	return rs
+}
+
+// parseMissingIngredients parses the ingredients of the recipes stored before
+// they were parsed on save and returns how many recipes were updated.
+func (rs *recipeService) parseMissingIngredients() int {
+	recipes, err := rs.db.readRecipesWithoutParsedIngredients()
+	if err != nil {
+		rs.logger.Error(errutil.AddMessageToAppError(err, "failed at parseMissingIngredients()"))
+		return 0
+	}
+	for i, recipe := range recipes {
+		recipe.ParsedIngredients = ingredient.Parse(recipe.Ingredients)
+		if err := rs.db.updateParsedIngredients(&recipe); err != nil {
+			rs.logger.Error(errutil.AddMessageToAppError(err, "failed at parseMissingIngredients()"))
+			return i
+		}
+	}
+	if len(recipes) > 0 {
+		rs.logger.Infof("parsed ingredients of %d recipes", len(recipes))
+	}
+	return len(recipes)
 }
 
 func (rs *recipeService) createRecipe(recipe *types.Recipe) error {
 	rs.recipeCache.Invalidate()
+	recipe.ParsedIngredients = ingredient.Parse(recipe.Ingredients)
 	return rs.db.createRecipe(recipe)
 }
 
@@ -43,6 +91,30 @@ func (rs *recipeService) readRecipe(id uint) (types.Recipe, error) {
 	return rs.db.readRecipe(id)
 }
 
+// ReadRecipe gives other packages like mealplan access to a single recipe.
+func (rs *recipeService) ReadRecipe(id uint) (types.Recipe, error) {
+	return rs.readRecipe(id)
+}
+
+// ReadAllRecipes gives other packages access to all recipes, pending recipes
+// are only included for admins.
+func (rs *recipeService) ReadAllRecipes(isAdmin bool) ([]types.Recipe, error) {
+	return rs.readAllRecipes(isAdmin)
+}
+
+// OnDeleteRecipe registers a hook that is called with the id of every
+// permanently deleted recipe, so that other packages can clean up their
+// references. Recipes in the trash are only missing until they are restored.
+func (rs *recipeService) OnDeleteRecipe(hook func(id uint) error) {
+	rs.deleteHooks = append(rs.deleteHooks, hook)
+}
+
+// SetUserReader sets the function used to look up user accounts, for
+// example for the author pages.
+func (rs *recipeService) SetUserReader(readUser func(id uint) (types.User, error)) {
+	rs.readUser = readUser
+}
+
 func (rs *recipeService) getReadRecipeOptionsFromRequest(c echo.Context) readRecipesOptions {
 	isAdmin := servutil.IsAuthorized(c)
 	query := c.QueryParam("search")
@@ -54,9 +126,32 @@ func (rs *recipeService) getReadRecipeOptionsFromRequest(c echo.Context) readRec
 	if err != nil {
 		pageSize = 10
 	}
+	tags := []string{}
+	for _, tag := range c.QueryParams()["tag"] {
+		if trimmedTag := strings.TrimSpace(tag); len(trimmedTag) > 0 {
+			tags = append(tags, trimmedTag)
+		}
+	}
+	tagMode := types.TagModeAnd
+	if c.QueryParam("tagMode") == types.TagModeOr {
+		tagMode = types.TagModeOr
+	}
+	recipeSort := types.RecipeSort{Value: c.QueryParam("sort")}
+	if !isValidSort(recipeSort.Value) {
+		recipeSort.Value = ""
+	}
+	if recipeSort.Value == types.SortRandom {
+		recipeSort.Seed, err = strconv.ParseInt(c.QueryParam("seed"), 10, 64)
+		if err != nil || recipeSort.Seed <= 0 {
+			recipeSort.Seed = rand.Int63n(1_000_000) + 1
+		}
+	}
 	return readRecipesOptions{
 		isAdmin:  isAdmin,
 		query:    query,
+		tags:     tags,
+		tagMode:  tagMode,
+		sort:     recipeSort,
 		page:     page,
 		pageSize: pageSize,
 	}
@@ -64,42 +159,56 @@ func (rs *recipeService) getReadRecipeOptionsFromRequest(c echo.Context) readRec
 
 type readRecipesOptions struct {
 	query          string
+	tags           []string
+	tagMode        string
+	sort           types.RecipeSort
 	page, pageSize int
 	isAdmin        bool
 }
 
-func (rs *recipeService) readRecipes(options readRecipesOptions) ([]types.Recipe, types.PaginationInfo, error) {
+func (rs *recipeService) readRecipes(options readRecipesOptions) ([]types.Recipe, types.PaginationInfo, types.TagFilter, error) {
 	recipes := []types.Recipe{}
 	paginationInfo := types.PaginationInfo{}
+	tagFilter := types.TagFilter{Mode: options.tagMode}
 	var err error
 
 	if options.page <= 0 || options.pageSize <= 0 {
-		return recipes, paginationInfo, nil
+		return recipes, paginationInfo, tagFilter, nil
 	}
 	paginationInfo.CurrentPage = options.page
 
 	recipes, err = rs.readAllRecipes(options.isAdmin)
 	if err != nil {
-		return recipes, paginationInfo, errutil.AddMessageToAppError(
+		return recipes, paginationInfo, tagFilter, errutil.AddMessageToAppError(
 			err,
 			fmt.Sprintf("failed at readRecipes() with options %v", options),
 		)
 	}
 
 	if len(options.query) > 0 {
-		recipes = rs.filterRecipes(recipes, options.query)
+		recipes, err = rs.searchRecipes(recipes, options.query)
+		if err != nil {
+			return recipes, paginationInfo, tagFilter, errutil.AddMessageToAppError(
+				err,
+				fmt.Sprintf("failed at readRecipes() with options %v", options),
+			)
+		}
 	}
+
+	filtered := filterRecipesByTags(recipes, options.tags, options.tagMode)
+	tagFilter.Facets = getTagFacets(recipes, filtered, options.tags, options.tagMode)
+	recipes = sortRecipes(filtered, options.sort)
 	paginationInfo.TotalRecipes = len(recipes)
 
 	numberOfPages := int(math.Ceil(float64(len(recipes)) / float64(options.pageSize)))
 	if numberOfPages == 0 {
-		return recipes, paginationInfo, nil
+		return recipes, paginationInfo, tagFilter, nil
 	}
 	paginationInfo.TotalPages = numberOfPages
 
 	start := (options.page - 1) * options.pageSize
 	if start >= len(recipes) {
-		return []types.Recipe{}, paginationInfo, nil
+		return []types.Recipe{}, paginationInfo, tagFilter, nil
 	}
 
 	end := options.page * options.pageSize
@@ -107,33 +216,78 @@ func (rs *recipeService) readRecipes(options readRecipesOptions) ([]types.Recipe
 		end = len(recipes)
 	}
 
-	return recipes[start:end], paginationInfo, nil
+	return recipes[start:end], paginationInfo, tagFilter, nil
 }
 
 func (rs *recipeService) readAllRecipes(isAdmin bool) ([]types.Recipe, error) {
-	if rs.recipeCache.IsValid() {
-		recipes := *rs.recipeCache.Get(isAdmin)
-		return recipes, nil
-	}
-	recipes, err := rs.db.readAllRecipesWithPending()
+	recipes, err := rs.recipeCache.Load(isAdmin, rs.db.readAllRecipesWithPending)
 	if err != nil {
-		errutil.AddMessageToAppError(
+		return recipes, errutil.AddMessageToAppError(
 			err,
 			fmt.Sprintf("failed at readAllRecipes() with isAdmin = %t", isAdmin),
 		)
 	}
-	rs.recipeCache.Set(recipes)
-	return *rs.recipeCache.Get(isAdmin), nil
+	return recipes, nil
 }
 
+// deleteRecipe permanently deletes the recipe together with its revisions,
+// suggestions and images, see trashRecipe for moving it to the trash.
 func (rs *recipeService) deleteRecipe(id uint) error {
+	recipe, err := rs.db.readRecipeWithTrashed(id)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteRecipe()")
+	}
+	revisions, err := rs.db.readRevisions(id)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteRecipe()")
+	}
+	suggestions, err := rs.db.readSuggestions(id)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteRecipe()")
+	}
 	rs.recipeCache.Invalidate()
-	return rs.db.deleteRecipe(id)
+	if err := rs.db.deleteRecipe(id); err != nil {
+		return err
+	}
+	// Old revisions keep their images, so that they can be restored. Images
+	// can be shared, for example by merged duplicates, so only the ones no
+	// longer referred to are deleted.
+	images := make(map[string]bool)
+	addImageKeys(images, recipe.Image, recipe.Instructions)
+	for _, revision := range revisions {
+		addImageKeys(images, revision.Image, revision.Instructions)
+	}
+	for _, suggestion := range suggestions {
+		addImageKeys(images, suggestion.Image, suggestion.Instructions)
+	}
+	if err := rs.deleteUnreferencedImages(images); err != nil {
+		rs.logger.Error(errutil.AddMessageToAppError(err, "failed at deleteRecipe()"))
+	}
+	// The recipe is gone either way, a failing hook must not fail the deletion
+	for _, hook := range rs.deleteHooks {
+		if err := hook(id); err != nil {
+			rs.logger.Error(errutil.AddMessageToAppError(err, "failed at deleteRecipe()"))
+		}
+	}
+	return nil
 }
 
-func (rs *recipeService) updateRecipe(recipe *types.Recipe) error {
+// updateRecipe saves the recipe and stores the new version as a revision by
+// the editor.
+func (rs *recipeService) updateRecipe(recipe *types.Recipe, editor types.User) error {
+	return rs.updateRecipeWithRevisionEditor(recipe, editor, editor.Name)
+}
+
+// updateRecipeWithRevisionEditor saves the update like updateRecipe, but
+// records revisionEditor as the editor of the saved revision.
+func (rs *recipeService) updateRecipeWithRevisionEditor(recipe *types.Recipe, editor types.User, revisionEditor string) error {
 	rs.recipeCache.Invalidate()
-	return rs.db.updateRecipe(recipe)
+	recipe.LastModifiedAt = time.Now().Format(time.RFC3339)
+	recipe.LastEditedByID = editor.ID
+	recipe.LastEditedByName = editor.Name
+	recipe.ParsedIngredients = ingredient.Parse(recipe.Ingredients)
+	revision := types.NewRecipeRevision(*recipe, revisionEditor, recipe.LastModifiedAt)
+	return rs.db.updateRecipe(recipe, &revision)
 }
 
 func (rs *recipeService) updatePending(id uint, pending bool) error {
@@ -141,6 +295,98 @@ func (rs *recipeService) updatePending(id uint, pending bool) error {
 	return rs.db.updatePending(id, pending)
 }
 
+// searchRecipes returns the recipes matching the search query, see
+// parseSearchQuery for its syntax. recipes are the recipes the user may see.
+// With the full-text index the text terms are matched and ranked by it,
+// otherwise they are matched in memory and the order is kept.
+func (rs *recipeService) searchRecipes(recipes []types.Recipe, query string) ([]types.Recipe, error) {
+	parsed, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.groups) == 0 {
+		return recipes, nil
+	}
+
+	// The ids matching each text term, read once per term from the index
+	termIds := make(map[string]map[uint]bool)
+	var rankQueries []string
+	if rs.db.fullTextSearch {
+		for _, group := range parsed.groups {
+			for _, term := range group {
+				if term.field == fieldTime {
+					continue
+				}
+				matchQuery := term.matchQuery()
+				if !term.negate {
+					rankQueries = append(rankQueries, matchQuery)
+				}
+				if _, ok := termIds[matchQuery]; ok {
+					continue
+				}
+				ids, err := rs.db.searchRecipeIds(matchQuery)
+				if err != nil {
+					return nil, errutil.AddMessageToAppError(err, "failed at searchRecipes()")
+				}
+				termIds[matchQuery] = make(map[uint]bool, len(ids))
+				for _, id := range ids {
+					termIds[matchQuery][id] = true
+				}
+			}
+		}
+	}
+
+	matchesTerm := func(recipe types.Recipe, term searchTerm) bool {
+		var matches bool
+		switch {
+		case term.field == fieldTime:
+			matches = term.matchesTime(recipe)
+		case rs.db.fullTextSearch:
+			matches = termIds[term.matchQuery()][recipe.ID]
+		default:
+			matches = term.matchesText(recipe)
+		}
+		return matches != term.negate
+	}
+
+	found := []types.Recipe{}
+	for _, recipe := range recipes {
+		for _, group := range parsed.groups {
+			matchesGroup := true
+			for _, term := range group {
+				if !matchesTerm(recipe, term) {
+					matchesGroup = false
+					break
+				}
+			}
+			if matchesGroup {
+				found = append(found, recipe)
+				break
+			}
+		}
+	}
+
+	if len(rankQueries) == 0 {
+		return found, nil
+	}
+	rankedIds, err := rs.db.searchRecipeIds(strings.Join(rankQueries, " OR "))
+	if err != nil {
+		return nil, errutil.AddMessageToAppError(err, "failed at searchRecipes()")
+	}
+	rank := make(map[uint]int, len(rankedIds))
+	for i, id := range rankedIds {
+		rank[id] = i + 1
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		rankI, rankJ := rank[found[i].ID], rank[found[j].ID]
+		if rankI == 0 || rankJ == 0 {
+			return rankI != 0
+		}
+		return rankI < rankJ
+	})
+	return found, nil
+}
+
 func (rs *recipeService) filterRecipes(recipes []types.Recipe, query string) []types.Recipe {
 	query = strings.ToLower(strings.TrimSpace(query))
 	if len(query) == 0 {
@@ -226,19 +472,6 @@ func (rs *recipeService) updateRecipeWithFormData(c echo.Context, recipe *types.
 	recipe.Ingredients = strings.TrimSpace(c.Request().FormValue("ingredients"))
 	recipe.Instructions = strings.TrimSpace(c.Request().FormValue("instructions"))
 
-	if len(recipe.Title) == 0 {
-		formErrors["title"] = errutil.FormErrorNoTitle
-	}
-	if len(recipe.Description) == 0 {
-		formErrors["description"] = errutil.FormErrorNoDescription
-	}
-	if len(recipe.Ingredients) == 0 {
-		formErrors["ingredients"] = errutil.FormErrorNoIngredients
-	}
-	if len(recipe.Instructions) == 0 {
-		formErrors["instructions"] = errutil.FormErrorNoInstructions
-	}
-
 	cookingDuration, err := strconv.Atoi(c.Request().FormValue("cookingDuration"))
 	if err != nil {
 		formErrors["cookingDuration"] = errutil.FormErrorNoCookingDuration
@@ -253,9 +486,185 @@ func (rs *recipeService) updateRecipeWithFormData(c echo.Context, recipe *types.
 		recipe.TotalDuration = totalDuration
 	}
 
+	if c.Request().FormValue("removeImage") == "on" {
+		recipe.Image = ""
+	}
+	upload, err := rs.readImageUpload(c, "image")
+	if err != nil {
+		formErrors["image"] = err
+	}
+
+	servings := strings.TrimSpace(c.Request().FormValue("servings"))
+	if len(servings) == 0 {
+		recipe.Servings = 0
+	} else if parsedServings, err := strconv.Atoi(servings); err != nil || parsedServings < 0 {
+		formErrors["servings"] = errutil.FormErrorInvalidServings
+	} else {
+		recipe.Servings = parsedServings
+	}
+
+	for key, err := range rs.validateRecipe(recipe) {
+		formErrors[key] = err
+	}
+
+	// The image is only stored once the form is valid, so that a rejected
+	// form leaves no files behind
+	if len(formErrors) == 0 && upload != nil {
+		key, err := rs.images.Save(upload.data, upload.img)
+		if err != nil {
+			return formErrors, &errutil.AppError{
+				UserMessage: "Das Bild konnte nicht gespeichert werden",
+				Err:         fmt.Errorf("failed at updateRecipeWithFormData(): %w", err),
+				StatusCode:  http.StatusInternalServerError,
+			}
+		}
+		recipe.Image = key
+	}
+
 	return formErrors, nil
 }
 
+type imageUpload struct {
+	data []byte
+	img  image.Image
+}
+
+// readImageUpload returns the validated image of the form's file input with
+// the given name or nil if no image was uploaded. Errors are form errors.
+func (rs *recipeService) readImageUpload(c echo.Context, name string) (*imageUpload, error) {
+	file, _, err := c.Request().FormFile(name)
+	if err != nil {
+		if !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+			rs.logger.Error(fmt.Errorf("failed at readImageUpload(): %w", err))
+			return nil, errutil.FormErrorInvalidImage
+		}
+		return nil, nil
+	}
+	defer file.Close()
+
+	data, img, err := imageutil.Validate(file)
+	if err != nil {
+		if errors.Is(err, imageutil.ErrTooLarge) {
+			return nil, errutil.FormErrorImageTooLarge
+		}
+		return nil, errutil.FormErrorInvalidImage
+	}
+	return &imageUpload{data: data, img: img}, nil
+}
+
+// saveInstructionsImage stores the image uploaded while writing the
+// instructions and returns the Markdown that embeds it.
+func (rs *recipeService) saveInstructionsImage(c echo.Context) (string, error) {
+	if err := rs.parseForm(c); err != nil {
+		return "", errutil.AddMessageToAppError(err, "failed at saveInstructionsImage()")
+	}
+
+	upload, err := rs.readImageUpload(c, "instructionsImage")
+	if err != nil || upload == nil {
+		if err == nil {
+			err = errutil.FormErrorInvalidImage
+		}
+		return "", &errutil.AppError{
+			UserMessage: err.Error(),
+			Err:         fmt.Errorf("failed at saveInstructionsImage(): %w", err),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	key, err := rs.images.Save(upload.data, upload.img)
+	if err != nil {
+		return "", &errutil.AppError{
+			UserMessage: "Das Bild konnte nicht gespeichert werden",
+			Err:         fmt.Errorf("failed at saveInstructionsImage(): %w", err),
+			StatusCode:  http.StatusInternalServerError,
+		}
+	}
+	return fmt.Sprintf("![](%s)", imageutil.Url(key, imageutil.VariantLarge)), nil
+}
+
+// deleteImage removes an image that is no longer used. The recipe is saved
+// at this point, so a failure is only logged.
+func (rs *recipeService) deleteImage(key string) {
+	if err := rs.images.Delete(key); err != nil {
+		rs.logger.Error(errutil.AddMessageToAppError(err, "failed at deleteImage()"))
+	}
+}
+
+// validateRecipe checks the rules every recipe has to fulfill, no matter if
+// it comes from the form or from an import, and returns the errors by field.
+func (rs *recipeService) validateRecipe(recipe *types.Recipe) map[string]error {
+	validationErrors := make(map[string]error)
+
+	if len(recipe.Title) == 0 {
+		validationErrors["title"] = errutil.FormErrorNoTitle
+	}
+	if len(recipe.Description) == 0 {
+		validationErrors["description"] = errutil.FormErrorNoDescription
+	}
+	if len(recipe.Ingredients) == 0 {
+		validationErrors["ingredients"] = errutil.FormErrorNoIngredients
+	}
+	if len(recipe.Instructions) == 0 {
+		validationErrors["instructions"] = errutil.FormErrorNoInstructions
+	}
+	if recipe.Servings < 0 {
+		validationErrors["servings"] = errutil.FormErrorInvalidServings
+	}
+
+	return validationErrors
+}
+
+func (rs *recipeService) getQueryServings(c echo.Context) (int, error) {
+	servings := c.QueryParam("servings")
+	if len(servings) == 0 {
+		return 0, nil
+	}
+	parsedServings, err := strconv.Atoi(servings)
+	if err != nil || parsedServings <= 0 {
+		return 0, &errutil.AppError{
+			UserMessage: "Ungültige Portionsanzahl",
+			Err:         fmt.Errorf("failed at getQueryServings() with param %s", servings),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return parsedServings, nil
+}
+
+func (rs *recipeService) getQueryUnitSystem(c echo.Context) (ingredient.UnitSystem, error) {
+	units := c.QueryParam("units")
+	if len(units) == 0 {
+		return "", nil
+	}
+	system, ok := ingredient.ParseUnitSystem(units)
+	if !ok {
+		return "", &errutil.AppError{
+			UserMessage: "Ungültiges Einheitensystem",
+			Err:         fmt.Errorf("failed at getQueryUnitSystem() with param %s", units),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return system, nil
+}
+
+func (rs *recipeService) convertRecipeUnits(recipe *types.Recipe, system ingredient.UnitSystem) {
+	if len(system) == 0 {
+		return
+	}
+	recipe.Ingredients = ingredient.ConvertMarkdown(recipe.Ingredients, system)
+	recipe.ParsedIngredients = ingredient.Convert(recipe.ParsedIngredients, system)
+	recipe.Instructions = ingredient.ConvertTemperatures(recipe.Instructions, system)
+}
+
+func (rs *recipeService) scaleRecipe(recipe *types.Recipe, servings int) {
+	if recipe.Servings <= 0 || servings <= 0 || recipe.Servings == servings {
+		return
+	}
+	factor := float64(servings) / float64(recipe.Servings)
+	recipe.Ingredients = ingredient.ScaleMarkdown(recipe.Ingredients, factor)
+	recipe.ParsedIngredients = ingredient.Scale(recipe.ParsedIngredients, factor)
+	recipe.Servings = servings
+}
+
 func (rs *recipeService) getRecipeAsJson(id uint) ([]byte, error) {
 	recipe, err := rs.db.readRecipe(id)
 	if err != nil {
@@ -272,17 +681,50 @@ func (rs *recipeService) getRecipeAsJson(id uint) ([]byte, error) {
 	return jsonRecipe, nil
 }
 
-func (rs *recipeService) createRecipeForm(recipe types.Recipe, formErrors map[string]error) []types.FormElement {
+func (rs *recipeService) getRecipeAsCooklang(id uint) ([]byte, error) {
+	recipe, err := rs.db.readRecipe(id)
+	if err != nil {
+		return []byte{}, errutil.AddMessageToAppError(err, "failed at getRecipeAsCooklang()")
+	}
+	return []byte(cooklang.Format(recipe)), nil
+}
+
+// getRecipePageMeta returns the metadata for the head of the recipe page, so
+// that shared links get a preview and search engines can index the recipe.
+// The recipe has to be passed before its Markdown is rendered.
+func (rs *recipeService) getRecipePageMeta(baseUrl string, recipe types.Recipe) types.PageMeta {
+	url := fmt.Sprintf("%s/recipe/%d", baseUrl, recipe.ID)
+	meta := types.PageMeta{
+		Title:       recipe.Title,
+		Description: recipe.Description,
+		Url:         url,
+		Type:        "article",
+	}
+	jsonLd := schemaorg.NewRecipe(recipe, url)
+	if len(recipe.Image) > 0 {
+		meta.Image = baseUrl + imageutil.Url(recipe.Image, imageutil.VariantLarge)
+		jsonLd.Image = []string{meta.Image}
+	}
+	meta.JsonLd = jsonLd
+	return meta
+}
+
+func (rs *recipeService) createRecipeForm(c echo.Context, recipe types.Recipe, formErrors map[string]error) []types.FormElement {
 	cookingDuration := ""
 	if recipe.Duration != 0 {
 		cookingDuration = fmt.Sprintf("%d", recipe.Duration)
 	}
 
 	totalDuration := ""
-	if recipe.Duration != 0 {
+	if recipe.TotalDuration != 0 {
 		totalDuration = fmt.Sprintf("%d", recipe.TotalDuration)
 	}
 
+	servings := ""
+	if recipe.Servings != 0 {
+		servings = fmt.Sprintf("%d", recipe.Servings)
+	}
+
 	return []types.FormElement{
 		{
 			Type:      types.FormElementInput,
@@ -322,6 +764,14 @@ func (rs *recipeService) createRecipeForm(recipe types.Recipe, formErrors map[st
 			Placeholder: "Gesamtzeit",
 			Required:    true,
 		},
+		{
+			Type:      types.FormElementInput,
+			Name:      "servings",
+			Err:       formErrors["servings"],
+			Value:     servings,
+			InputType: "number",
+			Label:     "Portionen",
+		},
 		{
 			Type:      types.FormElementInput,
 			Name:      "author",
@@ -339,13 +789,21 @@ func (rs *recipeService) createRecipeForm(recipe types.Recipe, formErrors map[st
 			Label:     "Quelle",
 		},
 		{
-			Type:        types.FormElementInput,
-			Name:        "tags",
-			Err:         formErrors["tags"],
-			Value:       recipe.Tags,
-			InputType:   "text",
-			Label:       "Tags (getrennt durch Kommas)",
-			Placeholder: "Tags",
+			Type:  types.FormElementImage,
+			Name:  "image",
+			Err:   formErrors["image"],
+			Value: recipe.Image,
+			Label: "Bild",
+		},
+		{
+			Type:           types.FormElementInput,
+			Name:           "tags",
+			Err:            formErrors["tags"],
+			Value:          recipe.Tags,
+			InputType:      "text",
+			Label:          "Tags (getrennt durch Kommas)",
+			Placeholder:    "Tags",
+			SuggestionsUrl: "/recipe/tag-suggestions",
 		},
 		{
 			Type:           types.FormElementTextArea,
@@ -365,7 +823,7 @@ func (rs *recipeService) createRecipeForm(recipe types.Recipe, formErrors map[st
 			Label:          "Anleitung (Markdown)",
 			Placeholder:    "Anleitung",
 			Required:       true,
-			LabelComponent: components.PreviewButton("instructions"),
+			LabelComponent: components.InstructionsLabelButtons(servutil.IsLoggedIn(c)),
 		},
 	}
 }
@@ -391,20 +849,28 @@ func (rs *recipeService) getRecipeLinks(isAdmin bool, query string) ([]types.Rec
 	return links, nil
 }
 
-func (rs *recipeService) renderMarkdown(markdown string) (string, error) {
-	var buf bytes.Buffer
-	if err := goldmark.Convert([]byte(markdown), &buf); err != nil {
+func (rs *recipeService) renderMarkdown(source string) (string, error) {
+	html, err := markdown.Convert(source)
+	if err != nil {
 		return "", &errutil.AppError{
 			UserMessage: "Fehler beim Markdownparsing",
 			StatusCode:  http.StatusInternalServerError,
 			Err:         fmt.Errorf("failed at renderMarkdown(): %w", err),
 		}
 	}
-	return buf.String(), nil
+	return html, nil
 }
 
+// parseForm parses url encoded as well as multipart forms, the latter carry
+// image uploads.
 func (rs *recipeService) parseForm(c echo.Context) error {
-	if err := c.Request().ParseForm(); err != nil {
+	c.Request().Body = http.MaxBytesReader(
+		c.Response(),
+		c.Request().Body,
+		imageutil.MaxUploadSize+maxFormTextSize,
+	)
+	err := c.Request().ParseMultipartForm(imageutil.MaxUploadSize)
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
 		return &errutil.AppError{
 			UserMessage: "Fehlerhaftes Formular",
 			Err:         fmt.Errorf("failed at parseForm(): %w", err),
@@ -414,6 +880,72 @@ func (rs *recipeService) parseForm(c echo.Context) error {
 	return nil
 }
 
+// checkSpam rejects forms submitted by visitors that look automated, see
+// spam.Filter for the checks.
+func (rs *recipeService) checkSpam(c echo.Context) error {
+	if rs.spamFilter == nil {
+		return nil
+	}
+	if err := rs.parseForm(c); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at checkSpam()")
+	}
+
+	texts := []string{}
+	for _, name := range []string{
+		"title",
+		"description",
+		"author",
+		"source",
+		"tags",
+		"ingredients",
+		"instructions",
+		"submitter",
+		"suggestionComment",
+	} {
+		texts = append(texts, c.Request().FormValue(name))
+	}
+
+	err := rs.spamFilter.Check(spam.Submission{
+		IP:        c.RealIP(),
+		Honeypot:  c.Request().FormValue(spam.HoneypotField),
+		StartedAt: c.Request().FormValue(spam.StartedAtField),
+		Texts:     texts,
+	})
+	if err == nil {
+		return nil
+	}
+
+	appErr := &errutil.AppError{
+		UserMessage: "Einsendung abgelehnt",
+		Err:         fmt.Errorf("failed at checkSpam(): %w", err),
+		StatusCode:  http.StatusBadRequest,
+	}
+	switch {
+	case errors.Is(err, spam.ErrRateLimited):
+		appErr.UserMessage = "Zu viele Einsendungen, bitte versuche es später erneut"
+		appErr.StatusCode = http.StatusTooManyRequests
+	case errors.Is(err, spam.ErrTooManyLinks):
+		appErr.UserMessage = "Das Rezept enthält zu viele Links"
+	case errors.Is(err, spam.ErrBannedWord):
+		appErr.UserMessage = "Das Rezept enthält unzulässige Wörter"
+	}
+	return appErr
+}
+
+// recordSubmission counts the saved submission of a visitor towards the rate
+// limit of their IP.
+func (rs *recipeService) recordSubmission(c echo.Context) {
+	if rs.spamFilter != nil {
+		rs.spamFilter.Record(c.RealIP())
+	}
+}
+
+// getFormStartedAt returns the time the submitted form was opened, it is
+// passed on when the form is shown again.
+func (rs *recipeService) getFormStartedAt(c echo.Context) string {
+	return c.Request().FormValue(spam.StartedAtField)
+}
+
 func (rs *recipeService) extractFirstFormEntry(c echo.Context) (string, string, error) {
 	if err := rs.parseForm(c); err != nil {
 		return "", "", errutil.AddMessageToAppError(
@@ -2,14 +2,28 @@ package recipe
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kilianmandscharo/lethimcook/cache"
 	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/imageutil"
+	"github.com/kilianmandscharo/lethimcook/ingredient"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/schemaorg"
+	"github.com/kilianmandscharo/lethimcook/spam"
 	"github.com/kilianmandscharo/lethimcook/testutil"
 	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/labstack/echo/v4"
@@ -18,10 +32,33 @@ import (
 
 func newTestRecipeService() *recipeService {
 	logger := logging.New(logging.Debug, false)
+	imageDir, err := os.MkdirTemp("", "lethimcook-images")
+	if err != nil {
+		fmt.Println("failed to create test image directory: ", err)
+		os.Exit(1)
+	}
 	return &recipeService{
 		db:          newTestRecipeDatabase(),
 		logger:      logger,
 		recipeCache: cache.NewRecipeCache(logger),
+		images:      imageutil.NewStore(imageDir),
+		readUser:    newTestUserReader(),
+	}
+}
+
+// newTestUserReader returns a user reader that only knows the given users.
+func newTestUserReader(users ...types.User) func(id uint) (types.User, error) {
+	return func(id uint) (types.User, error) {
+		for _, user := range users {
+			if user.ID == id {
+				return user, nil
+			}
+		}
+		return types.User{}, &errutil.AppError{
+			UserMessage: "Benutzer nicht gefunden",
+			Err:         fmt.Errorf("user with id %d not found", id),
+			StatusCode:  http.StatusNotFound,
+		}
 	}
 }
 
@@ -31,6 +68,12 @@ type newTestContextOptions struct {
 	pathPending string
 }
 
+// newTestEditor returns the user recorded as the editor of changes made in
+// the tests.
+func newTestEditor() types.User {
+	return types.User{ID: 1, Name: "admin", Role: types.RoleAdmin}
+}
+
 func newTestContext(t *testing.T, options newTestContextOptions) echo.Context {
 	e := echo.New()
 	w := httptest.NewRecorder()
@@ -280,14 +323,98 @@ func TestUpdateRecipeWithFormData(t *testing.T) {
 
 	// Then
 	assert.NoError(t, err)
-	assert.True(t, recipe == types.Recipe{
+	assert.Equal(t, types.Recipe{
 		Title:        "title",
 		Description:  "description",
 		Instructions: "instructions",
 		Ingredients:  "ingredients",
 		Duration:     20,
 		Tags:         "vegan",
-	})
+	}, recipe)
+}
+
+func TestUpdateRecipeWithFormDataServings(t *testing.T) {
+	recipeService := newTestRecipeService()
+	validFormData := testutil.ConstructTestFormDataString(testutil.TestFormDataStringOptions{})
+
+	testCases := []struct {
+		servings     string
+		wantServings int
+		wantError    bool
+	}{
+		{"", 0, false},
+		{"4", 4, false},
+		{"-1", 0, true},
+		{"vier", 0, true},
+	}
+
+	for _, test := range testCases {
+		c := newTestContext(t, newTestContextOptions{
+			formData: validFormData + "&servings=" + test.servings,
+		})
+		var recipe types.Recipe
+		formErrors, err := recipeService.updateRecipeWithFormData(c, &recipe)
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.wantServings, recipe.Servings)
+		if test.wantError {
+			assert.Equal(t, errutil.FormErrorInvalidServings, formErrors["servings"])
+		} else {
+			assert.Empty(t, formErrors)
+		}
+	}
+}
+
+func TestScaleRecipe(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.Recipe{
+		Servings:          2,
+		Ingredients:       "- 1/2 TL Salz\n- 3 Eier",
+		ParsedIngredients: []types.Ingredient{{Quantity: 0.5, Unit: "TL", Name: "Salz"}, {Quantity: 3, Name: "Eier"}},
+	}
+
+	// When
+	recipeService.scaleRecipe(&recipe, 5)
+
+	// Then
+	assert.Equal(t, 5, recipe.Servings)
+	assert.Equal(t, "- 1 1/4 TL Salz\n- 7 1/2 Eier", recipe.Ingredients)
+	assert.Equal(t, 1.25, recipe.ParsedIngredients[0].Quantity)
+
+	// Given
+	recipe = types.Recipe{Ingredients: "- 3 Eier"}
+
+	// When
+	recipeService.scaleRecipe(&recipe, 5)
+
+	// Then
+	assert.Equal(t, 0, recipe.Servings)
+	assert.Equal(t, "- 3 Eier", recipe.Ingredients)
+}
+
+func TestConvertRecipeUnits(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.Recipe{
+		Ingredients:       "- 1 lb butter\n- 2 eggs",
+		ParsedIngredients: []types.Ingredient{{Quantity: 1, Unit: "lb", Name: "butter"}, {Quantity: 2, Name: "eggs"}},
+		Instructions:      "Bake at 350 °F for 30 minutes.",
+	}
+
+	// When
+	recipeService.convertRecipeUnits(&recipe, ingredient.UnitSystemMetric)
+
+	// Then
+	assert.Equal(t, "- 450 g butter\n- 2 eggs", recipe.Ingredients)
+	assert.Equal(t, types.Ingredient{Quantity: 450, Unit: "g", Name: "butter"}, recipe.ParsedIngredients[0])
+	assert.Equal(t, "Bake at 175 °C for 30 minutes.", recipe.Instructions)
+
+	// When
+	recipeService.convertRecipeUnits(&recipe, "")
+
+	// Then
+	assert.Equal(t, "- 450 g butter\n- 2 eggs", recipe.Ingredients)
 }
 
 func TestReadAllRecipesService(t *testing.T) {
@@ -316,6 +443,67 @@ func TestReadAllRecipesService(t *testing.T) {
 	assert.Equal(t, 2, len(recipes))
 }
 
+func TestCreateRecipeParsesIngredients(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.NewTestRecipe()
+	recipe.Ingredients = "- 200 g Mehl\n- 2 Eier"
+
+	// When
+	err := recipeService.createRecipe(&recipe)
+
+	// Then
+	assert.NoError(t, err)
+	want := []types.Ingredient{
+		{Quantity: 200, Unit: "g", Name: "Mehl"},
+		{Quantity: 2, Name: "Eier"},
+	}
+	assert.Equal(t, want, recipe.ParsedIngredients)
+	retrievedRecipe, err := recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, want, retrievedRecipe.ParsedIngredients)
+
+	// When
+	recipe.Ingredients = "- 1/2 TL Salz"
+	err = recipeService.updateRecipe(&recipe, newTestEditor())
+
+	// Then
+	assert.NoError(t, err)
+	retrievedRecipe, err = recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Ingredient{{Quantity: 0.5, Unit: "TL", Name: "Salz"}}, retrievedRecipe.ParsedIngredients)
+}
+
+func TestParseMissingIngredients(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.NewTestRecipe()
+	recipe.Ingredients = "- 3 Zwiebeln"
+	assert.NoError(t, recipeService.db.createRecipe(&recipe))
+	withoutList := types.NewTestRecipe()
+	withoutList.Ingredients = "Was der Kühlschrank hergibt"
+	assert.NoError(t, recipeService.db.createRecipe(&withoutList))
+	assert.NoError(t, recipeService.db.handler.Exec("UPDATE recipes SET parsed_ingredients = NULL").Error)
+
+	// When
+	parsed := recipeService.parseMissingIngredients()
+
+	// Then
+	assert.Equal(t, 2, parsed)
+	retrievedRecipe, err := recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Ingredient{{Quantity: 3, Name: "Zwiebeln"}}, retrievedRecipe.ParsedIngredients)
+	retrievedRecipe, err = recipeService.readRecipe(withoutList.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []types.Ingredient{}, retrievedRecipe.ParsedIngredients)
+
+	// When
+	parsed = recipeService.parseMissingIngredients()
+
+	// Then
+	assert.Equal(t, 0, parsed)
+}
+
 func TestGetRecipeAsJson(t *testing.T) {
 	// Given
 	recipeService := newTestRecipeService()
@@ -334,11 +522,30 @@ func TestGetRecipeAsJson(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
-		[]byte("{\"id\":1,\"author\":\"Phillip Jeffries\",\"source\":\"\",\"title\":\"Naan\",\"description\":\"\",\"duration\":0,\"totalDuration\":0,\"ingredients\":\"\",\"instructions\":\"\",\"tags\":\"\",\"createdAt\":\"\"}"),
+		[]byte("{\"id\":1,\"author\":\"Phillip Jeffries\",\"source\":\"\",\"title\":\"Naan\",\"description\":\"\",\"duration\":0,\"totalDuration\":0,\"servings\":0,\"ingredients\":\"\",\"instructions\":\"\",\"tags\":\"\",\"createdAt\":\"\"}"),
 		recipeJson,
 	)
 }
 
+func TestGetRecipePageMeta(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.Recipe{ID: 4, Title: "Naan", Description: "Fluffig", Ingredients: "- 500 g Mehl"}
+
+	// When
+	meta := recipeService.getRecipePageMeta("https://lethimcook.de", recipe)
+
+	// Then
+	assert.Equal(t, "Naan", meta.Title)
+	assert.Equal(t, "Fluffig", meta.Description)
+	assert.Equal(t, "https://lethimcook.de/recipe/4", meta.Url)
+	assert.Equal(t, "article", meta.Type)
+	jsonLd, ok := meta.JsonLd.(schemaorg.Recipe)
+	assert.True(t, ok)
+	assert.Equal(t, "https://lethimcook.de/recipe/4", jsonLd.Url)
+	assert.Equal(t, []string{"500 g Mehl"}, jsonLd.RecipeIngredient)
+}
+
 func TestReadRecipes(t *testing.T) {
 	tests := []struct {
 		options            readRecipesOptions
@@ -527,7 +734,11 @@ func TestReadRecipes(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		recipes, paginationInfo, err := recipeService.readRecipes(tt.options)
+		// Recipes without ingredients are stored with an empty list
+		for i := range tt.wantRecipes {
+			tt.wantRecipes[i].ParsedIngredients = []types.Ingredient{}
+		}
+		recipes, paginationInfo, _, err := recipeService.readRecipes(tt.options)
 		assert.NoError(t, err)
 		assert.Equal(t, tt.wantRecipes, recipes)
 		assert.Equal(t, tt.wantPaginationInfo, paginationInfo)
@@ -630,3 +841,191 @@ func TestExtractFirstFormEntry(t *testing.T) {
 		}
 	}
 }
+
+func TestDeleteRecipeRunsHooks(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.NewTestRecipe()
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+
+	deleted := []uint{}
+	recipeService.OnDeleteRecipe(func(id uint) error {
+		return errors.New("hook failed")
+	})
+	recipeService.OnDeleteRecipe(func(id uint) error {
+		deleted = append(deleted, id)
+		return nil
+	})
+
+	// When
+	err := recipeService.deleteRecipe(recipe.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{recipe.ID}, deleted)
+
+	// When
+	err = recipeService.deleteRecipe(recipe.ID)
+
+	// Then
+	assert.Error(t, err)
+	assert.Equal(t, []uint{recipe.ID}, deleted)
+}
+
+func newTestImageContext(t *testing.T, name string, image []byte) echo.Context {
+	formValues, err := url.ParseQuery(testutil.ConstructTestFormDataString(testutil.TestFormDataStringOptions{}))
+	assert.NoError(t, err)
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, values := range formValues {
+		assert.NoError(t, writer.WriteField(name, values[0]))
+	}
+	part, err := writer.CreateFormFile(name, "bild.png")
+	assert.NoError(t, err)
+	_, err = part.Write(image)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, "", &body)
+	assert.NoError(t, err)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func newTestPng(t *testing.T) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 64, 48))))
+	return buf.Bytes()
+}
+
+func TestUpdateRecipeWithFormDataImage(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.Recipe{}
+
+	// When
+	formErrors, err := recipeService.updateRecipeWithFormData(newTestImageContext(t, "image", newTestPng(t)), &recipe)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, formErrors)
+	assert.Regexp(t, "^[0-9a-f]{32}$", recipe.Image)
+	assert.FileExists(t, filepath.Join(recipeService.images.Dir(), recipe.Image, imageutil.VariantThumbnail))
+
+	// Given
+	recipe = types.Recipe{}
+
+	// When
+	formErrors, err = recipeService.updateRecipeWithFormData(newTestImageContext(t, "image", []byte("kein Bild")), &recipe)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, errutil.FormErrorInvalidImage, formErrors["image"])
+	assert.Empty(t, recipe.Image)
+}
+
+func TestDeleteRecipeDeletesImage(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.Recipe{}
+	_, err := recipeService.updateRecipeWithFormData(newTestImageContext(t, "image", newTestPng(t)), &recipe)
+	assert.NoError(t, err)
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	imageDir := filepath.Join(recipeService.images.Dir(), recipe.Image)
+	assert.DirExists(t, imageDir)
+
+	// When
+	meta := recipeService.getRecipePageMeta("https://lethimcook.de", recipe)
+
+	// Then
+	assert.Equal(t, "https://lethimcook.de/images/"+recipe.Image+"/large.jpg", meta.Image)
+
+	// When
+	err = recipeService.deleteRecipe(recipe.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.NoDirExists(t, imageDir)
+}
+
+func TestSaveInstructionsImage(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+
+	// When
+	snippet, err := recipeService.saveInstructionsImage(newTestImageContext(t, "instructionsImage", newTestPng(t)))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Regexp(t, `^!\[\]\(/images/[0-9a-f]{32}/large\.jpg\)$`, snippet)
+	key := strings.Split(snippet, "/")[2]
+	assert.FileExists(t, filepath.Join(recipeService.images.Dir(), key, imageutil.VariantLarge))
+
+	// When
+	_, err = recipeService.saveInstructionsImage(newTestImageContext(t, "instructionsImage", []byte("kein Bild")))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+	assert.Equal(t, errutil.FormErrorInvalidImage.Error(), errutil.GetAppErrorUserMessage(err))
+
+	// When
+	_, err = recipeService.saveInstructionsImage(newTestImageContext(t, "image", newTestPng(t)))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestCheckSpam(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	formData := testutil.ConstructTestFormDataString(testutil.TestFormDataStringOptions{})
+
+	// When
+	err := recipeService.checkSpam(newTestContext(t, newTestContextOptions{formData: formData}))
+
+	// Then
+	assert.NoError(t, err)
+
+	// Given
+	config := spam.DefaultConfig()
+	config.RateLimit = 1
+	recipeService.spamFilter = spam.NewFilter(config, recipeService.logger)
+	startedAt := fmt.Sprintf("&formStartedAt=%d", time.Now().Add(-time.Minute).Unix())
+
+	// When
+	err = recipeService.checkSpam(newTestContext(t, newTestContextOptions{formData: formData}))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = recipeService.checkSpam(newTestContext(t, newTestContextOptions{
+		formData: formData + startedAt + "&source=https://a.de+https://b.de+https://c.de+https://d.de",
+	}))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+	assert.Equal(t, "Das Rezept enthält zu viele Links", err.(*errutil.AppError).UserMessage)
+
+	// When
+	c := newTestContext(t, newTestContextOptions{formData: formData + startedAt})
+	err = recipeService.checkSpam(c)
+
+	// Then
+	assert.NoError(t, err)
+
+	// When
+	err = recipeService.checkSpam(newTestContext(t, newTestContextOptions{formData: formData + startedAt}))
+
+	// Then
+	assert.NoError(t, err)
+
+	// When
+	recipeService.recordSubmission(c)
+	err = recipeService.checkSpam(newTestContext(t, newTestContextOptions{formData: formData + startedAt}))
+
+	// Then
+	assert.Equal(t, http.StatusTooManyRequests, errutil.GetAppErrorStatusCode(err))
+}
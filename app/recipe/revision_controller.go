@@ -0,0 +1,85 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderRecipeHistoryPage(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at RenderRecipeHistoryPage()"),
+		)
+	}
+
+	if !servutil.CanEdit(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "RenderRecipeHistoryPage()"))
+	}
+
+	recipe, err := rc.recipeService.getRecipeById(c)
+	if err != nil {
+		return createError(err)
+	}
+	if !servutil.CanModify(c, recipe) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "RenderRecipeHistoryPage()"))
+	}
+
+	entries, err := rc.recipeService.getRevisionHistory(recipe.ID)
+	if err != nil {
+		return createError(err)
+	}
+
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.RecipeHistoryPage(servutil.IsAuthorized(c), recipe, entries),
+	})
+}
+
+func (rc *RecipeController) HandleRestoreRevision(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleRestoreRevision()"),
+		)
+	}
+
+	if !servutil.CanEdit(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleRestoreRevision()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+	if err := rc.recipeService.checkCanModify(c, id); err != nil {
+		return createError(err)
+	}
+
+	revisionId, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		return createError(&errutil.AppError{
+			UserMessage: "Ungültiges Pfadparameter",
+			Err: fmt.Errorf(
+				"failed at HandleRestoreRevision() with parameter %s: %w",
+				c.Param("revision"),
+				err,
+			),
+			StatusCode: http.StatusBadRequest,
+		})
+	}
+
+	recipe, err := rc.recipeService.restoreRevision(id, uint(revisionId), rc.recipeService.getEditor(c))
+	if err != nil {
+		return createError(err)
+	}
+
+	return rc.renderUpdatedRecipePage(c, recipe, "Version wiederhergestellt")
+}
@@ -0,0 +1,159 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderRecipeHistoryPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipe := types.NewTestRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&recipe))
+	recipe.Title = "Neuer Titel"
+	assert.NoError(t, recipeController.recipeService.updateRecipe(&recipe, newTestEditor()))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeHistoryPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id/history",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeHistoryPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id/history",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusForbidden,
+				Role:           types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeHistoryPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id/history",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+				AssertMessage:  true,
+				MessageWant:    "Geändert: Titel",
+			},
+		)
+	})
+
+	t.Run("recipe not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeHistoryPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id/history",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "99",
+				StatusWant:     http.StatusNotFound,
+				Authorized:     true,
+			},
+		)
+	})
+}
+
+func TestHandleRestoreRevision(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipe := types.NewTestRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&recipe))
+	recipe.Title = "Neuer Titel"
+	assert.NoError(t, recipeController.recipeService.updateRecipe(&recipe, newTestEditor()))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRestoreRevision,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/history/:revision/restore",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "revision"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRestoreRevision,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/history/:revision/restore",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "revision"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusForbidden,
+				Role:            types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRestoreRevision,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/history/:revision/restore",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "revision"},
+				PathParamValues: []string{"1", "abc"},
+				StatusWant:      http.StatusBadRequest,
+				Authorized:      true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRestoreRevision,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/history/:revision/restore",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "revision"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusOK,
+				Authorized:      true,
+				AssertMessage:   true,
+				MessageWant:     "Version wiederhergestellt",
+			},
+		)
+		restored, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Test title", restored.Title)
+	})
+}
@@ -0,0 +1,84 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"gorm.io/gorm"
+)
+
+// createRevision stores the revision of the recipe. Recipes that were
+// created before revisions existed have none yet, their stored version is
+// kept as the first revision so that it can still be restored.
+func (db *recipeDatabase) createRevision(tx *gorm.DB, recipe *types.Recipe, revision *types.RecipeRevision) error {
+	var count int64
+	if err := tx.Model(&types.RecipeRevision{}).Where("recipe_id = ?", recipe.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		var stored types.Recipe
+		if err := tx.First(&stored, recipe.ID).Error; err != nil {
+			return err
+		}
+		createdAt := stored.LastModifiedAt
+		if len(createdAt) == 0 {
+			createdAt = stored.CreatedAt
+		}
+		initial := types.NewRecipeRevision(stored, "", createdAt)
+		if err := tx.Create(&initial).Error; err != nil {
+			return err
+		}
+	}
+	revision.ID = 0
+	revision.RecipeID = recipe.ID
+	return tx.Create(revision).Error
+}
+
+// readRevisions returns the revisions of the recipe, newest first.
+func (db *recipeDatabase) readRevisions(recipeId uint) ([]types.RecipeRevision, error) {
+	var revisions []types.RecipeRevision
+	if err := db.handler.Where("recipe_id = ?", recipeId).Order("id DESC").Find(&revisions).Error; err != nil {
+		return revisions, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readRevisions() with recipe id %d, database failure: %w",
+				recipeId,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return revisions, nil
+}
+
+func (db *recipeDatabase) readRevision(recipeId, revisionId uint) (types.RecipeRevision, error) {
+	var revision types.RecipeRevision
+	err := db.handler.Where("recipe_id = ?", recipeId).First(&revision, revisionId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return revision, &errutil.AppError{
+				UserMessage: "Version nicht gefunden",
+				Err: fmt.Errorf(
+					"failed at readRevision(), revision %d of recipe %d not found",
+					revisionId,
+					recipeId,
+				),
+				StatusCode: http.StatusNotFound,
+			}
+		}
+		return revision, &errutil.AppError{
+			UserMessage: "Datenbankfehler",
+			Err: fmt.Errorf(
+				"failed at readRevision() with recipe id %d and revision id %d, database failure: %w",
+				recipeId,
+				revisionId,
+				err,
+			),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+	return revision, nil
+}
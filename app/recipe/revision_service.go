@@ -0,0 +1,87 @@
+package recipe
+
+import (
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+// getEditor returns the logged in user, it is recorded as the last editor of
+// the recipe and the editor of the revisions saved by the request.
+func (rs *recipeService) getEditor(c echo.Context) types.User {
+	user, _ := servutil.GetUser(c)
+	return user
+}
+
+// getRevisionHistory returns the revisions of the recipe, newest first, each
+// compared with the revision before it.
+func (rs *recipeService) getRevisionHistory(recipeId uint) ([]types.RecipeRevisionEntry, error) {
+	entries := []types.RecipeRevisionEntry{}
+
+	revisions, err := rs.db.readRevisions(recipeId)
+	if err != nil {
+		return entries, errutil.AddMessageToAppError(err, "failed at getRevisionHistory()")
+	}
+
+	for i, revision := range revisions {
+		entry := types.RecipeRevisionEntry{Revision: revision, Current: i == 0}
+		if i+1 < len(revisions) {
+			previous := revisions[i+1]
+			entry.ChangedFields = getChangedFields(previous, revision)
+			if previous.Ingredients != revision.Ingredients {
+				entry.IngredientsDiff = diffLines(previous.Ingredients, revision.Ingredients)
+			}
+			if previous.Instructions != revision.Instructions {
+				entry.InstructionsDiff = diffLines(previous.Instructions, revision.Instructions)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func getChangedFields(previous, revision types.RecipeRevision) []string {
+	fields := []string{}
+	for _, field := range []struct {
+		label   string
+		changed bool
+	}{
+		{label: "Titel", changed: previous.Title != revision.Title},
+		{label: "Autor", changed: previous.Author != revision.Author},
+		{label: "Quelle", changed: previous.Source != revision.Source},
+		{label: "Beschreibung", changed: previous.Description != revision.Description},
+		{label: "Tags", changed: previous.Tags != revision.Tags},
+		{label: "Kochzeit", changed: previous.Duration != revision.Duration},
+		{label: "Gesamtzeit", changed: previous.TotalDuration != revision.TotalDuration},
+		{label: "Portionen", changed: previous.Servings != revision.Servings},
+		{label: "Bild", changed: previous.Image != revision.Image},
+		{label: "Zutaten", changed: previous.Ingredients != revision.Ingredients},
+		{label: "Anleitung", changed: previous.Instructions != revision.Instructions},
+	} {
+		if field.changed {
+			fields = append(fields, field.label)
+		}
+	}
+	return fields
+}
+
+// restoreRevision sets the recipe back to the content of the revision. The
+// restored version is saved as a new revision, so the restore itself can be
+// undone as well.
+func (rs *recipeService) restoreRevision(recipeId, revisionId uint, editor types.User) (types.Recipe, error) {
+	recipe, err := rs.db.readRecipe(recipeId)
+	if err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at restoreRevision()")
+	}
+	revision, err := rs.db.readRevision(recipeId, revisionId)
+	if err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at restoreRevision()")
+	}
+
+	revision.ApplyTo(&recipe)
+	if err := rs.updateRecipe(&recipe, editor); err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at restoreRevision()")
+	}
+	return recipe, nil
+}
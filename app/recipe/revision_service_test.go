@@ -0,0 +1,102 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisionHistory(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.NewTestRecipe()
+	recipe.CreatedAt = "2024-01-01T10:00:00Z"
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+
+	// When
+	entries, err := recipeService.getRevisionHistory(recipe.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// When
+	recipe.Title = "Neuer Titel"
+	recipe.Ingredients = recipe.Ingredients + "\n- Salz"
+	assert.NoError(t, recipeService.updateRecipe(&recipe, newTestEditor()))
+	entries, err = recipeService.getRevisionHistory(recipe.ID)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.True(t, entries[0].Current)
+	assert.Equal(t, "admin", entries[0].Revision.Editor)
+	assert.Equal(t, []string{"Titel", "Zutaten"}, entries[0].ChangedFields)
+	assert.Equal(t, types.DiffLine{Kind: types.DiffAdded, Text: "- Salz"}, entries[0].IngredientsDiff[len(entries[0].IngredientsDiff)-1])
+	assert.Empty(t, entries[0].InstructionsDiff)
+	assert.False(t, entries[1].Current)
+	assert.Equal(t, "Test title", entries[1].Revision.Title)
+	assert.Equal(t, "2024-01-01T10:00:00Z", entries[1].Revision.CreatedAt)
+	assert.Equal(t, "Unbekannt", entries[1].Revision.GetEditor())
+}
+
+func TestRestoreRevision(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.NewTestRecipe()
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	recipe.Title = "Neuer Titel"
+	recipe.Instructions = "Alles anders"
+	assert.NoError(t, recipeService.updateRecipe(&recipe, newTestEditor()))
+	entries, err := recipeService.getRevisionHistory(recipe.ID)
+	assert.NoError(t, err)
+	original := entries[1].Revision
+
+	// When
+	restored, err := recipeService.restoreRevision(recipe.ID, original.ID, newTestEditor())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Test title", restored.Title)
+	stored, err := recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, original.Instructions, stored.Instructions)
+	entries, err = recipeService.getRevisionHistory(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, []string{"Titel", "Anleitung"}, entries[0].ChangedFields)
+
+	// When
+	_, err = recipeService.restoreRevision(recipe.ID, 99, newTestEditor())
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	other := types.NewTestRecipe()
+	assert.NoError(t, recipeService.createRecipe(&other))
+	_, err = recipeService.restoreRevision(other.ID, original.ID, newTestEditor())
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestDeleteRecipeDeletesRevisions(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := types.NewTestRecipe()
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	recipe.Title = "Neuer Titel"
+	assert.NoError(t, recipeService.updateRecipe(&recipe, newTestEditor()))
+
+	// When
+	assert.NoError(t, recipeService.deleteRecipe(recipe.ID))
+
+	// Then
+	revisions, err := recipeService.db.readRevisions(recipe.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, revisions)
+}
@@ -0,0 +1,90 @@
+package recipe
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+var umlautReplacer = strings.NewReplacer(
+	"ä", "a", "ö", "o", "ü", "u", "ß", "ss",
+	"à", "a", "á", "a", "â", "a", "é", "e", "è", "e", "ê", "e",
+	"î", "i", "ï", "i", "ô", "o", "ñ", "n", "ç", "c",
+)
+
+// germanSuffixes are stripped from the end of a word, longest first, so that
+// "Zwiebeln", "Tomaten" and "Käse" find "Zwiebel", "Tomate" and "Kase".
+var germanSuffixes = []string{"ern", "en", "er", "es", "em", "e", "n", "s"}
+
+// searchFields returns the texts of a recipe that are searched, in the order
+// of the columns of the search index.
+func searchFields(recipe types.Recipe) []string {
+	return []string{
+		recipe.Title,
+		recipe.Description,
+		recipe.Author,
+		recipe.Tags,
+		recipe.Ingredients,
+		recipe.Instructions,
+	}
+}
+
+// searchTokens splits text into lower case words with umlauts and accents
+// folded and German inflection endings removed. The same normalization is
+// applied to the indexed recipes and to the search query.
+func searchTokens(text string) []string {
+	words := strings.FieldsFunc(umlautReplacer.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		tokens = append(tokens, stem(word))
+	}
+	return tokens
+}
+
+func stem(word string) string {
+	for _, suffix := range germanSuffixes {
+		if stemmed, found := strings.CutSuffix(word, suffix); found && len([]rune(stemmed)) >= 3 {
+			return stemmed
+		}
+	}
+	return word
+}
+
+// normalizeSearchText is the text that is written to the search index.
+func normalizeSearchText(text string) string {
+	return strings.Join(searchTokens(text), " ")
+}
+
+// buildMatchQuery turns a search query into an FTS5 query that requires every
+// word as a prefix, e.g. `"kas"* "spatzl"*` for "Käse Spätzle". It returns an
+// empty string if the query contains no words.
+func buildMatchQuery(query string) string {
+	tokens := searchTokens(query)
+	terms := make([]string, len(tokens))
+	for i, token := range tokens {
+		terms[i] = `"` + token + `"*`
+	}
+	return strings.Join(terms, " ")
+}
+
+// matchesSearch is the search without the index: every word of the query has
+// to be the prefix of a word of the text.
+func matchesSearch(text string, queryTokens []string) bool {
+	recipeTokens := searchTokens(text)
+	for _, queryToken := range queryTokens {
+		found := false
+		for _, recipeToken := range recipeTokens {
+			if strings.HasPrefix(recipeToken, queryToken) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+//go:build sqlite_fts5
+
+package recipe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The full-text index needs SQLite with FTS5, run these tests with
+// make test or go test -tags sqlite_fts5 ./...
+
+func TestSearchRecipes(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	if !recipeService.db.fullTextSearch {
+		t.Fatal("SQLite was built without FTS5")
+	}
+	newTestSearchRecipes(t, recipeService)
+
+	testCases := []struct {
+		query   string
+		isAdmin bool
+		titles  []string
+	}{
+		{query: "Kase", titles: []string{"Käsespätzle", "Tomatensuppe"}},
+		{query: "käse", titles: []string{"Käsespätzle", "Tomatensuppe"}},
+		{query: "Tomate", titles: []string{"Tomatensuppe"}},
+		{query: "zwiebel", titles: []string{"Zwiebelkuchen"}},
+		{query: "vegetar", titles: []string{"Zwiebelkuchen"}},
+		{query: "Käse schnell", titles: []string{"Tomatensuppe"}},
+		{query: "teig", titles: []string{}},
+		{query: "teig", isAdmin: true, titles: []string{"Brot"}},
+		{query: "xyz", titles: []string{}},
+	}
+
+	for _, test := range testCases {
+		// When
+		recipes, err := recipeService.readAllRecipes(test.isAdmin)
+		assert.NoError(t, err)
+		found, err := recipeService.searchRecipes(recipes, test.query)
+
+		// Then
+		assert.NoError(t, err)
+		titles := []string{}
+		for _, recipe := range found {
+			titles = append(titles, recipe.Title)
+		}
+		assert.Equal(t, test.titles, titles, test.query)
+	}
+}
+
+func TestSearchRecipesStaysInSync(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	if !recipeService.db.fullTextSearch {
+		t.Fatal("SQLite was built without FTS5")
+	}
+	newTestSearchRecipes(t, recipeService)
+
+	search := func(query string) []uint {
+		recipes, err := recipeService.readAllRecipes(true)
+		assert.NoError(t, err)
+		found, err := recipeService.searchRecipes(recipes, query)
+		assert.NoError(t, err)
+		ids := []uint{}
+		for _, recipe := range found {
+			ids = append(ids, recipe.ID)
+		}
+		return ids
+	}
+
+	// When
+	recipe, err := recipeService.readRecipe(3)
+	assert.NoError(t, err)
+	recipe.Title = "Lauchkuchen"
+	assert.NoError(t, recipeService.updateRecipe(&recipe, newTestEditor()))
+
+	// Then
+	assert.Equal(t, []uint{3}, search("lauch"))
+
+	// When
+	assert.NoError(t, recipeService.deleteRecipe(3))
+
+	// Then
+	assert.Equal(t, []uint{}, search("lauch"))
+}
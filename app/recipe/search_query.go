@@ -0,0 +1,269 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+const (
+	fieldTitle       = "titel"
+	fieldAuthor      = "autor"
+	fieldTag         = "tag"
+	fieldIngredients = "zutat"
+	fieldTime        = "zeit"
+)
+
+// searchFieldAliases maps the field names accepted in a query to the fields.
+var searchFieldAliases = map[string]string{
+	"titel":      fieldTitle,
+	"title":      fieldTitle,
+	"autor":      fieldAuthor,
+	"author":     fieldAuthor,
+	"tag":        fieldTag,
+	"tags":       fieldTag,
+	"zutat":      fieldIngredients,
+	"zutaten":    fieldIngredients,
+	"ingredient": fieldIngredients,
+	"zeit":       fieldTime,
+	"time":       fieldTime,
+	"dauer":      fieldTime,
+}
+
+// searchColumns maps the text fields to the columns of the search index.
+var searchColumns = map[string]string{
+	fieldTitle:       "title",
+	fieldAuthor:      "author",
+	fieldTag:         "tags",
+	fieldIngredients: "ingredients",
+}
+
+type searchTerm struct {
+	field    string
+	text     string
+	phrase   bool
+	negate   bool
+	operator string
+	minutes  int
+}
+
+// searchQuery is a parsed search in disjunctive normal form: a recipe
+// matches if it matches all terms of at least one group.
+type searchQuery struct {
+	groups [][]searchTerm
+}
+
+func newSearchQueryError(query, message string) error {
+	return &errutil.AppError{
+		UserMessage: "Ungültige Suche: " + message,
+		Err:         fmt.Errorf("failed at parseSearchQuery() with query %s: %s", query, message),
+		StatusCode:  http.StatusBadRequest,
+	}
+}
+
+// parseSearchQuery parses a search like `tag:vegan zeit:<30 -pilze OR
+// "rote linsen"`. Words are combined with AND, which binds tighter than OR.
+// A leading "-" excludes a term, "field:value" restricts it to a field and
+// "zeit" compares the total duration in minutes.
+func parseSearchQuery(query string) (searchQuery, error) {
+	var parsed searchQuery
+	var group []searchTerm
+
+	words, err := splitSearchQuery(query)
+	if err != nil {
+		return parsed, err
+	}
+
+	for i, word := range words {
+		if !word.quoted && (word.text == "OR" || word.text == "ODER") {
+			if len(group) == 0 || i == len(words)-1 {
+				return parsed, newSearchQueryError(query, fmt.Sprintf("%s braucht auf beiden Seiten einen Suchbegriff", word.text))
+			}
+			parsed.groups = append(parsed.groups, group)
+			group = nil
+			continue
+		}
+		term, err := parseSearchTerm(query, word)
+		if err != nil {
+			return parsed, err
+		}
+		if term.field != fieldTime && len(searchTokens(term.text)) == 0 {
+			continue
+		}
+		group = append(group, term)
+	}
+
+	if len(group) > 0 {
+		parsed.groups = append(parsed.groups, group)
+	}
+	return parsed, nil
+}
+
+type searchWord struct {
+	text   string
+	quoted bool
+}
+
+// splitSearchQuery splits the query at white space outside of quotes. Quotes
+// may also follow a field name or a "-", as in `tag:"ohne fleisch"`.
+func splitSearchQuery(query string) ([]searchWord, error) {
+	var words []searchWord
+	var builder strings.Builder
+	inQuotes, quoted := false, false
+
+	flush := func() {
+		if builder.Len() > 0 || quoted {
+			words = append(words, searchWord{text: builder.String(), quoted: quoted})
+		}
+		builder.Reset()
+		quoted = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			quoted = true
+			builder.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, newSearchQueryError(query, "Es fehlt ein schließendes Anführungszeichen")
+	}
+	flush()
+
+	return words, nil
+}
+
+func parseSearchTerm(query string, word searchWord) (searchTerm, error) {
+	var term searchTerm
+	text := word.text
+
+	if rest, found := strings.CutPrefix(text, "-"); found {
+		if len(rest) == 0 {
+			return term, newSearchQueryError(query, `Nach "-" fehlt der auszuschließende Begriff`)
+		}
+		term.negate = true
+		text = rest
+	}
+
+	if name, value, found := strings.Cut(text, ":"); found && isFieldName(name) {
+		field, ok := searchFieldAliases[strings.ToLower(name)]
+		if !ok {
+			return term, newSearchQueryError(
+				query,
+				fmt.Sprintf(`Unbekanntes Feld "%s", erlaubt sind titel, autor, tag, zutat und zeit`, name),
+			)
+		}
+		if len(value) == 0 {
+			return term, newSearchQueryError(query, fmt.Sprintf(`Nach "%s:" fehlt ein Wert`, name))
+		}
+		term.field = field
+		text = value
+	}
+
+	if term.field == fieldTime {
+		return parseTimeTerm(query, term, text)
+	}
+
+	if unquoted, found := strings.CutPrefix(text, `"`); found {
+		text = strings.TrimSuffix(unquoted, `"`)
+		term.phrase = true
+	}
+	term.text = text
+	return term, nil
+}
+
+func isFieldName(name string) bool {
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return len(name) > 0
+}
+
+// parseTimeTerm reads comparisons like "<30", ">=60" or "45". A number
+// without an operator means at most that many minutes.
+func parseTimeTerm(query string, term searchTerm, value string) (searchTerm, error) {
+	term.operator = "<="
+	for _, operator := range []string{"<=", ">=", "<", ">", "="} {
+		if rest, found := strings.CutPrefix(value, operator); found {
+			term.operator = operator
+			value = rest
+			break
+		}
+	}
+	minutes, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || minutes < 0 {
+		return term, newSearchQueryError(
+			query,
+			fmt.Sprintf(`"%s" ist keine gültige Zeit, erwartet wird z.B. zeit:<30 oder zeit:>=60`, value),
+		)
+	}
+	term.minutes = minutes
+	return term, nil
+}
+
+// matchQuery returns the FTS5 query of a text term, e.g.
+// `{tags} : ("vegan"*)` for "tag:vegan".
+func (term searchTerm) matchQuery() string {
+	tokens := searchTokens(term.text)
+	var expression string
+	if term.phrase {
+		expression = `"` + strings.Join(tokens, " ") + `"*`
+	} else {
+		expression = buildMatchQuery(term.text)
+	}
+	if column, ok := searchColumns[term.field]; ok {
+		return fmt.Sprintf("{%s} : (%s)", column, expression)
+	}
+	return "(" + expression + ")"
+}
+
+// matchesText checks a text term against a recipe without the search index.
+func (term searchTerm) matchesText(recipe types.Recipe) bool {
+	var text string
+	switch term.field {
+	case fieldTitle:
+		text = recipe.Title
+	case fieldAuthor:
+		text = recipe.Author
+	case fieldTag:
+		text = recipe.Tags
+	case fieldIngredients:
+		text = recipe.Ingredients
+	default:
+		text = strings.Join(searchFields(recipe), "\n")
+	}
+
+	if term.phrase {
+		return strings.Contains(" "+normalizeSearchText(text), " "+normalizeSearchText(term.text))
+	}
+	return matchesSearch(text, searchTokens(term.text))
+}
+
+func (term searchTerm) matchesTime(recipe types.Recipe) bool {
+	duration := recipe.GetTotalDuration()
+	switch term.operator {
+	case "<":
+		return duration < term.minutes
+	case ">":
+		return duration > term.minutes
+	case ">=":
+		return duration >= term.minutes
+	case "=":
+		return duration == term.minutes
+	default:
+		return duration <= term.minutes
+	}
+}
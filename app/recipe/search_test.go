@@ -0,0 +1,180 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTokens(t *testing.T) {
+	testCases := []struct {
+		text   string
+		tokens []string
+	}{
+		{text: "", tokens: []string{}},
+		{text: "Käse", tokens: []string{"kas"}},
+		{text: "Kase", tokens: []string{"kas"}},
+		{text: "Zwiebeln, Tomaten & Eier", tokens: []string{"zwiebel", "tomat", "eier"}},
+		{text: "Weißbrot (Crème fraîche)", tokens: []string{"weissbrot", "crem", "fraich"}},
+		{text: "200 g Mehl", tokens: []string{"200", "g", "mehl"}},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.tokens, searchTokens(test.text), test.text)
+	}
+}
+
+func TestBuildMatchQuery(t *testing.T) {
+	assert.Equal(t, `"kas"* "spatzl"*`, buildMatchQuery("Käse Spätzle"))
+	assert.Equal(t, `"or"* "near"*`, buildMatchQuery(`OR "NEAR"`))
+	assert.Equal(t, "", buildMatchQuery(" -*\" "))
+}
+
+func newTestSearchRecipes(t *testing.T, recipeService *recipeService) {
+	for _, recipe := range []types.Recipe{
+		{Title: "Käsespätzle", Description: "Mit Bergkäse", Ingredients: "- 500 g Spätzle\n- 200 g Käse"},
+		{Title: "Tomatensuppe", Description: "Schnell", Ingredients: "- 1 kg Tomaten", Instructions: "Mit Käse bestreuen."},
+		{Title: "Zwiebelkuchen", Description: "Herbst", Ingredients: "- 1 kg Zwiebeln", Tags: "Vegetarisch"},
+		{Title: "Brot", Description: "Einfach", Instructions: "Den Teig kneten.", Pending: true},
+	} {
+		assert.NoError(t, recipeService.createRecipe(&recipe))
+	}
+}
+
+func TestSearchRecipesWithoutIndex(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipeService.db.fullTextSearch = false
+	newTestSearchRecipes(t, recipeService)
+	recipes, err := recipeService.readAllRecipes(false)
+	assert.NoError(t, err)
+
+	// When
+	found, err := recipeService.searchRecipes(recipes, "Kase schnell")
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(found))
+	assert.Equal(t, "Tomatensuppe", found[0].Title)
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	testCases := []struct {
+		query  string
+		groups [][]searchTerm
+	}{
+		{query: "", groups: nil},
+		{query: "  ", groups: nil},
+		{
+			query:  "Käse nudeln",
+			groups: [][]searchTerm{{{text: "Käse"}, {text: "nudeln"}}},
+		},
+		{
+			query: `tag:vegetarisch zeit:<30 -pilze "rote linsen"`,
+			groups: [][]searchTerm{{
+				{field: fieldTag, text: "vegetarisch"},
+				{field: fieldTime, operator: "<", minutes: 30},
+				{text: "pilze", negate: true},
+				{text: "rote linsen", phrase: true},
+			}},
+		},
+		{
+			query: `Autor:"Oma Erna" OR zeit:45 ODER -Titel:suppe`,
+			groups: [][]searchTerm{
+				{{field: fieldAuthor, text: "Oma Erna", phrase: true}},
+				{{field: fieldTime, operator: "<=", minutes: 45}},
+				{{field: fieldTitle, text: "suppe", negate: true}},
+			},
+		},
+		{
+			query:  `zutat:mehl "OR" 12:30`,
+			groups: [][]searchTerm{{{field: fieldIngredients, text: "mehl"}, {text: "OR", phrase: true}, {text: "12:30"}}},
+		},
+	}
+
+	for _, test := range testCases {
+		// When
+		parsed, err := parseSearchQuery(test.query)
+
+		// Then
+		assert.NoError(t, err, test.query)
+		assert.Equal(t, test.groups, parsed.groups, test.query)
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	testCases := []struct {
+		query   string
+		message string
+	}{
+		{query: `"rote linsen`, message: "Ungültige Suche: Es fehlt ein schließendes Anführungszeichen"},
+		{query: "OR nudeln", message: "Ungültige Suche: OR braucht auf beiden Seiten einen Suchbegriff"},
+		{query: "nudeln ODER", message: "Ungültige Suche: ODER braucht auf beiden Seiten einen Suchbegriff"},
+		{query: "nudeln OR OR reis", message: "Ungültige Suche: OR braucht auf beiden Seiten einen Suchbegriff"},
+		{query: "nudeln -", message: `Ungültige Suche: Nach "-" fehlt der auszuschließende Begriff`},
+		{query: "farbe:rot", message: `Ungültige Suche: Unbekanntes Feld "farbe", erlaubt sind titel, autor, tag, zutat und zeit`},
+		{query: "tag:", message: `Ungültige Suche: Nach "tag:" fehlt ein Wert`},
+		{query: "zeit:kurz", message: `Ungültige Suche: "kurz" ist keine gültige Zeit, erwartet wird z.B. zeit:<30 oder zeit:>=60`},
+	}
+
+	for _, test := range testCases {
+		// When
+		_, err := parseSearchQuery(test.query)
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err), test.query)
+		assert.Equal(t, test.message, errutil.GetAppErrorUserMessage(err), test.query)
+	}
+}
+
+func TestSearchRecipesWithQueryLanguage(t *testing.T) {
+	for _, fullTextSearch := range []bool{true, false} {
+		// Given
+		recipeService := newTestRecipeService()
+		if fullTextSearch && !recipeService.db.fullTextSearch {
+			continue
+		}
+		recipeService.db.fullTextSearch = fullTextSearch
+		for _, recipe := range []types.Recipe{
+			{Title: "Pilzrisotto", Author: "Oma", Tags: "Vegetarisch", TotalDuration: 40, Ingredients: "- 300 g Pilze\n- 200 g Reis"},
+			{Title: "Linsensuppe", Author: "Opa", Tags: "Vegetarisch, Vegan", TotalDuration: 25, Ingredients: "- 200 g rote Linsen\n- 1 Zwiebel"},
+			{Title: "Gemüsepfanne", Author: "Oma", Tags: "Vegetarisch", Duration: 20, Ingredients: "- 200 g Pilze\n- 1 Paprika"},
+			{Title: "Gulasch", Author: "Oma", Tags: "Fleisch", TotalDuration: 120, Ingredients: "- 1 kg Rind\n- 3 Zwiebeln"},
+		} {
+			assert.NoError(t, recipeService.createRecipe(&recipe))
+		}
+		recipes, err := recipeService.readAllRecipes(false)
+		assert.NoError(t, err)
+
+		testCases := []struct {
+			query  string
+			titles []string
+		}{
+			{query: "tag:vegetarisch zeit:<30 -pilze", titles: []string{"Linsensuppe"}},
+			{query: "tag:vegetarisch zeit:<=40", titles: []string{"Gemüsepfanne", "Linsensuppe", "Pilzrisotto"}},
+			{query: "zeit:>60", titles: []string{"Gulasch"}},
+			{query: "autor:oma -tag:vegetarisch", titles: []string{"Gulasch"}},
+			{query: `"rote linsen"`, titles: []string{"Linsensuppe"}},
+			{query: `"linsen rote"`, titles: []string{}},
+			{query: "gulasch OR titel:risotto", titles: []string{"Gulasch"}},
+			{query: "gulasch OR titel:pilzrisotto", titles: []string{"Gulasch", "Pilzrisotto"}},
+			{query: "zutat:zwiebel -rind", titles: []string{"Linsensuppe"}},
+		}
+
+		for _, test := range testCases {
+			// When
+			found, err := recipeService.searchRecipes(recipes, test.query)
+
+			// Then
+			assert.NoError(t, err)
+			titles := []string{}
+			for _, recipe := range found {
+				titles = append(titles, recipe.Title)
+			}
+			assert.ElementsMatch(t, test.titles, titles, "%s (full-text search: %t)", test.query, fullTextSearch)
+		}
+	}
+}
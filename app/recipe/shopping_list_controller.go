@@ -0,0 +1,112 @@
+package recipe
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderShoppingListPage(c echo.Context) error {
+	return rc.renderShoppingListPageHelper(
+		c,
+		rc.recipeService.getShoppingListEntries(c),
+		"",
+	)
+}
+
+func (rc *RecipeController) renderShoppingListPageHelper(c echo.Context, entries []types.ShoppingListEntry, message string) error {
+	shoppingList, entries, err := rc.recipeService.buildShoppingList(entries)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderShoppingListPageHelper()"),
+		)
+	}
+	cookie := rc.recipeService.newShoppingListCookie(entries)
+	c.SetCookie(&cookie)
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.ShoppingListPage(servutil.IsAuthorized(c), shoppingList),
+		Message:   message,
+	})
+}
+
+func (rc *RecipeController) HandleAddToShoppingList(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleAddToShoppingList()"),
+		)
+	}
+
+	recipe, err := rc.recipeService.getRecipeById(c)
+	if err != nil {
+		return createError(err)
+	}
+	servings, err := rc.recipeService.getFormServings(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	entries := rc.recipeService.addShoppingListEntry(
+		rc.recipeService.getShoppingListEntries(c),
+		types.ShoppingListEntry{RecipeID: recipe.ID, Servings: servings},
+	)
+	cookie := rc.recipeService.newShoppingListCookie(entries)
+	c.SetCookie(&cookie)
+
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.Joiner(),
+		Message:   "Zur Einkaufsliste hinzugefügt",
+	})
+}
+
+func (rc *RecipeController) HandleRemoveFromShoppingList(c echo.Context) error {
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleRemoveFromShoppingList()"),
+		)
+	}
+	entries := rc.recipeService.removeShoppingListEntry(
+		rc.recipeService.getShoppingListEntries(c),
+		id,
+	)
+	return rc.renderShoppingListPageHelper(c, entries, "Von der Einkaufsliste entfernt")
+}
+
+func (rc *RecipeController) HandleClearShoppingList(c echo.Context) error {
+	return rc.renderShoppingListPageHelper(
+		c,
+		[]types.ShoppingListEntry{},
+		"Einkaufsliste geleert",
+	)
+}
+
+func (rc *RecipeController) HandleDownloadShoppingListAsText(c echo.Context) error {
+	shoppingList, _, err := rc.recipeService.buildShoppingList(
+		rc.recipeService.getShoppingListEntries(c),
+	)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleDownloadShoppingListAsText()"),
+		)
+	}
+	text := []byte(rc.recipeService.formatShoppingListAsText(shoppingList))
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		"attachment; filename=einkaufsliste.txt",
+	)
+	c.Response().Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	c.Response().Header().Set(echo.HeaderContentLength, strconv.Itoa(len(text)))
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, text)
+}
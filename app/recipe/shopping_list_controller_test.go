@@ -0,0 +1,186 @@
+package recipe
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleAddToShoppingList(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("recipe not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleAddToShoppingList,
+				Method:         http.MethodPost,
+				Route:          "/shopping-list",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusNotFound,
+			},
+		)
+	})
+
+	err := recipeController.recipeService.createRecipe(&types.Recipe{Servings: 2})
+	assert.NoError(t, err)
+
+	t.Run("invalid servings", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleAddToShoppingList,
+				Method:         http.MethodPost,
+				Route:          "/shopping-list",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithFormData:   true,
+				FormData:       "servings=0",
+				StatusWant:     http.StatusBadRequest,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleAddToShoppingList,
+				Method:         http.MethodPost,
+				Route:          "/shopping-list",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithFormData:   true,
+				FormData:       "servings=4",
+				WithCookie:     true,
+				Cookie:         http.Cookie{Name: shoppingListCookieName, Value: "2:0"},
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "Zur Einkaufsliste hinzugefügt",
+			},
+		)
+		assert.Contains(t, rr.Header().Get(echo.HeaderSetCookie), shoppingListCookieName+"=2:0.1:4")
+	})
+}
+
+func TestHandleRemoveFromShoppingList(t *testing.T) {
+	recipeController := newTestRecipeController()
+	err := recipeController.recipeService.createRecipe(&types.Recipe{Title: "Pfannkuchen"})
+	assert.NoError(t, err)
+	err = recipeController.recipeService.createRecipe(&types.Recipe{Title: "Brot"})
+	assert.NoError(t, err)
+
+	t.Run("invalid path param", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRemoveFromShoppingList,
+				Method:         http.MethodDelete,
+				Route:          "/shopping-list",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "xx",
+				StatusWant:     http.StatusBadRequest,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		rr, _ := testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRemoveFromShoppingList,
+				Method:         http.MethodDelete,
+				Route:          "/shopping-list",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				WithCookie:     true,
+				Cookie:         http.Cookie{Name: shoppingListCookieName, Value: "1:0.2:0"},
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "Von der Einkaufsliste entfernt",
+			},
+		)
+		assert.Contains(t, rr.Header().Get(echo.HeaderSetCookie), shoppingListCookieName+"=2:0")
+		assert.Contains(t, rr.Body.String(), "Brot")
+		assert.NotContains(t, rr.Body.String(), "Pfannkuchen")
+	})
+}
+
+func TestRenderShoppingListPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("empty list", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.RenderShoppingListPage,
+				Method:        http.MethodGet,
+				Route:         "/shopping-list",
+				StatusWant:    http.StatusOK,
+				AssertMessage: true,
+				MessageWant:   "Deine Einkaufsliste ist leer",
+			},
+		)
+	})
+
+	err := recipeController.recipeService.createRecipe(&types.Recipe{
+		Servings:    2,
+		Ingredients: "- 200 g Mehl",
+	})
+	assert.NoError(t, err)
+
+	t.Run("merged list", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.RenderShoppingListPage,
+				Method:        http.MethodGet,
+				Route:         "/shopping-list",
+				WithCookie:    true,
+				Cookie:        http.Cookie{Name: shoppingListCookieName, Value: "1:3"},
+				StatusWant:    http.StatusOK,
+				AssertMessage: true,
+				MessageWant:   "300 g Mehl",
+			},
+		)
+	})
+}
+
+func TestHandleDownloadShoppingListAsText(t *testing.T) {
+	// Given
+	recipeController := newTestRecipeController()
+	err := recipeController.recipeService.createRecipe(&types.Recipe{
+		Title:       "Brot",
+		Ingredients: "- 500 g Mehl",
+	})
+	assert.NoError(t, err)
+
+	// When
+	rr, _ := testutil.AssertRequest(
+		t,
+		testutil.RequestOptions{
+			HandlerFunc: recipeController.HandleDownloadShoppingListAsText,
+			Method:      http.MethodGet,
+			Route:       "/shopping-list/txt",
+			WithCookie:  true,
+			Cookie:      http.Cookie{Name: shoppingListCookieName, Value: "1:0"},
+			StatusWant:  http.StatusOK,
+		},
+	)
+
+	// Then
+	assert.True(t, strings.HasPrefix(rr.Header().Get(echo.HeaderContentType), echo.MIMETextPlain))
+	assert.Contains(t, rr.Header().Get(echo.HeaderContentDisposition), "einkaufsliste.txt")
+	assert.Contains(t, rr.Body.String(), "[ ] 500 g Mehl")
+}
@@ -0,0 +1,179 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/ingredient"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+const shoppingListCookieName = "shopping-list"
+
+// getShoppingListEntries reads the recipes on the shopping list from the
+// cookie, which holds entries like "12:4" for four servings of recipe 12
+// separated by dots. Malformed entries are skipped.
+func (rs *recipeService) getShoppingListEntries(c echo.Context) []types.ShoppingListEntry {
+	entries := []types.ShoppingListEntry{}
+
+	cookie, err := c.Cookie(shoppingListCookieName)
+	if err != nil {
+		return entries
+	}
+
+	for _, value := range strings.Split(cookie.Value, ".") {
+		id, servings, _ := strings.Cut(value, ":")
+		parsedId, err := strconv.Atoi(id)
+		if err != nil || parsedId <= 0 {
+			continue
+		}
+		parsedServings, err := strconv.Atoi(servings)
+		if err != nil || parsedServings < 0 {
+			parsedServings = 0
+		}
+		entries = rs.addShoppingListEntry(entries, types.ShoppingListEntry{
+			RecipeID: uint(parsedId),
+			Servings: parsedServings,
+		})
+	}
+
+	return entries
+}
+
+func (rs *recipeService) getFormServings(c echo.Context) (int, error) {
+	servings := strings.TrimSpace(c.FormValue("servings"))
+	if len(servings) == 0 {
+		return 0, nil
+	}
+	parsedServings, err := strconv.Atoi(servings)
+	if err != nil || parsedServings <= 0 {
+		return 0, &errutil.AppError{
+			UserMessage: "Ungültige Portionsanzahl",
+			Err:         fmt.Errorf("failed at getFormServings() with value %s", servings),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	return parsedServings, nil
+}
+
+func (rs *recipeService) newShoppingListCookie(entries []types.ShoppingListEntry) http.Cookie {
+	values := make([]string, len(entries))
+	for i, entry := range entries {
+		values[i] = fmt.Sprintf("%d:%d", entry.RecipeID, entry.Servings)
+	}
+
+	expires := time.Now().Add(30 * 24 * time.Hour)
+	if len(entries) == 0 {
+		expires = time.Unix(0, 0)
+	}
+
+	return http.Cookie{
+		Name:     shoppingListCookieName,
+		Value:    strings.Join(values, "."),
+		Expires:  expires,
+		Secure:   true,
+		HttpOnly: true,
+		Path:     "/",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// addShoppingListEntry adds entry to the list or updates the servings if the
+// recipe is already on it.
+func (rs *recipeService) addShoppingListEntry(entries []types.ShoppingListEntry, entry types.ShoppingListEntry) []types.ShoppingListEntry {
+	for i := range entries {
+		if entries[i].RecipeID == entry.RecipeID {
+			entries[i].Servings = entry.Servings
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
+
+func (rs *recipeService) removeShoppingListEntry(entries []types.ShoppingListEntry, id uint) []types.ShoppingListEntry {
+	remaining := []types.ShoppingListEntry{}
+	for _, entry := range entries {
+		if entry.RecipeID != id {
+			remaining = append(remaining, entry)
+		}
+	}
+	return remaining
+}
+
+// buildShoppingList reads the recipes of the entries, scales them to the
+// requested servings and merges their ingredients into one list grouped by
+// category. Recipes that no longer exist are left out, the returned entries
+// only contain the recipes that were found.
+func (rs *recipeService) buildShoppingList(entries []types.ShoppingListEntry) (types.ShoppingList, []types.ShoppingListEntry, error) {
+	var shoppingList types.ShoppingList
+	found := []types.ShoppingListEntry{}
+	ingredients := []types.Ingredient{}
+
+	for _, entry := range entries {
+		recipe, err := rs.readRecipe(entry.RecipeID)
+		if err != nil {
+			var appError *errutil.AppError
+			if errors.As(err, &appError) && appError.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return shoppingList, found, errutil.AddMessageToAppError(err, "failed at buildShoppingList()")
+		}
+
+		rs.scaleRecipe(&recipe, entry.Servings)
+		found = append(found, entry)
+		shoppingList.Recipes = append(shoppingList.Recipes, types.ShoppingListRecipe{
+			ID:       recipe.ID,
+			Title:    recipe.Title,
+			Servings: recipe.Servings,
+		})
+		ingredients = append(ingredients, recipe.ParsedIngredients...)
+	}
+
+	byCategory := make(map[string][]types.Ingredient)
+	for _, merged := range ingredient.Merge(ingredients) {
+		category := ingredient.Category(merged.Name)
+		byCategory[category] = append(byCategory[category], merged)
+	}
+	for _, category := range ingredient.Categories() {
+		if len(byCategory[category]) > 0 {
+			shoppingList.Categories = append(shoppingList.Categories, types.ShoppingListCategory{
+				Name:        category,
+				Ingredients: byCategory[category],
+			})
+		}
+	}
+
+	return shoppingList, found, nil
+}
+
+func (rs *recipeService) formatShoppingListAsText(shoppingList types.ShoppingList) string {
+	var builder strings.Builder
+
+	builder.WriteString("Einkaufsliste\n")
+
+	if len(shoppingList.Recipes) > 0 {
+		builder.WriteString("\nRezepte:\n")
+		for _, recipe := range shoppingList.Recipes {
+			if recipe.Servings > 0 {
+				builder.WriteString(fmt.Sprintf("- %s (%d Portionen)\n", recipe.Title, recipe.Servings))
+			} else {
+				builder.WriteString(fmt.Sprintf("- %s\n", recipe.Title))
+			}
+		}
+	}
+
+	for _, category := range shoppingList.Categories {
+		builder.WriteString(fmt.Sprintf("\n%s:\n", category.Name))
+		for _, i := range category.Ingredients {
+			builder.WriteString(fmt.Sprintf("[ ] %s\n", ingredient.Format(i)))
+		}
+	}
+
+	return builder.String()
+}
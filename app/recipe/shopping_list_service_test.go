@@ -0,0 +1,120 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetShoppingListEntries(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	testCases := []struct {
+		cookie  string
+		entries []types.ShoppingListEntry
+	}{
+		{"", []types.ShoppingListEntry{}},
+		{"1:4", []types.ShoppingListEntry{{RecipeID: 1, Servings: 4}}},
+		{
+			"1:4.2:0.x:1.3.1:2",
+			[]types.ShoppingListEntry{
+				{RecipeID: 1, Servings: 2},
+				{RecipeID: 2, Servings: 0},
+				{RecipeID: 3, Servings: 0},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		// Given
+		c := newTestContext(t, newTestContextOptions{})
+		if len(test.cookie) > 0 {
+			c.Request().AddCookie(&http.Cookie{Name: shoppingListCookieName, Value: test.cookie})
+		}
+
+		// When
+		entries := recipeService.getShoppingListEntries(c)
+
+		// Then
+		assert.Equal(t, test.entries, entries)
+	}
+}
+
+func TestNewShoppingListCookie(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	// When
+	cookie := recipeService.newShoppingListCookie([]types.ShoppingListEntry{
+		{RecipeID: 1, Servings: 4},
+		{RecipeID: 2},
+	})
+
+	// Then
+	assert.Equal(t, shoppingListCookieName, cookie.Name)
+	assert.Equal(t, "1:4.2:0", cookie.Value)
+}
+
+func TestRemoveShoppingListEntry(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	// When
+	entries := recipeService.removeShoppingListEntry(
+		[]types.ShoppingListEntry{{RecipeID: 1}, {RecipeID: 2}},
+		1,
+	)
+
+	// Then
+	assert.Equal(t, []types.ShoppingListEntry{{RecipeID: 2}}, entries)
+}
+
+func TestBuildShoppingList(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{
+		Title:       "Pfannkuchen",
+		Servings:    2,
+		Ingredients: "- 200 g Mehl\n- 2 Eier\n- 300 ml Milch",
+	}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{
+		Title:       "Brot",
+		Ingredients: "- 500 g Mehl\n- 1 TL Salz",
+	}))
+
+	// When
+	shoppingList, entries, err := recipeService.buildShoppingList([]types.ShoppingListEntry{
+		{RecipeID: 1, Servings: 4},
+		{RecipeID: 2},
+		{RecipeID: 3},
+	})
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []types.ShoppingListEntry{{RecipeID: 1, Servings: 4}, {RecipeID: 2}}, entries)
+	assert.Equal(t, []types.ShoppingListRecipe{
+		{ID: 1, Title: "Pfannkuchen", Servings: 4},
+		{ID: 2, Title: "Brot"},
+	}, shoppingList.Recipes)
+	assert.Equal(t, []types.ShoppingListCategory{
+		{Name: "Gewürze & Öle", Ingredients: []types.Ingredient{{Quantity: 1, Unit: "TL", Name: "Salz"}}},
+		{Name: "Milchprodukte & Eier", Ingredients: []types.Ingredient{
+			{Quantity: 4, Name: "Eier"},
+			{Quantity: 600, Unit: "ml", Name: "Milch"},
+		}},
+		{Name: "Backen", Ingredients: []types.Ingredient{{Quantity: 900, Unit: "g", Name: "Mehl"}}},
+	}, shoppingList.Categories)
+
+	// When
+	text := recipeService.formatShoppingListAsText(shoppingList)
+
+	// Then
+	assert.Equal(
+		t,
+		"Einkaufsliste\n\nRezepte:\n- Pfannkuchen (4 Portionen)\n- Brot\n\n"+
+			"Gewürze & Öle:\n[ ] 1 TL Salz\n\n"+
+			"Milchprodukte & Eier:\n[ ] 4 Eier\n[ ] 600 ml Milch\n\n"+
+			"Backen:\n[ ] 900 g Mehl\n",
+		text,
+	)
+}
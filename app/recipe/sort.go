@@ -0,0 +1,93 @@
+package recipe
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+func isValidSort(value string) bool {
+	for _, option := range types.SortOptions {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRecipeTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func sortTitle(title string) string {
+	return umlautReplacer.Replace(strings.ToLower(strings.TrimSpace(title)))
+}
+
+// sortRecipes returns the recipes in the requested order. An empty sort keeps
+// the order of the recipes, which is newest first or, when searching, by
+// relevance. The recipes are copied since they may belong to the cache.
+func sortRecipes(recipes []types.Recipe, recipeSort types.RecipeSort) []types.Recipe {
+	if len(recipeSort.Value) == 0 {
+		return recipes
+	}
+
+	sorted := make([]types.Recipe, len(recipes))
+	copy(sorted, recipes)
+
+	byTime := func(getTime func(recipe types.Recipe) time.Time, descending bool) func(i, j int) bool {
+		return func(i, j int) bool {
+			a, b := getTime(sorted[i]), getTime(sorted[j])
+			if a.Equal(b) {
+				return (sorted[i].ID > sorted[j].ID) == descending
+			}
+			return a.After(b) == descending
+		}
+	}
+	createdAt := func(recipe types.Recipe) time.Time {
+		return parseRecipeTime(recipe.CreatedAt)
+	}
+
+	switch recipeSort.Value {
+	case types.SortNewest:
+		sort.SliceStable(sorted, byTime(createdAt, true))
+	case types.SortOldest:
+		sort.SliceStable(sorted, byTime(createdAt, false))
+	case types.SortModified:
+		sort.SliceStable(sorted, byTime(func(recipe types.Recipe) time.Time {
+			if len(recipe.LastModifiedAt) > 0 {
+				return parseRecipeTime(recipe.LastModifiedAt)
+			}
+			return parseRecipeTime(recipe.CreatedAt)
+		}, true))
+	case types.SortTitle:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sortTitle(sorted[i].Title) < sortTitle(sorted[j].Title)
+		})
+	case types.SortDuration:
+		// Recipes without a duration go last.
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a, b := sorted[i].GetTotalDuration(), sorted[j].GetTotalDuration()
+			if a == 0 || b == 0 {
+				return a != 0 && b == 0
+			}
+			return a < b
+		})
+	case types.SortRandom:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].ID < sorted[j].ID
+		})
+		random := rand.New(rand.NewSource(recipeSort.Seed))
+		random.Shuffle(len(sorted), func(i, j int) {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		})
+	}
+
+	return sorted
+}
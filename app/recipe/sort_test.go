@@ -0,0 +1,89 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func getRecipeIds(recipes []types.Recipe) []uint {
+	ids := []uint{}
+	for _, recipe := range recipes {
+		ids = append(ids, recipe.ID)
+	}
+	return ids
+}
+
+func TestSortRecipes(t *testing.T) {
+	// Given
+	recipes := []types.Recipe{
+		{ID: 4, Title: "Zwiebelkuchen", TotalDuration: 90, CreatedAt: "2024-03-01T10:00:00Z"},
+		{ID: 3, Title: "Äpfel im Schlafrock", Duration: 30, CreatedAt: "2024-01-01T10:00:00Z", LastModifiedAt: "2024-05-01T10:00:00Z"},
+		{ID: 2, Title: "brot", CreatedAt: "2024-02-01T10:00:00Z"},
+		{ID: 1, Title: "Apfelkuchen", TotalDuration: 60, CreatedAt: "2024-02-01T10:00:00Z"},
+	}
+
+	testCases := []struct {
+		sort string
+		ids  []uint
+	}{
+		{sort: "", ids: []uint{4, 3, 2, 1}},
+		{sort: types.SortNewest, ids: []uint{4, 2, 1, 3}},
+		{sort: types.SortOldest, ids: []uint{3, 1, 2, 4}},
+		{sort: types.SortTitle, ids: []uint{3, 1, 2, 4}},
+		{sort: types.SortDuration, ids: []uint{3, 1, 4, 2}},
+		{sort: types.SortModified, ids: []uint{3, 4, 2, 1}},
+	}
+
+	for _, test := range testCases {
+		// When
+		sorted := sortRecipes(recipes, types.RecipeSort{Value: test.sort})
+
+		// Then
+		assert.Equal(t, test.ids, getRecipeIds(sorted), test.sort)
+	}
+	assert.Equal(t, []uint{4, 3, 2, 1}, getRecipeIds(recipes))
+}
+
+func TestSortRecipesRandom(t *testing.T) {
+	// Given
+	recipes := []types.Recipe{}
+	for i := range 20 {
+		recipes = append(recipes, types.Recipe{ID: uint(20 - i)})
+	}
+
+	// When
+	first := sortRecipes(recipes, types.RecipeSort{Value: types.SortRandom, Seed: 42})
+	second := sortRecipes(recipes, types.RecipeSort{Value: types.SortRandom, Seed: 42})
+	other := sortRecipes(recipes, types.RecipeSort{Value: types.SortRandom, Seed: 43})
+
+	// Then
+	assert.Equal(t, getRecipeIds(first), getRecipeIds(second))
+	assert.NotEqual(t, getRecipeIds(first), getRecipeIds(other))
+	assert.ElementsMatch(t, getRecipeIds(recipes), getRecipeIds(first))
+}
+
+func TestReadRecipesRandomSortIsStableAcrossPages(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	for range 10 {
+		assert.NoError(t, recipeService.createRecipe(&types.Recipe{}))
+	}
+	options := readRecipesOptions{
+		sort:     types.RecipeSort{Value: types.SortRandom, Seed: 7},
+		pageSize: 4,
+	}
+
+	// When
+	ids := []uint{}
+	for page := 1; page <= 3; page++ {
+		options.page = page
+		recipes, _, _, err := recipeService.readRecipes(options)
+		assert.NoError(t, err)
+		ids = append(ids, getRecipeIds(recipes)...)
+	}
+
+	// Then
+	assert.ElementsMatch(t, []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ids)
+}
@@ -0,0 +1,183 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderRecipeSuggestPage(c echo.Context) error {
+	recipe, err := rc.recipeService.getRecipeById(c)
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at RenderRecipeSuggestPage()"),
+		)
+	}
+
+	formElements := rc.recipeService.createRecipeForm(c, recipe, make(map[string]error))
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.RecipeSuggestPage(servutil.GetRole(c), recipe.ID, recipe.Title, formElements, "", ""),
+	})
+}
+
+func (rc *RecipeController) HandleCreateSuggestion(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleCreateSuggestion()"),
+		)
+	}
+
+	recipe, err := rc.recipeService.getRecipeById(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if !servutil.IsLoggedIn(c) {
+		if err := rc.recipeService.checkSpam(c); err != nil {
+			return createError(err)
+		}
+	}
+
+	suggested, formErrors, err := rc.recipeService.createSuggestion(c, recipe)
+	if err != nil {
+		return createError(err)
+	}
+
+	if len(formErrors) > 0 {
+		formElements := rc.recipeService.createRecipeForm(c, suggested, formErrors)
+		return rc.renderer.RenderComponent(render.RenderComponentOptions{
+			Context: c,
+			Component: components.RecipeSuggestPage(
+				servutil.GetRole(c),
+				recipe.ID,
+				recipe.Title,
+				formElements,
+				c.Request().FormValue("suggestionComment"),
+				rc.recipeService.getFormStartedAt(c),
+			),
+			Err: &errutil.AppError{
+				UserMessage: "Fehlerhaftes Formular",
+				StatusCode:  http.StatusBadRequest,
+				Err:         fmt.Errorf("failed at HandleCreateSuggestion(), invalid form: %v", formErrors),
+			},
+		})
+	}
+
+	if !servutil.IsLoggedIn(c) {
+		rc.recipeService.recordSubmission(c)
+	}
+	rc.logger.Infof("created suggestion for recipe %d", recipe.ID)
+	return rc.renderUpdatedRecipePage(c, recipe, "Änderungsvorschlag eingereicht")
+}
+
+func (rc *RecipeController) HandleAcceptSuggestion(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleAcceptSuggestion()"),
+		)
+	}
+
+	if !servutil.CanEdit(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleAcceptSuggestion()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+	if err := rc.recipeService.checkCanModify(c, id); err != nil {
+		return createError(err)
+	}
+
+	suggestionId, err := rc.recipeService.getPathSuggestionId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	recipe, err := rc.recipeService.acceptSuggestion(id, suggestionId, rc.recipeService.getEditor(c))
+	if err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("accepted suggestion %d for recipe %d", suggestionId, id)
+	return rc.renderUpdatedRecipePage(c, recipe, "Änderungsvorschlag übernommen")
+}
+
+func (rc *RecipeController) HandleRejectSuggestion(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleRejectSuggestion()"),
+		)
+	}
+
+	if !servutil.CanEdit(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleRejectSuggestion()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+	if err := rc.recipeService.checkCanModify(c, id); err != nil {
+		return createError(err)
+	}
+
+	suggestionId, err := rc.recipeService.getPathSuggestionId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.rejectSuggestion(id, suggestionId); err != nil {
+		return createError(err)
+	}
+
+	recipe, err := rc.recipeService.readRecipe(id)
+	if err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("rejected suggestion %d for recipe %d", suggestionId, id)
+	return rc.renderUpdatedRecipePage(c, recipe, "Änderungsvorschlag abgelehnt")
+}
+
+// renderUpdatedRecipePage shows the recipe after it was changed, together
+// with its open suggestions for users who may edit it.
+func (rc *RecipeController) renderUpdatedRecipePage(c echo.Context, recipe types.Recipe, message string) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderUpdatedRecipePage()"),
+		)
+	}
+
+	user, _ := servutil.GetUser(c)
+	var suggestions []types.RecipeSuggestionEntry
+	if user.CanModify(recipe) {
+		entries, err := rc.recipeService.getSuggestions(recipe)
+		if err != nil {
+			return createError(err)
+		}
+		suggestions = entries
+	}
+
+	if err := recipe.RenderMarkdown(); err != nil {
+		return createError(err)
+	}
+
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.RecipePage(user, recipe, recipe.ParseTags(), "", suggestions),
+		Message:   message,
+	})
+}
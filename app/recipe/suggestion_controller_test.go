@@ -0,0 +1,287 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderRecipeSuggestPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&recipe))
+
+	t.Run("recipe not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeSuggestPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id/suggest",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				StatusWant:     http.StatusNotFound,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipeSuggestPage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id/suggest",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				AssertMessage:  true,
+				MessageWant:    "Änderung vorschlagen",
+			},
+		)
+	})
+}
+
+func TestHandleCreateSuggestion(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&recipe))
+
+	t.Run("invalid form", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateSuggestion,
+				Method:         http.MethodPost,
+				Route:          "/recipe/:id/suggestions",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusBadRequest,
+				WithFormData:   true,
+				FormData:       "title=&cookingDuration=10&totalDuration=10",
+				AssertMessage:  true,
+				MessageWant:    "Fehlerhaftes Formular",
+			},
+		)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateSuggestion,
+				Method:         http.MethodPost,
+				Route:          "/recipe/:id/suggestions",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusBadRequest,
+				WithFormData:   true,
+				FormData:       testSuggestionFormData + "instructions",
+				AssertMessage:  true,
+				MessageWant:    "Keine Änderungen vorgeschlagen",
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleCreateSuggestion,
+				Method:         http.MethodPost,
+				Route:          "/recipe/:id/suggestions",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				WithFormData:   true,
+				FormData:       testSuggestionFormData + "Erst+umrühren",
+				AssertMessage:  true,
+				MessageWant:    "Änderungsvorschlag eingereicht",
+			},
+		)
+	})
+
+	t.Run("admin sees the suggestion", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.RenderRecipePage,
+				Method:         http.MethodGet,
+				Route:          "/recipe/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+				AssertMessage:  true,
+				MessageWant:    "Änderungsvorschläge",
+			},
+		)
+	})
+}
+
+func TestHandleAcceptSuggestion(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&recipe))
+	_, _, err := recipeController.recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{formData: testSuggestionFormData + "Erst+umrühren"}),
+		recipe,
+	)
+	assert.NoError(t, err)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleAcceptSuggestion,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/suggestions/:suggestion/accept",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleAcceptSuggestion,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/suggestions/:suggestion/accept",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusForbidden,
+				Role:            types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("invalid suggestion", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleAcceptSuggestion,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/suggestions/:suggestion/accept",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "abc"},
+				StatusWant:      http.StatusBadRequest,
+				Authorized:      true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleAcceptSuggestion,
+				Method:          http.MethodPost,
+				Route:           "/recipe/:id/suggestions/:suggestion/accept",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusOK,
+				Authorized:      true,
+				AssertMessage:   true,
+				MessageWant:     "Änderungsvorschlag übernommen",
+			},
+		)
+		accepted, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Erst umrühren", accepted.Instructions)
+	})
+}
+
+func TestHandleRejectSuggestion(t *testing.T) {
+	recipeController := newTestRecipeController()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&recipe))
+	_, _, err := recipeController.recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{formData: testSuggestionFormData + "Erst+umrühren"}),
+		recipe,
+	)
+	assert.NoError(t, err)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRejectSuggestion,
+				Method:          http.MethodDelete,
+				Route:           "/recipe/:id/suggestions/:suggestion",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRejectSuggestion,
+				Method:          http.MethodDelete,
+				Route:           "/recipe/:id/suggestions/:suggestion",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusForbidden,
+				Role:            types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("suggestion not found", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRejectSuggestion,
+				Method:          http.MethodDelete,
+				Route:           "/recipe/:id/suggestions/:suggestion",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "2"},
+				StatusWant:      http.StatusNotFound,
+				Authorized:      true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:     recipeController.HandleRejectSuggestion,
+				Method:          http.MethodDelete,
+				Route:           "/recipe/:id/suggestions/:suggestion",
+				WithPathParam:   true,
+				PathParamNames:  []string{"id", "suggestion"},
+				PathParamValues: []string{"1", "1"},
+				StatusWant:      http.StatusOK,
+				Authorized:      true,
+				AssertMessage:   true,
+				MessageWant:     "Änderungsvorschlag abgelehnt",
+			},
+		)
+		rejected, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "instructions", rejected.Instructions)
+	})
+}
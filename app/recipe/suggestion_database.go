@@ -0,0 +1,75 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"gorm.io/gorm"
+)
+
+func newSuggestionDatabaseError(functionName string, err error) error {
+	return &errutil.AppError{
+		UserMessage: "Datenbankfehler",
+		Err: fmt.Errorf(
+			"failed at %s, database failure: %w",
+			functionName,
+			err,
+		),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
+
+func (db *recipeDatabase) createSuggestion(suggestion *types.RecipeSuggestion) error {
+	if err := db.handler.Create(suggestion).Error; err != nil {
+		return newSuggestionDatabaseError(
+			fmt.Sprintf("createSuggestion() with recipe id %d", suggestion.RecipeID),
+			err,
+		)
+	}
+	return nil
+}
+
+// readSuggestions returns the open suggestions for the recipe, oldest first.
+func (db *recipeDatabase) readSuggestions(recipeId uint) ([]types.RecipeSuggestion, error) {
+	var suggestions []types.RecipeSuggestion
+	if err := db.handler.Where("recipe_id = ?", recipeId).Order("id").Find(&suggestions).Error; err != nil {
+		return suggestions, newSuggestionDatabaseError(
+			fmt.Sprintf("readSuggestions() with recipe id %d", recipeId),
+			err,
+		)
+	}
+	return suggestions, nil
+}
+
+func (db *recipeDatabase) readSuggestion(recipeId, suggestionId uint) (types.RecipeSuggestion, error) {
+	var suggestion types.RecipeSuggestion
+	err := db.handler.Where("recipe_id = ?", recipeId).First(&suggestion, suggestionId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return suggestion, &errutil.AppError{
+				UserMessage: "Änderungsvorschlag nicht gefunden",
+				Err: fmt.Errorf(
+					"failed at readSuggestion(), suggestion %d for recipe %d not found",
+					suggestionId,
+					recipeId,
+				),
+				StatusCode: http.StatusNotFound,
+			}
+		}
+		return suggestion, newSuggestionDatabaseError(
+			fmt.Sprintf("readSuggestion() with recipe id %d and suggestion id %d", recipeId, suggestionId),
+			err,
+		)
+	}
+	return suggestion, nil
+}
+
+func (db *recipeDatabase) deleteSuggestion(id uint) error {
+	if err := db.handler.Delete(&types.RecipeSuggestion{}, id).Error; err != nil {
+		return newSuggestionDatabaseError(fmt.Sprintf("deleteSuggestion() with id %d", id), err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// suggestionRevisionEditor is recorded as the editor of the revisions
+	// saved by accepting a suggestion, it notes where the change came from.
+	suggestionRevisionEditor = "%s (Änderungsvorschlag)"
+	maxSuggestionCommentLen  = 1000
+)
+
+func (rs *recipeService) getPathSuggestionId(c echo.Context) (uint, error) {
+	id, err := strconv.Atoi(c.Param("suggestion"))
+	if err != nil {
+		return 0, &errutil.AppError{
+			UserMessage: "Ungültiges Pfadparameter",
+			Err: fmt.Errorf(
+				"failed at getPathSuggestionId() with parameter %s: %w",
+				c.Param("suggestion"),
+				err,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+	return uint(id), nil
+}
+
+// createSuggestion stores the form data as a suggested change to the recipe.
+// It returns the suggested version of the recipe so that the form can be
+// shown again if it has errors.
+func (rs *recipeService) createSuggestion(c echo.Context, recipe types.Recipe) (types.Recipe, map[string]error, error) {
+	suggested := recipe
+	if recipe.Pending {
+		return suggested, nil, &errutil.AppError{
+			UserMessage: "Für ausstehende Rezepte können keine Änderungen vorgeschlagen werden",
+			Err: fmt.Errorf(
+				"failed at createSuggestion(), recipe %d is pending",
+				recipe.ID,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	formErrors, err := rs.updateRecipeWithFormData(c, &suggested)
+	if err != nil || len(formErrors) > 0 {
+		return suggested, formErrors, err
+	}
+
+	comment := strings.TrimSpace(c.Request().FormValue("suggestionComment"))
+	if utf8.RuneCountInString(comment) > maxSuggestionCommentLen {
+		return suggested, formErrors, rs.discardSuggestedImage(recipe, suggested, &errutil.AppError{
+			UserMessage: fmt.Sprintf("Die Anmerkung darf höchstens %d Zeichen lang sein", maxSuggestionCommentLen),
+			Err:         fmt.Errorf("failed at createSuggestion(), comment too long"),
+			StatusCode:  http.StatusBadRequest,
+		})
+	}
+
+	current := types.NewRecipeRevision(recipe, "", "")
+	proposed := types.NewRecipeRevision(suggested, "", "")
+	if len(getChangedFields(current, proposed)) == 0 {
+		return suggested, formErrors, &errutil.AppError{
+			UserMessage: "Keine Änderungen vorgeschlagen",
+			Err: fmt.Errorf(
+				"failed at createSuggestion(), suggestion for recipe %d changes nothing",
+				recipe.ID,
+			),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	suggestion := types.NewRecipeSuggestion(suggested, comment, time.Now().Format(time.RFC3339))
+	if err := rs.db.createSuggestion(&suggestion); err != nil {
+		return suggested, formErrors, rs.discardSuggestedImage(
+			recipe,
+			suggested,
+			errutil.AddMessageToAppError(err, "failed at createSuggestion()"),
+		)
+	}
+	return suggested, formErrors, nil
+}
+
+// discardSuggestedImage deletes the image uploaded with a suggestion that is
+// not saved and passes the error on.
+func (rs *recipeService) discardSuggestedImage(recipe, suggested types.Recipe, err error) error {
+	if suggested.Image != recipe.Image {
+		rs.deleteImage(suggested.Image)
+	}
+	return err
+}
+
+// getSuggestions returns the open suggestions for the recipe, each compared
+// with the current version of the recipe.
+func (rs *recipeService) getSuggestions(recipe types.Recipe) ([]types.RecipeSuggestionEntry, error) {
+	entries := []types.RecipeSuggestionEntry{}
+
+	suggestions, err := rs.db.readSuggestions(recipe.ID)
+	if err != nil {
+		return entries, errutil.AddMessageToAppError(err, "failed at getSuggestions()")
+	}
+
+	current := types.NewRecipeRevision(recipe, "", "")
+	for _, suggestion := range suggestions {
+		suggested := recipe
+		suggestion.ApplyTo(&suggested)
+		proposed := types.NewRecipeRevision(suggested, "", "")
+
+		entry := types.RecipeSuggestionEntry{
+			Suggestion:    suggestion,
+			ChangedFields: getChangedFields(current, proposed),
+		}
+		if current.Ingredients != proposed.Ingredients {
+			entry.IngredientsDiff = diffLines(current.Ingredients, proposed.Ingredients)
+		}
+		if current.Instructions != proposed.Instructions {
+			entry.InstructionsDiff = diffLines(current.Instructions, proposed.Instructions)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// acceptSuggestion merges the suggestion into the recipe. The change is
+// saved as a revision of the accepting editor, marked as a suggestion.
+func (rs *recipeService) acceptSuggestion(recipeId, suggestionId uint, editor types.User) (types.Recipe, error) {
+	recipe, err := rs.db.readRecipe(recipeId)
+	if err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at acceptSuggestion()")
+	}
+	suggestion, err := rs.db.readSuggestion(recipeId, suggestionId)
+	if err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at acceptSuggestion()")
+	}
+
+	suggestion.ApplyTo(&recipe)
+	revisionEditor := fmt.Sprintf(suggestionRevisionEditor, editor.Name)
+	if err := rs.updateRecipeWithRevisionEditor(&recipe, editor, revisionEditor); err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at acceptSuggestion()")
+	}
+	if err := rs.db.deleteSuggestion(suggestion.ID); err != nil {
+		return recipe, errutil.AddMessageToAppError(err, "failed at acceptSuggestion()")
+	}
+	return recipe, nil
+}
+
+func (rs *recipeService) rejectSuggestion(recipeId, suggestionId uint) error {
+	suggestion, err := rs.db.readSuggestion(recipeId, suggestionId)
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at rejectSuggestion()")
+	}
+	if err := rs.db.deleteSuggestion(suggestion.ID); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at rejectSuggestion()")
+	}
+
+	inUse, err := rs.isImageInUse(recipeId, suggestion.Image)
+	if err != nil {
+		rs.logger.Error(errutil.AddMessageToAppError(err, "failed at rejectSuggestion()"))
+	} else if !inUse {
+		rs.deleteImage(suggestion.Image)
+	}
+	return nil
+}
+
+// isImageInUse reports whether the recipe, one of its revisions or one of its
+// suggestions still shows the image.
+func (rs *recipeService) isImageInUse(recipeId uint, key string) (bool, error) {
+	if len(key) == 0 {
+		return true, nil
+	}
+	recipe, err := rs.db.readRecipe(recipeId)
+	if err != nil {
+		return false, err
+	}
+	if recipe.Image == key {
+		return true, nil
+	}
+	revisions, err := rs.db.readRevisions(recipeId)
+	if err != nil {
+		return false, err
+	}
+	for _, revision := range revisions {
+		if revision.Image == key {
+			return true, nil
+		}
+	}
+	suggestions, err := rs.db.readSuggestions(recipeId)
+	if err != nil {
+		return false, err
+	}
+	for _, suggestion := range suggestions {
+		if suggestion.Image == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
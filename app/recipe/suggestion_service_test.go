@@ -0,0 +1,170 @@
+package recipe
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSuggestionRecipe() types.Recipe {
+	return types.Recipe{
+		Title:         "title",
+		Description:   "description",
+		Duration:      10,
+		TotalDuration: 10,
+		Tags:          "tags",
+		Ingredients:   "ingredients",
+		Instructions:  "instructions",
+	}
+}
+
+const testSuggestionFormData = "title=title&description=description&cookingDuration=10&totalDuration=10&tags=tags&ingredients=ingredients&instructions="
+
+func TestCreateSuggestion(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+
+	// When
+	_, formErrors, err := recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{formData: testSuggestionFormData + "instructions"}),
+		recipe,
+	)
+
+	// Then
+	assert.Empty(t, formErrors)
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	_, formErrors, err = recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{formData: "title=&cookingDuration=10&totalDuration=10"}),
+		recipe,
+	)
+
+	// Then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, formErrors)
+
+	// When
+	_, formErrors, err = recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{
+			formData: testSuggestionFormData + "instructions&suggestionComment=" + strings.Repeat("a", maxSuggestionCommentLen+1),
+		}),
+		recipe,
+	)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	suggested, formErrors, err := recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{
+			formData: testSuggestionFormData + "Erst+umrühren&suggestionComment=Fehlender+Schritt",
+		}),
+		recipe,
+	)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Empty(t, formErrors)
+	assert.Equal(t, "Erst umrühren", suggested.Instructions)
+	stored, err := recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "instructions", stored.Instructions)
+	entries, err := recipeService.getSuggestions(stored)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "Fehlender Schritt", entries[0].Suggestion.Comment)
+	assert.Equal(t, []string{"Anleitung"}, entries[0].ChangedFields)
+	assert.Empty(t, entries[0].IngredientsDiff)
+	assert.Equal(t, []types.DiffLine{
+		{Kind: types.DiffRemoved, Text: "instructions"},
+		{Kind: types.DiffAdded, Text: "Erst umrühren"},
+	}, entries[0].InstructionsDiff)
+}
+
+func TestCreateSuggestionForPendingRecipe(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := newTestSuggestionRecipe()
+	recipe.Pending = true
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+
+	// When
+	_, _, err := recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{formData: testSuggestionFormData + "Erst+umrühren"}),
+		recipe,
+	)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestAcceptSuggestion(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	_, _, err := recipeService.createSuggestion(
+		newTestContext(t, newTestContextOptions{formData: testSuggestionFormData + "Erst+umrühren"}),
+		recipe,
+	)
+	assert.NoError(t, err)
+
+	// When
+	_, err = recipeService.acceptSuggestion(recipe.ID, 2, newTestEditor())
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	accepted, err := recipeService.acceptSuggestion(recipe.ID, 1, newTestEditor())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Erst umrühren", accepted.Instructions)
+	stored, err := recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Erst umrühren", stored.Instructions)
+	entries, err := recipeService.getSuggestions(stored)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	history, err := recipeService.getRevisionHistory(recipe.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin (Änderungsvorschlag)", history[0].Revision.Editor)
+	assert.Equal(t, uint(1), stored.LastEditedByID)
+	assert.Equal(t, "admin", stored.LastEditedByName)
+}
+
+func TestRejectSuggestion(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipe := newTestSuggestionRecipe()
+	assert.NoError(t, recipeService.createRecipe(&recipe))
+	_, _, err := recipeService.createSuggestion(newTestImageContext(t, "image", newTestPng(t)), recipe)
+	assert.NoError(t, err)
+	entries, err := recipeService.getSuggestions(recipe)
+	assert.NoError(t, err)
+	imageDir := filepath.Join(recipeService.images.Dir(), entries[0].Suggestion.Image)
+	assert.NotEmpty(t, entries[0].Suggestion.Image)
+	assert.DirExists(t, imageDir)
+
+	// When
+	err = recipeService.rejectSuggestion(recipe.ID, 1)
+
+	// Then
+	assert.NoError(t, err)
+	entries, err = recipeService.getSuggestions(recipe)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoDirExists(t, imageDir)
+	stored, err := recipeService.readRecipe(recipe.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, stored.Image)
+}
@@ -0,0 +1,141 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderTagAdminPage(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderTagAdminPage()"),
+		)
+	}
+	return rc.renderTagAdminPage(c, "")
+}
+
+func (rc *RecipeController) renderTagAdminPage(c echo.Context, message string) error {
+	tags, err := rc.recipeService.readTags()
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderTagAdminPage()"),
+		)
+	}
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.TagAdminPage(true, tags),
+		Message:   message,
+	})
+}
+
+func (rc *RecipeController) HandleRenameTag(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleRenameTag()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleRenameTag()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.renameTag(id, c.FormValue("name")); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("renamed tag %d to %s", id, c.FormValue("name"))
+	return rc.renderTagAdminPage(c, "Tag umbenannt")
+}
+
+func (rc *RecipeController) HandleMergeTags(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleMergeTags()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleMergeTags()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	targetId, err := strconv.Atoi(c.FormValue("target"))
+	if err != nil {
+		return createError(&errutil.AppError{
+			UserMessage: "Bitte wähle ein Tag aus",
+			Err: fmt.Errorf(
+				"failed at HandleMergeTags() with target %s: %w",
+				c.FormValue("target"),
+				err,
+			),
+			StatusCode: http.StatusBadRequest,
+		})
+	}
+
+	if err := rc.recipeService.mergeTags(id, uint(targetId)); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Infof("merged tag %d into %d", id, targetId)
+	return rc.renderTagAdminPage(c, "Tags zusammengeführt")
+}
+
+func (rc *RecipeController) HandleDeleteTag(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleDeleteTag()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleDeleteTag()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.deleteTag(id); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Info("deleted tag", id)
+	return rc.renderTagAdminPage(c, "Tag entfernt")
+}
+
+func (rc *RecipeController) HandleGetTagSuggestions(c echo.Context) error {
+	suggestions, err := rc.recipeService.getTagSuggestions(c.QueryParam("tags"), servutil.IsAuthorized(c))
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleGetTagSuggestions()"),
+		)
+	}
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:       c,
+		Component:     components.TagSuggestions(suggestions),
+		OnlyComponent: true,
+	})
+}
@@ -0,0 +1,168 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTagAdminPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderTagAdminPage,
+				Method:      http.MethodGet,
+				Route:       "/admin/tags",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+	t.Run("authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderTagAdminPage,
+				Method:      http.MethodGet,
+				Route:       "/admin/tags",
+				StatusWant:  http.StatusOK,
+				Authorized:  true,
+			},
+		)
+	})
+}
+
+func TestHandleTagActions(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(
+		t,
+		recipeController.recipeService.createRecipe(&types.Recipe{Tags: "Vegan, Suppe, Veganes"}),
+	)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTag,
+				Method:         http.MethodDelete,
+				Route:          "/admin/tags/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				StatusWant:     http.StatusUnauthorized,
+			},
+		)
+	})
+	t.Run("rename", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRenameTag,
+				Method:         http.MethodPut,
+				Route:          "/admin/tags/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				WithFormData:   true,
+				FormData:       "name=Suppen",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+			},
+		)
+	})
+	t.Run("merge with invalid target", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleMergeTags,
+				Method:         http.MethodPost,
+				Route:          "/admin/tags/:id/merge",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "3",
+				WithFormData:   true,
+				FormData:       "target=",
+				StatusWant:     http.StatusBadRequest,
+				Authorized:     true,
+			},
+		)
+	})
+	t.Run("merge", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleMergeTags,
+				Method:         http.MethodPost,
+				Route:          "/admin/tags/:id/merge",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "3",
+				WithFormData:   true,
+				FormData:       "target=1",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+			},
+		)
+	})
+	t.Run("delete unknown tag", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTag,
+				Method:         http.MethodDelete,
+				Route:          "/admin/tags/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "3",
+				StatusWant:     http.StatusNotFound,
+				Authorized:     true,
+			},
+		)
+	})
+	t.Run("delete", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTag,
+				Method:         http.MethodDelete,
+				Route:          "/admin/tags/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+			},
+		)
+	})
+
+	recipe, err := recipeController.recipeService.readRecipe(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Suppen", recipe.Tags)
+}
+
+func TestHandleGetTagSuggestions(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(
+		t,
+		recipeController.recipeService.createRecipe(&types.Recipe{Tags: "Vegan, Suppe"}),
+	)
+
+	testutil.AssertRequest(
+		t,
+		testutil.RequestOptions{
+			HandlerFunc:    recipeController.HandleGetTagSuggestions,
+			Method:         http.MethodGet,
+			Route:          "/recipe/tag-suggestions",
+			WithQueryParam: true,
+			QueryParam:     "?tags=Suppe,%20v",
+			StatusWant:     http.StatusOK,
+			AssertMessage:  true,
+			MessageWant:    `<option value="Suppe, Vegan"></option>`,
+		},
+	)
+}
@@ -0,0 +1,303 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"gorm.io/gorm"
+)
+
+// The tags of a recipe are stored in the tags table and linked through the
+// recipe_tags table. Recipe.Tags keeps a comma separated copy of the linked
+// tag names, so that rendering, searching and exporting a recipe need no
+// join. Every write to a recipe or a tag updates both.
+
+func tagKey(name string) string {
+	return strings.ToLower(cleanTagName(name))
+}
+
+func cleanTagName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// migrateTags creates the tag tables and, if they are still empty, fills them
+// by splitting the tag strings of the existing recipes.
+func (db *recipeDatabase) migrateTags() error {
+	if err := db.handler.AutoMigrate(&types.Tag{}, &types.RecipeTag{}); err != nil {
+		return fmt.Errorf("failed at migrateTags(): %w", err)
+	}
+
+	var count int64
+	if err := db.handler.Model(&types.Tag{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed at migrateTags(): %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var recipes []types.Recipe
+	if err := db.handler.Where("tags IS NOT NULL AND tags != ''").Find(&recipes).Error; err != nil {
+		return fmt.Errorf("failed at migrateTags(): %w", err)
+	}
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		for _, recipe := range recipes {
+			tags, err := db.resolveTags(tx, &recipe)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&recipe).Update("tags", recipe.Tags).Error; err != nil {
+				return err
+			}
+			if err := db.linkTags(tx, recipe.ID, tags); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed at migrateTags(): %w", err)
+	}
+	if len(recipes) > 0 {
+		db.logger.Infof("migrated the tags of %d recipes", len(recipes))
+	}
+	return nil
+}
+
+// resolveTags looks up the tags of the recipe case-insensitively, creates the
+// ones that don't exist yet and rewrites recipe.Tags with their names, so that
+// "vegan " is stored as an already existing "Vegan".
+func (db *recipeDatabase) resolveTags(tx *gorm.DB, recipe *types.Recipe) ([]types.Tag, error) {
+	var existing []types.Tag
+	if err := tx.Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	tagsByKey := make(map[string]types.Tag)
+	for _, tag := range existing {
+		tagsByKey[tagKey(tag.Name)] = tag
+	}
+
+	tags := []types.Tag{}
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, name := range recipe.ParseTags() {
+		key := tagKey(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tag, ok := tagsByKey[key]
+		if !ok {
+			tag = types.Tag{Name: cleanTagName(name)}
+			if err := tx.Create(&tag).Error; err != nil {
+				return nil, err
+			}
+			tagsByKey[key] = tag
+		}
+		tags = append(tags, tag)
+		names = append(names, tag.Name)
+	}
+
+	recipe.Tags = strings.Join(names, ", ")
+	return tags, nil
+}
+
+func (db *recipeDatabase) linkTags(tx *gorm.DB, recipeId uint, tags []types.Tag) error {
+	if err := tx.Where("recipe_id = ?", recipeId).Delete(&types.RecipeTag{}).Error; err != nil {
+		return err
+	}
+	for i, tag := range tags {
+		recipeTag := types.RecipeTag{RecipeID: recipeId, TagID: tag.ID, Position: i}
+		if err := tx.Create(&recipeTag).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneTags removes the tags that are no longer used by any recipe.
+func (db *recipeDatabase) pruneTags(tx *gorm.DB) error {
+	return tx.Exec("DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM recipe_tags)").Error
+}
+
+func (db *recipeDatabase) readRecipeIdsWithTag(tx *gorm.DB, tagId uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&types.RecipeTag{}).Where("tag_id = ?", tagId).Pluck("recipe_id", &ids).Error
+	return ids, err
+}
+
+// syncRecipeTags rewrites Recipe.Tags of the recipes from the recipe_tags
+// table after tags were renamed, merged or deleted.
+func (db *recipeDatabase) syncRecipeTags(tx *gorm.DB, recipeIds []uint) error {
+	for _, id := range recipeIds {
+		var names []string
+		err := tx.Raw(
+			"SELECT tags.name FROM recipe_tags JOIN tags ON tags.id = recipe_tags.tag_id "+
+				"WHERE recipe_tags.recipe_id = ? ORDER BY recipe_tags.position",
+			id,
+		).Scan(&names).Error
+		if err != nil {
+			return err
+		}
+		// Recipes in the trash are updated as well but stay out of the
+		// search index
+		var recipe types.Recipe
+		if err := tx.Unscoped().First(&recipe, id).Error; err != nil {
+			return err
+		}
+		recipe.Tags = strings.Join(names, ", ")
+		if err := tx.Unscoped().Model(&recipe).Update("tags", recipe.Tags).Error; err != nil {
+			return err
+		}
+		if recipe.DeletedAt.Valid {
+			continue
+		}
+		if err := db.indexRecipe(tx, &recipe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTagDatabaseError(functionName string, err error) error {
+	return &errutil.AppError{
+		UserMessage: "Datenbankfehler",
+		Err: fmt.Errorf(
+			"failed at %s, database failure: %w",
+			functionName,
+			err,
+		),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
+
+func (db *recipeDatabase) readTag(id uint) (types.Tag, error) {
+	var tag types.Tag
+	if err := db.handler.First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tag, &errutil.AppError{
+				UserMessage: "Tag nicht gefunden",
+				Err: fmt.Errorf(
+					"failed at readTag(), tag with id %d not found",
+					id,
+				),
+				StatusCode: http.StatusNotFound,
+			}
+		}
+		return tag, newTagDatabaseError("readTag()", err)
+	}
+	return tag, nil
+}
+
+// readTags returns all tags with the number of recipes using them, sorted by
+// name. Recipes in the trash are not counted.
+func (db *recipeDatabase) readTags() ([]types.TagUsage, error) {
+	var tags []types.TagUsage
+	err := db.handler.Raw(
+		"SELECT tags.id, tags.name, COUNT(recipes.id) AS recipe_count " +
+			"FROM tags LEFT JOIN recipe_tags ON recipe_tags.tag_id = tags.id " +
+			"LEFT JOIN recipes ON recipes.id = recipe_tags.recipe_id AND recipes.deleted_at IS NULL " +
+			"GROUP BY tags.id",
+	).Scan(&tags).Error
+	if err != nil {
+		return tags, newTagDatabaseError("readTags()", err)
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return sortTitle(tags[i].Name) < sortTitle(tags[j].Name)
+	})
+	return tags, nil
+}
+
+// readVisibleTags returns the tags used by at least one recipe that isn't
+// pending or in the trash, visitors must not learn about the others.
+func (db *recipeDatabase) readVisibleTags() ([]types.TagUsage, error) {
+	var tags []types.TagUsage
+	err := db.handler.Raw(
+		"SELECT tags.id, tags.name, COUNT(recipes.id) AS recipe_count " +
+			"FROM tags JOIN recipe_tags ON recipe_tags.tag_id = tags.id " +
+			"JOIN recipes ON recipes.id = recipe_tags.recipe_id " +
+			"WHERE recipes.pending = 0 AND recipes.deleted_at IS NULL " +
+			"GROUP BY tags.id",
+	).Scan(&tags).Error
+	if err != nil {
+		return tags, newTagDatabaseError("readVisibleTags()", err)
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return sortTitle(tags[i].Name) < sortTitle(tags[j].Name)
+	})
+	return tags, nil
+}
+
+func (db *recipeDatabase) renameTag(id uint, name string) error {
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.Tag{}).Where("id = ?", id).Update("name", name).Error; err != nil {
+			return err
+		}
+		recipeIds, err := db.readRecipeIdsWithTag(tx, id)
+		if err != nil {
+			return err
+		}
+		return db.syncRecipeTags(tx, recipeIds)
+	})
+	if err != nil {
+		return newTagDatabaseError(fmt.Sprintf("renameTag() with id %d and name %s", id, name), err)
+	}
+	return nil
+}
+
+// mergeTags moves all recipes from the source tag to the target tag and
+// deletes the source tag.
+func (db *recipeDatabase) mergeTags(sourceId, targetId uint) error {
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		recipeIds, err := db.readRecipeIdsWithTag(tx, sourceId)
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(
+			"DELETE FROM recipe_tags WHERE tag_id = ? AND recipe_id IN "+
+				"(SELECT recipe_id FROM recipe_tags WHERE tag_id = ?)",
+			sourceId,
+			targetId,
+		).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&types.RecipeTag{}).Where("tag_id = ?", sourceId).Update("tag_id", targetId).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&types.Tag{}, sourceId).Error; err != nil {
+			return err
+		}
+		return db.syncRecipeTags(tx, recipeIds)
+	})
+	if err != nil {
+		return newTagDatabaseError(fmt.Sprintf("mergeTags() with ids %d and %d", sourceId, targetId), err)
+	}
+	return nil
+}
+
+// deleteTag removes the tag from all recipes and deletes it.
+func (db *recipeDatabase) deleteTag(id uint) error {
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		recipeIds, err := db.readRecipeIdsWithTag(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&types.RecipeTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&types.Tag{}, id).Error; err != nil {
+			return err
+		}
+		return db.syncRecipeTags(tx, recipeIds)
+	})
+	if err != nil {
+		return newTagDatabaseError(fmt.Sprintf("deleteTag() with id %d", id), err)
+	}
+	return nil
+}
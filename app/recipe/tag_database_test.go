@@ -0,0 +1,108 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTagNames(t *testing.T, db *recipeDatabase) map[string]int {
+	tags, err := db.readTags()
+	assert.NoError(t, err)
+	names := make(map[string]int)
+	for _, tag := range tags {
+		names[tag.Name] = tag.RecipeCount
+	}
+	return names
+}
+
+func TestMigrateTags(t *testing.T) {
+	// Given
+	db := newTestRecipeDatabase()
+	db.handler.Create(&types.Recipe{Title: "Linsensuppe", Tags: "Vegan,  Suppe"})
+	db.handler.Create(&types.Recipe{Title: "Ofengemüse", Tags: "vegan , Vegan"})
+	db.handler.Create(&types.Recipe{Title: "Brot"})
+
+	// When
+	err := db.migrateTags()
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Vegan": 2, "Suppe": 1}, getTagNames(t, db))
+	recipe, err := db.readRecipe(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vegan, Suppe", recipe.Tags)
+	recipe, err = db.readRecipe(2)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vegan", recipe.Tags)
+}
+
+func TestRecipeTagsStayInSync(t *testing.T) {
+	// Given
+	db := newTestRecipeDatabase()
+	recipe := types.Recipe{Title: "Linsensuppe", Tags: "Vegan, Suppe"}
+	assert.NoError(t, db.createRecipe(&recipe))
+
+	// When
+	other := types.Recipe{Title: "Tomatensuppe", Tags: "suppe,  schnelle   Küche"}
+	assert.NoError(t, db.createRecipe(&other))
+
+	// Then
+	assert.Equal(t, "Suppe, schnelle Küche", other.Tags)
+	assert.Equal(t, map[string]int{"Vegan": 1, "Suppe": 2, "schnelle Küche": 1}, getTagNames(t, db))
+
+	// When
+	recipe.Tags = "Suppe"
+	assert.NoError(t, db.updateRecipe(&recipe, nil))
+
+	// Then
+	assert.Equal(t, map[string]int{"Suppe": 2, "schnelle Küche": 1}, getTagNames(t, db))
+
+	// When
+	assert.NoError(t, db.deleteRecipe(other.ID))
+
+	// Then
+	assert.Equal(t, map[string]int{"Suppe": 1}, getTagNames(t, db))
+}
+
+func TestRenameMergeAndDeleteTags(t *testing.T) {
+	// Given
+	db := newTestRecipeDatabase()
+	for _, recipe := range []types.Recipe{
+		{Title: "Linsensuppe", Tags: "Vegan, Suppe"},
+		{Title: "Ofengemüse", Tags: "Veganes, Gemüse"},
+		{Title: "Salat", Tags: "Vegan, Veganes"},
+	} {
+		assert.NoError(t, db.createRecipe(&recipe))
+	}
+	readTags := func() []string {
+		recipes, err := db.readAllRecipesWithPending()
+		assert.NoError(t, err)
+		tags := []string{}
+		for _, recipe := range recipes {
+			tags = append(tags, recipe.Tags)
+		}
+		return tags
+	}
+
+	// When
+	assert.NoError(t, db.mergeTags(3, 1))
+
+	// Then
+	assert.Equal(t, []string{"Vegan", "Vegan, Gemüse", "Vegan, Suppe"}, readTags())
+	assert.Equal(t, map[string]int{"Vegan": 3, "Suppe": 1, "Gemüse": 1}, getTagNames(t, db))
+
+	// When
+	assert.NoError(t, db.renameTag(1, "Pflanzlich"))
+
+	// Then
+	assert.Equal(t, []string{"Pflanzlich", "Pflanzlich, Gemüse", "Pflanzlich, Suppe"}, readTags())
+
+	// When
+	assert.NoError(t, db.deleteTag(1))
+
+	// Then
+	assert.Equal(t, []string{"", "Gemüse", "Suppe"}, readTags())
+	assert.Equal(t, map[string]int{"Suppe": 1, "Gemüse": 1}, getTagNames(t, db))
+}
@@ -0,0 +1,85 @@
+package recipe
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+func recipeTagSet(recipe types.Recipe) map[string]bool {
+	tagSet := make(map[string]bool)
+	for _, tag := range recipe.ParseTags() {
+		tagSet[strings.ToLower(tag)] = true
+	}
+	return tagSet
+}
+
+// filterRecipesByTags keeps the recipes that have all of the tags, or at least
+// one of them if mode is types.TagModeOr. Tags are compared case-insensitively.
+func filterRecipesByTags(recipes []types.Recipe, tags []string, mode string) []types.Recipe {
+	if len(tags) == 0 {
+		return recipes
+	}
+
+	filtered := []types.Recipe{}
+	for _, recipe := range recipes {
+		tagSet := recipeTagSet(recipe)
+		matches := 0
+		for _, tag := range tags {
+			if tagSet[strings.ToLower(tag)] {
+				matches++
+			}
+		}
+		if (mode == types.TagModeOr && matches > 0) || matches == len(tags) {
+			filtered = append(filtered, recipe)
+		}
+	}
+	return filtered
+}
+
+// getTagFacets counts the recipes per tag. With types.TagModeAnd the counts
+// are taken from the already filtered recipes, so that every count tells how
+// many recipes are left after also selecting that tag. With types.TagModeOr
+// they are taken from all recipes. Selected tags are always listed.
+func getTagFacets(recipes, filtered []types.Recipe, selectedTags []string, mode string) []types.TagFacet {
+	counted := recipes
+	if mode != types.TagModeOr {
+		counted = filtered
+	}
+
+	facets := []types.TagFacet{}
+	indices := make(map[string]int)
+	for _, recipe := range counted {
+		for _, tag := range recipe.ParseTags() {
+			key := strings.ToLower(tag)
+			i, ok := indices[key]
+			if !ok {
+				i = len(facets)
+				indices[key] = i
+				facets = append(facets, types.TagFacet{Name: tag})
+			}
+			facets[i].Count++
+		}
+	}
+
+	for _, tag := range selectedTags {
+		key := strings.ToLower(tag)
+		i, ok := indices[key]
+		if !ok {
+			i = len(facets)
+			indices[key] = i
+			facets = append(facets, types.TagFacet{Name: tag})
+		}
+		facets[i].Selected = true
+	}
+
+	sort.SliceStable(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return strings.ToLower(facets[i].Name) < strings.ToLower(facets[j].Name)
+	})
+
+	return facets
+}
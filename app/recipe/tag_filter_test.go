@@ -0,0 +1,96 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRecipesWithTags(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	for _, recipe := range []types.Recipe{
+		{Title: "Linsensuppe", Tags: "Vegan, Suppe"},
+		{Title: "Tomatensuppe", Tags: "vegetarisch, Suppe"},
+		{Title: "Gulasch", Tags: "Fleisch"},
+		{Title: "Ofengemüse", Tags: "Vegan"},
+	} {
+		assert.NoError(t, recipeService.createRecipe(&recipe))
+	}
+
+	testCases := []struct {
+		name    string
+		options readRecipesOptions
+		titles  []string
+		facets  []types.TagFacet
+	}{
+		{
+			name:    "no tags",
+			options: readRecipesOptions{tagMode: types.TagModeAnd},
+			titles:  []string{"Ofengemüse", "Gulasch", "Tomatensuppe", "Linsensuppe"},
+			facets: []types.TagFacet{
+				{Name: "Suppe", Count: 2},
+				{Name: "Vegan", Count: 2},
+				{Name: "Fleisch", Count: 1},
+				{Name: "vegetarisch", Count: 1},
+			},
+		},
+		{
+			name:    "all tags",
+			options: readRecipesOptions{tags: []string{"suppe", "vegan"}, tagMode: types.TagModeAnd},
+			titles:  []string{"Linsensuppe"},
+			facets: []types.TagFacet{
+				{Name: "Suppe", Count: 1, Selected: true},
+				{Name: "Vegan", Count: 1, Selected: true},
+			},
+		},
+		{
+			name:    "one of the tags",
+			options: readRecipesOptions{tags: []string{"Fleisch", "Vegan"}, tagMode: types.TagModeOr},
+			titles:  []string{"Ofengemüse", "Gulasch", "Linsensuppe"},
+			facets: []types.TagFacet{
+				{Name: "Suppe", Count: 2},
+				{Name: "Vegan", Count: 2, Selected: true},
+				{Name: "Fleisch", Count: 1, Selected: true},
+				{Name: "vegetarisch", Count: 1},
+			},
+		},
+		{
+			name:    "combined with the search",
+			options: readRecipesOptions{query: "tomate OR gulasch", tags: []string{"Suppe"}, tagMode: types.TagModeOr},
+			titles:  []string{"Tomatensuppe"},
+			facets: []types.TagFacet{
+				{Name: "Fleisch", Count: 1},
+				{Name: "Suppe", Count: 1, Selected: true},
+				{Name: "vegetarisch", Count: 1},
+			},
+		},
+		{
+			name:    "unknown tag",
+			options: readRecipesOptions{tags: []string{"Kuchen"}, tagMode: types.TagModeAnd},
+			titles:  []string{},
+			facets:  []types.TagFacet{{Name: "Kuchen", Selected: true}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			// When
+			test.options.page = 1
+			test.options.pageSize = 10
+			recipes, paginationInfo, tagFilter, err := recipeService.readRecipes(test.options)
+
+			// Then
+			assert.NoError(t, err)
+			titles := []string{}
+			for _, recipe := range recipes {
+				titles = append(titles, recipe.Title)
+			}
+			assert.ElementsMatch(t, test.titles, titles)
+			assert.Equal(t, len(test.titles), paginationInfo.TotalRecipes)
+			assert.Equal(t, test.facets, tagFilter.Facets)
+			assert.Equal(t, test.options.tagMode, tagFilter.Mode)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+func (rs *recipeService) readTags() ([]types.TagUsage, error) {
+	return rs.db.readTags()
+}
+
+// renameTag renames the tag in all recipes. If another tag already has the
+// new name, the two tags are merged.
+func (rs *recipeService) renameTag(id uint, name string) error {
+	name = cleanTagName(name)
+	if len(name) == 0 || strings.Contains(name, ",") {
+		return &errutil.AppError{
+			UserMessage: "Bitte trage einen Namen ohne Kommas ein",
+			Err:         fmt.Errorf("failed at renameTag(), invalid name %s", name),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+
+	if _, err := rs.db.readTag(id); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at renameTag()")
+	}
+	tags, err := rs.db.readTags()
+	if err != nil {
+		return errutil.AddMessageToAppError(err, "failed at renameTag()")
+	}
+	for _, tag := range tags {
+		if tag.ID != id && tagKey(tag.Name) == tagKey(name) {
+			return rs.mergeTags(id, tag.ID)
+		}
+	}
+
+	rs.recipeCache.Invalidate()
+	return rs.db.renameTag(id, name)
+}
+
+func (rs *recipeService) mergeTags(sourceId, targetId uint) error {
+	if sourceId == targetId {
+		return &errutil.AppError{
+			UserMessage: "Ein Tag kann nicht mit sich selbst zusammengeführt werden",
+			Err:         fmt.Errorf("failed at mergeTags(), source and target are both %d", sourceId),
+			StatusCode:  http.StatusBadRequest,
+		}
+	}
+	for _, id := range []uint{sourceId, targetId} {
+		if _, err := rs.db.readTag(id); err != nil {
+			return errutil.AddMessageToAppError(err, "failed at mergeTags()")
+		}
+	}
+
+	rs.recipeCache.Invalidate()
+	return rs.db.mergeTags(sourceId, targetId)
+}
+
+func (rs *recipeService) deleteTag(id uint) error {
+	if _, err := rs.db.readTag(id); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteTag()")
+	}
+
+	rs.recipeCache.Invalidate()
+	return rs.db.deleteTag(id)
+}
+
+// getTagSuggestions completes the last tag of the comma separated value of
+// the tags input. Every suggestion is the whole new value of the input, so
+// that the browser can offer it in a datalist. Only admins get the tags of
+// pending and trashed recipes suggested.
+func (rs *recipeService) getTagSuggestions(value string, isAdmin bool) ([]string, error) {
+	suggestions := []string{}
+
+	readTags := rs.db.readVisibleTags
+	if isAdmin {
+		readTags = rs.db.readTags
+	}
+	tags, err := readTags()
+	if err != nil {
+		return suggestions, errutil.AddMessageToAppError(err, "failed at getTagSuggestions()")
+	}
+
+	prefix, current := "", value
+	if i := strings.LastIndex(value, ","); i >= 0 {
+		prefix, current = strings.TrimSpace(value[:i+1])+" ", value[i+1:]
+	}
+	current = tagKey(current)
+
+	entered := make(map[string]bool)
+	for _, tag := range strings.Split(prefix, ",") {
+		entered[tagKey(tag)] = true
+	}
+
+	for _, tag := range tags {
+		key := tagKey(tag.Name)
+		if entered[key] || !strings.HasPrefix(key, current) {
+			continue
+		}
+		suggestions = append(suggestions, prefix+tag.Name)
+	}
+	return suggestions, nil
+}
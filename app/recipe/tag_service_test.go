@@ -0,0 +1,107 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenameTag(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe", Tags: "Vegan, Suppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Salat", Tags: "Veganes"}))
+
+	// When
+	err := recipeService.renameTag(1, " ")
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = recipeService.renameTag(5, "Suppen")
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = recipeService.renameTag(3, "vegan")
+
+	// Then
+	assert.NoError(t, err)
+	tags, err := recipeService.readTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.TagUsage{
+		{ID: 2, Name: "Suppe", RecipeCount: 1},
+		{ID: 1, Name: "Vegan", RecipeCount: 2},
+	}, tags)
+	recipes, err := recipeService.readAllRecipes(false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Vegan", recipes[0].Tags)
+}
+
+func TestMergeTagWithItself(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Tags: "Vegan"}))
+
+	// When
+	err := recipeService.mergeTags(1, 1)
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestGetTagSuggestions(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Tags: "Vegan, Vegetarisch, Suppe, Süß"}))
+
+	testCases := []struct {
+		value       string
+		suggestions []string
+	}{
+		{value: "", suggestions: []string{"Suppe", "Süß", "Vegan", "Vegetarisch"}},
+		{value: "ve", suggestions: []string{"Vegan", "Vegetarisch"}},
+		{value: "Suppe, VEGE", suggestions: []string{"Suppe, Vegetarisch"}},
+		{value: "Vegan,s", suggestions: []string{"Vegan, Suppe", "Vegan, Süß"}},
+		{value: "Vegan, ", suggestions: []string{"Vegan, Suppe", "Vegan, Süß", "Vegan, Vegetarisch"}},
+		{value: "Kuchen", suggestions: []string{}},
+	}
+
+	for _, test := range testCases {
+		// When
+		suggestions, err := recipeService.getTagSuggestions(test.value, false)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, test.suggestions, suggestions, test.value)
+	}
+}
+
+func TestGetTagSuggestionsHidesUnpublishedTags(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Tags: "Suppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Tags: "Suppe, Geheim", Pending: true}))
+	trashed := types.Recipe{Tags: "Gelöscht"}
+	assert.NoError(t, recipeService.createRecipe(&trashed))
+	assert.NoError(t, recipeService.trashRecipe(trashed.ID))
+
+	// When
+	suggestions, err := recipeService.getTagSuggestions("", false)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Suppe"}, suggestions)
+
+	// When
+	suggestions, err = recipeService.getTagSuggestions("", true)
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Geheim", "Gelöscht", "Suppe"}, suggestions)
+}
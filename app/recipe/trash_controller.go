@@ -0,0 +1,84 @@
+package recipe
+
+import (
+	"github.com/kilianmandscharo/lethimcook/components"
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/render"
+	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/labstack/echo/v4"
+)
+
+func (rc *RecipeController) RenderTrashPage(c echo.Context) error {
+	if !servutil.IsAuthorized(c) {
+		return rc.renderer.RenderError(
+			c,
+			servutil.NewAppErrorNotAllowed(c, "RenderTrashPage()"),
+		)
+	}
+	return rc.renderTrashPage(c, "")
+}
+
+func (rc *RecipeController) renderTrashPage(c echo.Context, message string) error {
+	trash, err := rc.recipeService.readTrash()
+	if err != nil {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at renderTrashPage()"),
+		)
+	}
+	return rc.renderer.RenderComponent(render.RenderComponentOptions{
+		Context:   c,
+		Component: components.TrashPage(true, trash, int(rc.recipeService.getTrashRetention().Hours()/24)),
+		Message:   message,
+	})
+}
+
+func (rc *RecipeController) HandleRestoreTrashedRecipe(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleRestoreTrashedRecipe()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleRestoreTrashedRecipe()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.restoreRecipe(id); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Info("restored recipe from the trash", id)
+	return rc.renderTrashPage(c, "Rezept wiederhergestellt")
+}
+
+func (rc *RecipeController) HandleDeleteTrashedRecipe(c echo.Context) error {
+	createError := func(err error) error {
+		return rc.renderer.RenderError(
+			c,
+			errutil.AddMessageToAppError(err, "failed at HandleDeleteTrashedRecipe()"),
+		)
+	}
+
+	if !servutil.IsAuthorized(c) {
+		return createError(servutil.NewAppErrorNotAllowed(c, "HandleDeleteTrashedRecipe()"))
+	}
+
+	id, err := rc.recipeService.getPathId(c)
+	if err != nil {
+		return createError(err)
+	}
+
+	if err := rc.recipeService.deleteTrashedRecipe(id); err != nil {
+		return createError(err)
+	}
+
+	rc.logger.Info("permanently deleted recipe", id)
+	return rc.renderTrashPage(c, "Rezept endgültig gelöscht")
+}
@@ -0,0 +1,188 @@
+package recipe
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTrashPage(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeController.recipeService.trashRecipe(1))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc: recipeController.RenderTrashPage,
+				Method:      http.MethodGet,
+				Route:       "/admin/trash",
+				StatusWant:  http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("authorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:   recipeController.RenderTrashPage,
+				Method:        http.MethodGet,
+				Route:         "/admin/trash",
+				StatusWant:    http.StatusOK,
+				Authorized:    true,
+				AssertMessage: true,
+				MessageWant:   "Linsensuppe",
+			},
+		)
+	})
+}
+
+func TestHandleRestoreTrashedRecipe(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeController.recipeService.trashRecipe(1))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRestoreTrashedRecipe,
+				Method:         http.MethodPost,
+				Route:          "/admin/trash/:id/restore",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRestoreTrashedRecipe,
+				Method:         http.MethodPost,
+				Route:          "/admin/trash/:id/restore",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusForbidden,
+				Role:           types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("not in the trash", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRestoreTrashedRecipe,
+				Method:         http.MethodPost,
+				Route:          "/admin/trash/:id/restore",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				StatusWant:     http.StatusNotFound,
+				Authorized:     true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleRestoreTrashedRecipe,
+				Method:         http.MethodPost,
+				Route:          "/admin/trash/:id/restore",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+				AssertMessage:  true,
+				MessageWant:    "Rezept wiederhergestellt",
+			},
+		)
+		_, err := recipeController.recipeService.readRecipe(1)
+		assert.NoError(t, err)
+	})
+}
+
+func TestHandleDeleteTrashedRecipe(t *testing.T) {
+	recipeController := newTestRecipeController()
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeController.recipeService.createRecipe(&types.Recipe{Title: "Tomatensuppe"}))
+	assert.NoError(t, recipeController.recipeService.trashRecipe(1))
+
+	t.Run("unauthorized", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTrashedRecipe,
+				Method:         http.MethodDelete,
+				Route:          "/admin/trash/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusUnauthorized,
+			},
+		)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTrashedRecipe,
+				Method:         http.MethodDelete,
+				Route:          "/admin/trash/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusForbidden,
+				Role:           types.RoleEditor,
+			},
+		)
+	})
+
+	t.Run("not in the trash", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTrashedRecipe,
+				Method:         http.MethodDelete,
+				Route:          "/admin/trash/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "2",
+				StatusWant:     http.StatusNotFound,
+				Authorized:     true,
+			},
+		)
+	})
+
+	t.Run("valid request", func(t *testing.T) {
+		testutil.AssertRequest(
+			t,
+			testutil.RequestOptions{
+				HandlerFunc:    recipeController.HandleDeleteTrashedRecipe,
+				Method:         http.MethodDelete,
+				Route:          "/admin/trash/:id",
+				WithPathParam:  true,
+				PathParamName:  "id",
+				PathParamValue: "1",
+				StatusWant:     http.StatusOK,
+				Authorized:     true,
+				AssertMessage:  true,
+				MessageWant:    "Rezept endgültig gelöscht",
+			},
+		)
+	})
+}
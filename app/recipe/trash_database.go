@@ -0,0 +1,146 @@
+package recipe
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"gorm.io/gorm"
+)
+
+// Recipes in the trash are soft deleted through Recipe.DeletedAt, so gorm
+// leaves them out of every query that is not Unscoped. They keep their tags
+// and revisions but are removed from the search index until restored.
+
+func newTrashNotFoundError(functionName string, id uint) error {
+	return &errutil.AppError{
+		UserMessage: "Rezept nicht im Papierkorb gefunden",
+		Err: fmt.Errorf(
+			"failed at %s, recipe with id %d not in the trash",
+			functionName,
+			id,
+		),
+		StatusCode: http.StatusNotFound,
+	}
+}
+
+func newTrashDatabaseError(functionName string, err error) error {
+	return &errutil.AppError{
+		UserMessage: "Datenbankfehler",
+		Err: fmt.Errorf(
+			"failed at %s, database failure: %w",
+			functionName,
+			err,
+		),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
+
+func (db *recipeDatabase) trashRecipe(id uint, deletedAt time.Time) error {
+	var result *gorm.DB
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		result = tx.Model(&types.Recipe{}).
+			Where("id = ?", id).
+			Update("deleted_at", deletedAt.Local())
+		if result.Error != nil {
+			return result.Error
+		}
+		return db.removeRecipeFromIndex(tx, id)
+	})
+	if err != nil {
+		return newTrashDatabaseError(fmt.Sprintf("trashRecipe() with id %d", id), err)
+	}
+	if result.RowsAffected == 0 {
+		return &errutil.AppError{
+			UserMessage: "Rezept nicht gefunden",
+			Err: fmt.Errorf(
+				"failed at trashRecipe(), recipe with id %d not found",
+				id,
+			),
+			StatusCode: http.StatusNotFound,
+		}
+	}
+	return nil
+}
+
+func (db *recipeDatabase) restoreRecipe(id uint) error {
+	var result *gorm.DB
+	err := db.handler.Transaction(func(tx *gorm.DB) error {
+		result = tx.Unscoped().
+			Model(&types.Recipe{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		var recipe types.Recipe
+		if err := tx.First(&recipe, id).Error; err != nil {
+			return err
+		}
+		return db.indexRecipe(tx, &recipe)
+	})
+	if err != nil {
+		return newTrashDatabaseError(fmt.Sprintf("restoreRecipe() with id %d", id), err)
+	}
+	if result.RowsAffected == 0 {
+		return newTrashNotFoundError("restoreRecipe()", id)
+	}
+	return nil
+}
+
+func (db *recipeDatabase) readTrashedRecipe(id uint) (types.Recipe, error) {
+	var recipe types.Recipe
+	err := db.handler.Unscoped().Where("deleted_at IS NOT NULL").First(&recipe, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return recipe, newTrashNotFoundError("readTrashedRecipe()", id)
+		}
+		return recipe, newTrashDatabaseError(fmt.Sprintf("readTrashedRecipe() with id %d", id), err)
+	}
+	return recipe, nil
+}
+
+// readRecipeWithTrashed reads the recipe no matter if it is in the trash.
+func (db *recipeDatabase) readRecipeWithTrashed(id uint) (types.Recipe, error) {
+	var recipe types.Recipe
+	if err := db.handler.Unscoped().First(&recipe, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return recipe, &errutil.AppError{
+				UserMessage: "Rezept nicht gefunden",
+				Err: fmt.Errorf(
+					"failed at readRecipeWithTrashed(), recipe with id %d not found",
+					id,
+				),
+				StatusCode: http.StatusNotFound,
+			}
+		}
+		return recipe, newTrashDatabaseError(fmt.Sprintf("readRecipeWithTrashed() with id %d", id), err)
+	}
+	return recipe, nil
+}
+
+// readTrashedRecipes returns the recipes in the trash, the most recently
+// deleted first.
+func (db *recipeDatabase) readTrashedRecipes() ([]types.Recipe, error) {
+	var recipes []types.Recipe
+	err := db.handler.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&recipes).Error
+	if err != nil {
+		return recipes, newTrashDatabaseError("readTrashedRecipes()", err)
+	}
+	return recipes, nil
+}
+
+func (db *recipeDatabase) readRecipeIdsTrashedBefore(deadline time.Time) ([]uint, error) {
+	var ids []uint
+	err := db.handler.Unscoped().
+		Model(&types.Recipe{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", deadline).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return ids, newTrashDatabaseError("readRecipeIdsTrashedBefore()", err)
+	}
+	return ids, nil
+}
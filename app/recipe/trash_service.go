@@ -0,0 +1,125 @@
+package recipe
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	trashPurgeInterval        = 24 * time.Hour
+)
+
+// parseTrashRetention reads the number of days recipes stay in the trash,
+// falling back to the default for missing or invalid values.
+func (rs *recipeService) parseTrashRetention(value string) time.Duration {
+	days := defaultTrashRetentionDays
+	if len(value) > 0 {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			rs.logger.Warnf("invalid trash retention %q, using %d days", value, defaultTrashRetentionDays)
+		} else {
+			days = parsed
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func (rs *recipeService) getTrashRetention() time.Duration {
+	if rs.trashRetention == 0 {
+		return defaultTrashRetentionDays * 24 * time.Hour
+	}
+	return rs.trashRetention
+}
+
+// trashRecipe moves the recipe to the trash, from where it can be restored
+// until it is purged.
+func (rs *recipeService) trashRecipe(id uint) error {
+	return rs.trashRecipeAt(id, time.Now())
+}
+
+// trashRecipeAt moves the recipe to the trash as if it was deleted at
+// deletedAt, so that a restored backup keeps the purge dates of its trash.
+func (rs *recipeService) trashRecipeAt(id uint, deletedAt time.Time) error {
+	rs.recipeCache.Invalidate()
+	return rs.db.trashRecipe(id, deletedAt)
+}
+
+func (rs *recipeService) restoreRecipe(id uint) error {
+	rs.recipeCache.Invalidate()
+	return rs.db.restoreRecipe(id)
+}
+
+func (rs *recipeService) readTrash() ([]types.TrashedRecipe, error) {
+	trash := []types.TrashedRecipe{}
+
+	recipes, err := rs.db.readTrashedRecipes()
+	if err != nil {
+		return trash, errutil.AddMessageToAppError(err, "failed at readTrash()")
+	}
+
+	for _, recipe := range recipes {
+		deletedAt := recipe.DeletedAt.Time.Local()
+		trash = append(trash, types.TrashedRecipe{
+			ID:              recipe.ID,
+			Title:           recipe.Title,
+			DeletedAt:       deletedAt.Format("02.01.2006 15:04"),
+			PurgeAt:         deletedAt.Add(rs.getTrashRetention()).Format("02.01.2006"),
+			RejectionReason: recipe.RejectionReason,
+		})
+	}
+	return trash, nil
+}
+
+// deleteTrashedRecipe permanently deletes a recipe from the trash.
+func (rs *recipeService) deleteTrashedRecipe(id uint) error {
+	if _, err := rs.db.readTrashedRecipe(id); err != nil {
+		return errutil.AddMessageToAppError(err, "failed at deleteTrashedRecipe()")
+	}
+	return rs.deleteRecipe(id)
+}
+
+// purgeTrash permanently deletes the recipes that have been in the trash for
+// longer than the retention period and returns how many were deleted.
+func (rs *recipeService) purgeTrash(now time.Time) (int, error) {
+	ids, err := rs.db.readRecipeIdsTrashedBefore(now.Add(-rs.getTrashRetention()))
+	if err != nil {
+		return 0, errutil.AddMessageToAppError(err, "failed at purgeTrash()")
+	}
+	for i, id := range ids {
+		if err := rs.deleteRecipe(id); err != nil {
+			return i, errutil.AddMessageToAppError(err, "failed at purgeTrash()")
+		}
+	}
+	return len(ids), nil
+}
+
+// StartTrashPurge purges the trash and the unused images now and then once a
+// day in the background.
+func (rs *recipeService) StartTrashPurge() {
+	purge := func() {
+		purged, err := rs.purgeTrash(time.Now())
+		if err != nil {
+			rs.logger.Error(err)
+		}
+		if purged > 0 {
+			rs.logger.Infof("purged %d recipes from the trash", purged)
+		}
+		purged, err = rs.purgeUnusedImages(time.Now())
+		if err != nil {
+			rs.logger.Error(err)
+		}
+		if purged > 0 {
+			rs.logger.Infof("purged %d unused images", purged)
+		}
+	}
+	purge()
+	go func() {
+		for range time.Tick(trashPurgeInterval) {
+			purge()
+		}
+	}()
+}
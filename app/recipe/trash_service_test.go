@@ -0,0 +1,160 @@
+package recipe
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashAndRestoreRecipe(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe", Tags: "Suppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Tomatensuppe", Tags: "Suppe"}))
+
+	// When
+	err := recipeService.trashRecipe(1)
+
+	// Then
+	assert.NoError(t, err)
+	_, err = recipeService.readRecipe(1)
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+	recipes, err := recipeService.readAllRecipes(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(recipes))
+	tags, err := recipeService.readTags()
+	assert.NoError(t, err)
+	assert.Equal(t, []types.TagUsage{{ID: 1, Name: "Suppe", RecipeCount: 1}}, tags)
+	trash, err := recipeService.readTrash()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(trash))
+	assert.Equal(t, "Linsensuppe", trash[0].Title)
+
+	// When
+	err = recipeService.trashRecipe(1)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = recipeService.restoreRecipe(2)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	err = recipeService.restoreRecipe(1)
+
+	// Then
+	assert.NoError(t, err)
+	recipe, err := recipeService.readRecipe(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Linsensuppe", recipe.Title)
+	trash, err = recipeService.readTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+}
+
+func TestDeleteTrashedRecipe(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+
+	// When
+	err := recipeService.deleteTrashedRecipe(1)
+
+	// Then
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+
+	// When
+	assert.NoError(t, recipeService.trashRecipe(1))
+	err = recipeService.deleteTrashedRecipe(1)
+
+	// Then
+	assert.NoError(t, err)
+	trash, err := recipeService.readTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+	err = recipeService.restoreRecipe(1)
+	assert.Equal(t, http.StatusNotFound, errutil.GetAppErrorStatusCode(err))
+}
+
+func TestPurgeTrash(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipeService.trashRetention = 7 * 24 * time.Hour
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Linsensuppe"}))
+	assert.NoError(t, recipeService.createRecipe(&types.Recipe{Title: "Tomatensuppe"}))
+	assert.NoError(t, recipeService.trashRecipe(1))
+
+	// When
+	purged, err := recipeService.purgeTrash(time.Now().Add(6 * 24 * time.Hour))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	// When
+	purged, err = recipeService.purgeTrash(time.Now().Add(8 * 24 * time.Hour))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	trash, err := recipeService.readTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+	_, err = recipeService.readRecipe(2)
+	assert.NoError(t, err)
+}
+
+// The purge runs in the background while requests read the recipes, run
+// with go test -race
+func TestPurgeTrashWhileReading(t *testing.T) {
+	// Given
+	recipeService := newTestRecipeService()
+	recipeService.trashRetention = 7 * 24 * time.Hour
+	for i := 0; i < 10; i++ {
+		recipe := types.Recipe{Title: fmt.Sprintf("Rezept %d", i)}
+		assert.NoError(t, recipeService.createRecipe(&recipe))
+		if i%2 == 0 {
+			assert.NoError(t, recipeService.trashRecipe(recipe.ID))
+		}
+	}
+
+	// When
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		purged, err := recipeService.purgeTrash(time.Now().Add(8 * 24 * time.Hour))
+		assert.NoError(t, err)
+		assert.Equal(t, 5, purged)
+	}()
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+			_, err := recipeService.readAllRecipes(true)
+			assert.NoError(t, err)
+		}
+	}
+
+	// Then
+	recipes, err := recipeService.readAllRecipes(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(recipes))
+}
+
+func TestParseTrashRetention(t *testing.T) {
+	recipeService := newTestRecipeService()
+
+	assert.Equal(t, 30*24*time.Hour, recipeService.parseTrashRetention(""))
+	assert.Equal(t, 30*24*time.Hour, recipeService.parseTrashRetention("abc"))
+	assert.Equal(t, 30*24*time.Hour, recipeService.parseTrashRetention("0"))
+	assert.Equal(t, 14*24*time.Hour, recipeService.parseTrashRetention("14"))
+}
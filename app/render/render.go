@@ -6,6 +6,7 @@ import (
 	"github.com/kilianmandscharo/lethimcook/errutil"
 	"github.com/kilianmandscharo/lethimcook/logging"
 	"github.com/kilianmandscharo/lethimcook/servutil"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/labstack/echo/v4"
 )
 
@@ -39,6 +40,7 @@ type RenderComponentOptions struct {
 	Message       string
 	Err           error
 	OnlyComponent bool
+	Meta          types.PageMeta
 }
 
 func (r *Renderer) RenderComponent(options RenderComponentOptions) error {
@@ -56,13 +58,19 @@ func (r *Renderer) RenderComponent(options RenderComponentOptions) error {
 	message := getRenderComponentOptionsMessage(options)
 	component := getRenderComponentOptionsComponent(options, message)
 
-	if servutil.IsHxRequest(options.Context) || options.OnlyComponent {
+	if options.OnlyComponent {
 		return component.Render(
 			options.Context.Request().Context(),
 			options.Context.Response().Writer,
 		)
 	}
-	return components.Page(component).Render(
+	if servutil.IsHxRequest(options.Context) {
+		return components.Joiner(components.Title(options.Meta), component).Render(
+			options.Context.Request().Context(),
+			options.Context.Response().Writer,
+		)
+	}
+	return components.Page(component, options.Meta).Render(
 		options.Context.Request().Context(),
 		options.Context.Response().Writer,
 	)
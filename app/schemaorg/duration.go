@@ -0,0 +1,53 @@
+package schemaorg
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var durationPattern = regexp.MustCompile(
+	`^P(?:(\d+(?:[.,]\d+)?)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`,
+)
+
+// ParseDuration converts an ISO-8601 duration like "PT1H30M" or "P0DT45M"
+// into minutes, rounded to the nearest minute.
+func ParseDuration(value string) (int, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	match := durationPattern.FindStringSubmatch(value)
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q", value)
+	}
+
+	minutes := 0.0
+	for i, factor := range []float64{24 * 60, 60, 1, 1.0 / 60} {
+		if len(match[i+1]) == 0 {
+			continue
+		}
+		number, err := strconv.ParseFloat(strings.Replace(match[i+1], ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q: %w", value, err)
+		}
+		minutes += number * factor
+	}
+
+	return int(math.Round(minutes)), nil
+}
+
+// FormatDuration converts minutes into an ISO-8601 duration like "PT1H30M".
+func FormatDuration(minutes int) string {
+	if minutes <= 0 {
+		return "PT0M"
+	}
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("PT%dM", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("PT%dH", hours)
+	default:
+		return fmt.Sprintf("PT%dH%dM", hours, minutes)
+	}
+}
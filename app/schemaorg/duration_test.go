@@ -0,0 +1,45 @@
+package schemaorg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDuration(t *testing.T) {
+	testCases := []struct {
+		value     string
+		minutes   int
+		expectErr bool
+	}{
+		{value: "PT30M", minutes: 30},
+		{value: "PT1H30M", minutes: 90},
+		{value: "PT2H", minutes: 120},
+		{value: "P0DT0H45M", minutes: 45},
+		{value: "P1D", minutes: 1440},
+		{value: "PT90S", minutes: 2},
+		{value: "PT0.5H", minutes: 30},
+		{value: "pt15m", minutes: 15},
+		{value: "P", expectErr: true},
+		{value: "PT", expectErr: true},
+		{value: "30 Minuten", expectErr: true},
+		{value: "", expectErr: true},
+	}
+
+	for _, test := range testCases {
+		minutes, err := ParseDuration(test.value)
+		if test.expectErr {
+			assert.Error(t, err, test.value)
+		} else {
+			assert.NoError(t, err, test.value)
+			assert.Equal(t, test.minutes, minutes, test.value)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	assert.Equal(t, "PT0M", FormatDuration(0))
+	assert.Equal(t, "PT45M", FormatDuration(45))
+	assert.Equal(t, "PT2H", FormatDuration(120))
+	assert.Equal(t, "PT1H30M", FormatDuration(90))
+}
@@ -0,0 +1,160 @@
+package schemaorg
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+)
+
+var (
+	listItemPattern     = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.*)$`)
+	headingPattern      = regexp.MustCompile(`^\s*#{1,6}\s+(.+?)\s*#*\s*$`)
+	markdownLinkPattern = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	emphasisPattern     = regexp.MustCompile(`\*\*|__|\x60`)
+)
+
+type Person struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+type HowToStep struct {
+	Type string `json:"@type"`
+	Text string `json:"text"`
+}
+
+type HowToSection struct {
+	Type            string      `json:"@type"`
+	Name            string      `json:"name"`
+	ItemListElement []HowToStep `json:"itemListElement"`
+}
+
+// Recipe is the JSON-LD representation of a recipe as described by
+// https://schema.org/Recipe.
+type Recipe struct {
+	Context            string   `json:"@context"`
+	Type               string   `json:"@type"`
+	Name               string   `json:"name"`
+	Description        string   `json:"description,omitempty"`
+	Url                string   `json:"url,omitempty"`
+	Image              []string `json:"image,omitempty"`
+	Author             *Person  `json:"author,omitempty"`
+	IsBasedOn          string   `json:"isBasedOn,omitempty"`
+	DatePublished      string   `json:"datePublished,omitempty"`
+	DateModified       string   `json:"dateModified,omitempty"`
+	PrepTime           string   `json:"prepTime,omitempty"`
+	CookTime           string   `json:"cookTime,omitempty"`
+	TotalTime          string   `json:"totalTime,omitempty"`
+	RecipeYield        string   `json:"recipeYield,omitempty"`
+	Keywords           string   `json:"keywords,omitempty"`
+	RecipeIngredient   []string `json:"recipeIngredient"`
+	RecipeInstructions []any    `json:"recipeInstructions"`
+}
+
+// NewRecipe maps a recipe with Markdown ingredients and instructions to its
+// JSON-LD representation. url is the absolute URL of the recipe page.
+func NewRecipe(recipe types.Recipe, url string) Recipe {
+	jsonLd := Recipe{
+		Context:            "https://schema.org",
+		Type:               "Recipe",
+		Name:               recipe.Title,
+		Description:        recipe.Description,
+		Url:                url,
+		IsBasedOn:          recipe.Source,
+		DatePublished:      formatDate(recipe.CreatedAt),
+		DateModified:       formatDate(recipe.LastModifiedAt),
+		Keywords:           strings.Join(recipe.ParseTags(), ", "),
+		RecipeIngredient:   getIngredientLines(recipe.Ingredients),
+		RecipeInstructions: getInstructionSteps(recipe.Instructions),
+	}
+
+	if len(recipe.Author) > 0 {
+		jsonLd.Author = &Person{Type: "Person", Name: recipe.Author}
+	}
+	if recipe.Servings > 0 {
+		jsonLd.RecipeYield = strconv.Itoa(recipe.Servings)
+	}
+
+	totalDuration := recipe.GetTotalDuration()
+	if recipe.Duration > 0 {
+		jsonLd.CookTime = FormatDuration(recipe.Duration)
+	}
+	if totalDuration > 0 {
+		jsonLd.TotalTime = FormatDuration(totalDuration)
+	}
+	if totalDuration > recipe.Duration && recipe.Duration > 0 {
+		jsonLd.PrepTime = FormatDuration(totalDuration - recipe.Duration)
+	}
+
+	return jsonLd
+}
+
+func formatDate(value string) string {
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return ""
+	}
+	return date.Format(time.DateOnly)
+}
+
+// cleanMarkdown reduces a line of Markdown to plain text.
+func cleanMarkdown(text string) string {
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	return strings.TrimSpace(emphasisPattern.ReplaceAllString(text, ""))
+}
+
+func getIngredientLines(markdown string) []string {
+	lines := []string{}
+	for _, line := range strings.Split(markdown, "\n") {
+		if match := listItemPattern.FindStringSubmatch(line); match != nil {
+			if text := cleanMarkdown(match[1]); len(text) > 0 {
+				lines = append(lines, text)
+			}
+		}
+	}
+	return lines
+}
+
+// getInstructionSteps turns every list item or paragraph line of the
+// instructions into a HowToStep. Headings start a new HowToSection.
+func getInstructionSteps(markdown string) []any {
+	steps := []any{}
+	var section *HowToSection
+
+	for _, line := range strings.Split(markdown, "\n") {
+		if match := headingPattern.FindStringSubmatch(line); match != nil {
+			if section != nil {
+				steps = append(steps, *section)
+			}
+			section = &HowToSection{
+				Type:            "HowToSection",
+				Name:            cleanMarkdown(match[1]),
+				ItemListElement: []HowToStep{},
+			}
+			continue
+		}
+
+		if match := listItemPattern.FindStringSubmatch(line); match != nil {
+			line = match[1]
+		}
+		text := cleanMarkdown(line)
+		if len(text) == 0 {
+			continue
+		}
+
+		step := HowToStep{Type: "HowToStep", Text: text}
+		if section != nil {
+			section.ItemListElement = append(section.ItemListElement, step)
+		} else {
+			steps = append(steps, step)
+		}
+	}
+
+	if section != nil {
+		steps = append(steps, *section)
+	}
+	return steps
+}
@@ -0,0 +1,97 @@
+package schemaorg
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRecipe(t *testing.T) {
+	// Given
+	recipe := types.Recipe{
+		ID:             3,
+		Title:          "Käsespätzle",
+		Description:    "Ein Klassiker",
+		Author:         "Anna",
+		Source:         "https://example.com/original",
+		Duration:       40,
+		TotalDuration:  75,
+		Servings:       4,
+		Tags:           "Pasta, Käse",
+		CreatedAt:      "2024-01-08T12:00:00+01:00",
+		LastModifiedAt: "2024-02-01T08:30:00+01:00",
+		Ingredients:    "## Teig\n\n- 500 g **Mehl**\n- 5 [Eier](/recipe/2)\n\nNotiz",
+		Instructions:   "1. Teig rühren.\n2. Ruhen lassen.\n\n### Käse\n\n- Schichten.",
+	}
+
+	// When
+	jsonLd := NewRecipe(recipe, "https://lethimcook.de/recipe/3")
+
+	// Then
+	assert.Equal(t, "https://schema.org", jsonLd.Context)
+	assert.Equal(t, "Recipe", jsonLd.Type)
+	assert.Equal(t, "Käsespätzle", jsonLd.Name)
+	assert.Equal(t, "https://lethimcook.de/recipe/3", jsonLd.Url)
+	assert.Equal(t, &Person{Type: "Person", Name: "Anna"}, jsonLd.Author)
+	assert.Equal(t, "https://example.com/original", jsonLd.IsBasedOn)
+	assert.Equal(t, "2024-01-08", jsonLd.DatePublished)
+	assert.Equal(t, "2024-02-01", jsonLd.DateModified)
+	assert.Equal(t, "PT40M", jsonLd.CookTime)
+	assert.Equal(t, "PT35M", jsonLd.PrepTime)
+	assert.Equal(t, "PT1H15M", jsonLd.TotalTime)
+	assert.Equal(t, "4", jsonLd.RecipeYield)
+	assert.Equal(t, "Pasta, Käse", jsonLd.Keywords)
+	assert.Equal(t, []string{"500 g Mehl", "5 Eier"}, jsonLd.RecipeIngredient)
+	assert.Equal(t, []any{
+		HowToStep{Type: "HowToStep", Text: "Teig rühren."},
+		HowToStep{Type: "HowToStep", Text: "Ruhen lassen."},
+		HowToSection{
+			Type:            "HowToSection",
+			Name:            "Käse",
+			ItemListElement: []HowToStep{{Type: "HowToStep", Text: "Schichten."}},
+		},
+	}, jsonLd.RecipeInstructions)
+}
+
+func TestNewRecipeEmpty(t *testing.T) {
+	// When
+	data, err := json.Marshal(NewRecipe(types.Recipe{Title: "</script>"}, ""))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"@context":"https://schema.org","@type":"Recipe","name":"\u003c/script\u003e","recipeIngredient":[],"recipeInstructions":[]}`,
+		string(data),
+	)
+}
+
+func TestNewRecipeRoundTrip(t *testing.T) {
+	// Given
+	recipe := types.Recipe{
+		Title:         "Pfannkuchen",
+		Description:   "Süß & schnell",
+		Author:        "Oma",
+		Duration:      15,
+		TotalDuration: 25,
+		Servings:      2,
+		Tags:          "Süß, Schnell",
+		Ingredients:   "- 2 Eier\n- 250 ml Milch",
+		Instructions:  "1. Alles verrühren.\n2. In der Pfanne backen.",
+	}
+	data, err := json.Marshal(NewRecipe(recipe, "https://lethimcook.de/recipe/1"))
+	assert.NoError(t, err)
+
+	// When
+	parsed, err := ParseRecipe(strings.NewReader(
+		`<script type="application/ld+json">` + string(data) + `</script>`,
+	))
+
+	// Then
+	assert.NoError(t, err)
+	recipe.Source = "https://lethimcook.de/recipe/1"
+	assert.Equal(t, recipe, parsed)
+}
@@ -0,0 +1,421 @@
+package schemaorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kilianmandscharo/lethimcook/types"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	ErrNoRecipe = errors.New("no schema.org Recipe found")
+
+	whitespacePattern = regexp.MustCompile(`[ \t\f\v\x{00a0}]+`)
+	numberPattern     = regexp.MustCompile(`\d+`)
+)
+
+// page holds the parts of an HTML page that are needed to import a recipe.
+type page struct {
+	jsonLd    []string
+	canonical string
+	ogUrl     string
+}
+
+// ParseRecipe extracts the first schema.org Recipe from the JSON-LD of an HTML
+// page and maps it to a recipe. The source of the recipe is the canonical URL
+// of the page. Input that starts with "{" or "[" is read as JSON-LD directly,
+// so that the script content can be pasted on its own.
+func ParseRecipe(r io.Reader) (types.Recipe, error) {
+	var recipe types.Recipe
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return recipe, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	var p page
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		p.jsonLd = []string{string(trimmed)}
+	} else {
+		node, err := html.Parse(bytes.NewReader(data))
+		if err != nil {
+			return recipe, fmt.Errorf("failed to parse HTML: %w", err)
+		}
+		collectPage(node, &p)
+	}
+
+	var node map[string]any
+	for _, script := range p.jsonLd {
+		var value any
+		if err := json.Unmarshal([]byte(cleanJson(script)), &value); err != nil {
+			continue
+		}
+		if node = findRecipe(value); node != nil {
+			break
+		}
+	}
+	if node == nil {
+		return recipe, ErrNoRecipe
+	}
+
+	recipe = mapRecipe(node)
+
+	switch {
+	case len(p.canonical) > 0:
+		recipe.Source = p.canonical
+	case len(p.ogUrl) > 0:
+		recipe.Source = p.ogUrl
+	}
+
+	return recipe, nil
+}
+
+func collectPage(node *html.Node, p *page) {
+	if node.Type == html.ElementNode {
+		switch node.DataAtom {
+		case atom.Script:
+			if strings.EqualFold(strings.TrimSpace(getAttribute(node, "type")), "application/ld+json") {
+				var builder strings.Builder
+				for child := node.FirstChild; child != nil; child = child.NextSibling {
+					if child.Type == html.TextNode {
+						builder.WriteString(child.Data)
+					}
+				}
+				p.jsonLd = append(p.jsonLd, builder.String())
+			}
+		case atom.Link:
+			if len(p.canonical) == 0 && hasToken(getAttribute(node, "rel"), "canonical") {
+				p.canonical = strings.TrimSpace(getAttribute(node, "href"))
+			}
+		case atom.Meta:
+			if len(p.ogUrl) == 0 && getAttribute(node, "property") == "og:url" {
+				p.ogUrl = strings.TrimSpace(getAttribute(node, "content"))
+			}
+		}
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		collectPage(child, p)
+	}
+}
+
+func getAttribute(node *html.Node, key string) string {
+	for _, attribute := range node.Attr {
+		if strings.EqualFold(attribute.Key, key) {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanJson removes the HTML comment and CDATA wrappers some sites put around
+// the content of their script tags.
+func cleanJson(script string) string {
+	script = strings.TrimSpace(script)
+	for _, wrapper := range [][2]string{{"<!--", "-->"}, {"<![CDATA[", "]]>"}, {"//<![CDATA[", "//]]>"}} {
+		if strings.HasPrefix(script, wrapper[0]) && strings.HasSuffix(script, wrapper[1]) {
+			script = strings.TrimSpace(script[len(wrapper[0]) : len(script)-len(wrapper[1])])
+		}
+	}
+	return script
+}
+
+// findRecipe searches the JSON-LD value for a node of the type Recipe. The
+// node can be the value itself, an element of an array or of an @graph, or
+// the main entity of a web page.
+func findRecipe(value any) map[string]any {
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			if node := findRecipe(element); node != nil {
+				return node
+			}
+		}
+	case map[string]any:
+		if isType(v, "Recipe") {
+			return v
+		}
+		for _, key := range []string{"@graph", "mainEntity", "mainEntityOfPage"} {
+			if node := findRecipe(v[key]); node != nil {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+func isType(node map[string]any, name string) bool {
+	switch t := node["@type"].(type) {
+	case string:
+		return t == name || t == "https://schema.org/"+name || t == "http://schema.org/"+name
+	case []any:
+		for _, element := range t {
+			if s, ok := element.(string); ok && isType(map[string]any{"@type": s}, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func mapRecipe(node map[string]any) types.Recipe {
+	recipe := types.Recipe{
+		Title:        getLine(node["name"]),
+		Description:  getLine(node["description"]),
+		Author:       getAuthor(node["author"]),
+		Source:       getText(node["url"]),
+		Servings:     getServings(node["recipeYield"]),
+		Ingredients:  getIngredients(node),
+		Instructions: getInstructions(node["recipeInstructions"]),
+		Tags:         strings.Join(getKeywords(node["keywords"]), ", "),
+	}
+	if len(recipe.Title) == 0 {
+		recipe.Title = getLine(node["headline"])
+	}
+
+	cookTime := getDuration(node["cookTime"])
+	prepTime := getDuration(node["prepTime"])
+	totalTime := getDuration(node["totalTime"])
+	if totalTime == 0 {
+		totalTime = prepTime + cookTime
+	}
+	recipe.Duration = cookTime
+	recipe.TotalDuration = totalTime
+
+	return recipe
+}
+
+// getText returns the text of a JSON-LD value with HTML tags removed and
+// entities decoded. Arrays yield their first text, objects their name, text
+// or @value.
+func getText(value any) string {
+	switch v := value.(type) {
+	case string:
+		return cleanText(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		for _, element := range v {
+			if text := getText(element); len(text) > 0 {
+				return text
+			}
+		}
+	case map[string]any:
+		for _, key := range []string{"name", "text", "@value"} {
+			if text := getText(v[key]); len(text) > 0 {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// getLine returns the text of a value for single line fields like the title.
+func getLine(value any) string {
+	return strings.ReplaceAll(getText(value), "\n", " ")
+}
+
+// cleanText strips HTML from text and normalizes whitespace while keeping
+// line breaks.
+func cleanText(text string) string {
+	if strings.ContainsAny(text, "<&") {
+		text = stripTags(text)
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(whitespacePattern.ReplaceAllString(line, " "))
+		if len(line) > 0 {
+			cleaned = append(cleaned, line)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+func stripTags(text string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(text), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return text
+	}
+
+	var builder strings.Builder
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		switch {
+		case node.Type == html.TextNode:
+			builder.WriteString(node.Data)
+		case node.Type == html.ElementNode && (node.DataAtom == atom.Br || node.DataAtom == atom.P || node.DataAtom == atom.Li):
+			builder.WriteString("\n")
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return builder.String()
+}
+
+func getAuthor(value any) string {
+	var names []string
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			if name := getText(element); len(name) > 0 {
+				names = append(names, name)
+			}
+		}
+	default:
+		if name := getText(v); len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// getServings returns the first number of the yield, e.g. 4 for "4 Portionen"
+// or ["4", "4 Portionen"].
+func getServings(value any) int {
+	servings, err := strconv.Atoi(numberPattern.FindString(getText(value)))
+	if err != nil {
+		return 0
+	}
+	return servings
+}
+
+func getDuration(value any) int {
+	minutes, err := ParseDuration(getText(value))
+	if err != nil {
+		return 0
+	}
+	return minutes
+}
+
+func getIngredients(node map[string]any) string {
+	value, ok := node["recipeIngredient"]
+	if !ok {
+		value = node["ingredients"]
+	}
+
+	var lines []string
+	for _, ingredient := range getTexts(value) {
+		lines = append(lines, "- "+ingredient)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// getTexts returns the texts of a list value. A single string is split into
+// its lines.
+func getTexts(value any) []string {
+	var texts []string
+	switch v := value.(type) {
+	case []any:
+		for _, element := range v {
+			if text := getLine(element); len(text) > 0 {
+				texts = append(texts, text)
+			}
+		}
+	default:
+		if text := getText(v); len(text) > 0 {
+			texts = strings.Split(text, "\n")
+		}
+	}
+	return texts
+}
+
+// getInstructions converts the instructions into a numbered Markdown list.
+// The instructions can be a text, a list of texts or HowToSteps, or a list
+// of HowToSections, whose names become headings.
+func getInstructions(value any) string {
+	var sections []string
+	var steps []string
+
+	addSection := func(name string) {
+		if len(steps) > 0 {
+			sections = append(sections, formatSteps(steps))
+			steps = nil
+		}
+		if len(name) > 0 {
+			sections = append(sections, "### "+name)
+		}
+	}
+
+	var collect func(value any)
+	collect = func(value any) {
+		switch v := value.(type) {
+		case string:
+			steps = append(steps, getTexts(v)...)
+		case []any:
+			for _, element := range v {
+				collect(element)
+			}
+		case map[string]any:
+			switch {
+			case isType(v, "HowToSection"):
+				addSection(getText(v["name"]))
+				collect(v["itemListElement"])
+				addSection("")
+			case isType(v, "ItemList"):
+				collect(v["itemListElement"])
+			default:
+				text := getText(v["text"])
+				if len(text) == 0 {
+					text = getText(v["name"])
+				}
+				if len(text) > 0 {
+					steps = append(steps, strings.ReplaceAll(text, "\n", " "))
+				}
+			}
+		}
+	}
+
+	collect(value)
+	addSection("")
+
+	return strings.Join(sections, "\n\n")
+}
+
+func formatSteps(steps []string) string {
+	lines := make([]string, len(steps))
+	for i, step := range steps {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, step)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func getKeywords(value any) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, text := range getTexts(value) {
+		for _, keyword := range strings.Split(text, ",") {
+			keyword = strings.TrimSpace(keyword)
+			if len(keyword) > 0 && !seen[strings.ToLower(keyword)] {
+				seen[strings.ToLower(keyword)] = true
+				keywords = append(keywords, keyword)
+			}
+		}
+	}
+	return keywords
+}
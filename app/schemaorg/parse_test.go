@@ -0,0 +1,157 @@
+package schemaorg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPage = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Käsespätzle</title>
+	<link rel="canonical" href="https://example.com/rezepte/kaesespaetzle">
+	<meta property="og:url" content="https://example.com/og">
+	<script type="application/ld+json">{"@context": "https://schema.org", "@type": "WebSite", "name": "Beispiel"}</script>
+	<script type="application/ld+json">
+	{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "BreadcrumbList"},
+			{
+				"@type": ["Recipe", "NewsArticle"],
+				"name": "Käsespätzle &amp; Zwiebeln",
+				"description": "<p>Ein <b>Klassiker</b></p>",
+				"url": "https://example.com/json-url",
+				"author": [{"@type": "Person", "name": "Anna"}, {"@type": "Person", "name": "Ben"}],
+				"recipeYield": ["4", "4 Portionen"],
+				"prepTime": "PT20M",
+				"cookTime": "PT40M",
+				"totalTime": "PT1H15M",
+				"keywords": "Pasta, Käse, pasta",
+				"recipeIngredient": ["500 g Spätzle", "200 g  Bergkäse"],
+				"recipeInstructions": [
+					{
+						"@type": "HowToSection",
+						"name": "Zwiebeln",
+						"itemListElement": [{"@type": "HowToStep", "text": "Zwiebeln schneiden."}]
+					},
+					{
+						"@type": "HowToSection",
+						"name": "Spätzle",
+						"itemListElement": [
+							{"@type": "HowToStep", "text": "Spätzle kochen."},
+							{"@type": "HowToStep", "name": "Mit Käse schichten."}
+						]
+					}
+				]
+			}
+		]
+	}
+	</script>
+</head>
+<body></body>
+</html>`
+
+func TestParseRecipe(t *testing.T) {
+	// When
+	recipe, err := ParseRecipe(strings.NewReader(testPage))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Käsespätzle & Zwiebeln", recipe.Title)
+	assert.Equal(t, "Ein Klassiker", recipe.Description)
+	assert.Equal(t, "Anna, Ben", recipe.Author)
+	assert.Equal(t, "https://example.com/rezepte/kaesespaetzle", recipe.Source)
+	assert.Equal(t, 4, recipe.Servings)
+	assert.Equal(t, 40, recipe.Duration)
+	assert.Equal(t, 75, recipe.TotalDuration)
+	assert.Equal(t, "Pasta, Käse", recipe.Tags)
+	assert.Equal(t, "- 500 g Spätzle\n- 200 g Bergkäse", recipe.Ingredients)
+	assert.Equal(
+		t,
+		"### Zwiebeln\n\n1. Zwiebeln schneiden.\n\n### Spätzle\n\n1. Spätzle kochen.\n2. Mit Käse schichten.",
+		recipe.Instructions,
+	)
+}
+
+func TestParseRecipeSource(t *testing.T) {
+	testCases := []struct {
+		head   string
+		source string
+	}{
+		{
+			head:   `<link rel="canonical" href="https://example.com/a"><meta property="og:url" content="https://example.com/b">`,
+			source: "https://example.com/a",
+		},
+		{
+			head:   `<meta property="og:url" content="https://example.com/b">`,
+			source: "https://example.com/b",
+		},
+		{
+			head:   "",
+			source: "https://example.com/c",
+		},
+	}
+
+	for _, test := range testCases {
+		// Given
+		page := test.head + `<script type="application/ld+json">
+			{"@type": "Recipe", "name": "Test", "url": "https://example.com/c"}
+		</script>`
+
+		// When
+		recipe, err := ParseRecipe(strings.NewReader(page))
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, test.source, recipe.Source)
+	}
+}
+
+func TestParseRecipeFromJson(t *testing.T) {
+	// Given
+	jsonLd := `[{
+		"@type": "Recipe",
+		"name": "Pfannkuchen",
+		"author": "Oma",
+		"recipeYield": 2,
+		"prepTime": "PT10M",
+		"cookTime": "PT15M",
+		"keywords": ["Süß", "Schnell"],
+		"recipeIngredient": "2 Eier\n250 ml Milch",
+		"recipeInstructions": "Alles verrühren.\nIn der Pfanne backen."
+	}]`
+
+	// When
+	recipe, err := ParseRecipe(strings.NewReader(jsonLd))
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, "Pfannkuchen", recipe.Title)
+	assert.Equal(t, "Oma", recipe.Author)
+	assert.Equal(t, 2, recipe.Servings)
+	assert.Equal(t, 15, recipe.Duration)
+	assert.Equal(t, 25, recipe.TotalDuration)
+	assert.Equal(t, "Süß, Schnell", recipe.Tags)
+	assert.Equal(t, "- 2 Eier\n- 250 ml Milch", recipe.Ingredients)
+	assert.Equal(t, "1. Alles verrühren.\n2. In der Pfanne backen.", recipe.Instructions)
+}
+
+func TestParseRecipeNoRecipe(t *testing.T) {
+	testCases := []string{
+		"<html><body><h1>Kein Rezept</h1></body></html>",
+		`<script type="application/ld+json">{"@type": "Article"}</script>`,
+		`<script type="application/ld+json">{invalid</script>`,
+		"",
+	}
+
+	for _, input := range testCases {
+		// When
+		_, err := ParseRecipe(strings.NewReader(input))
+
+		// Then
+		assert.ErrorIs(t, err, ErrNoRecipe)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/kilianmandscharo/lethimcook/components"
 	"github.com/kilianmandscharo/lethimcook/env"
 	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/kilianmandscharo/lethimcook/mealplan"
 	"github.com/kilianmandscharo/lethimcook/recipe"
 	"github.com/kilianmandscharo/lethimcook/render"
 	"github.com/kilianmandscharo/lethimcook/servutil"
@@ -27,11 +28,15 @@ type Server struct {
 func New(
 	authController *auth.AuthController,
 	recipeController *recipe.RecipeController,
+	mealPlanController *mealplan.MealPlanController,
 	logger *logging.Logger,
 	renderer *render.Renderer,
 	isProd bool,
 ) Server {
 	e := echo.New()
+	// The server is reached directly, headers like X-Forwarded-For are set by
+	// the client and must not be trusted for the spam rate limit
+	e.IPExtractor = echo.ExtractIPDirect()
 
 	e.Use(authController.ValidateTokenMiddleware, logging.LoggerMiddleware(logger))
 	e.Static("/static", "./static")
@@ -56,6 +61,7 @@ func New(
 	})
 
 	recipeController.AttachHandlerFunctions(e)
+	mealPlanController.AttachHandlerFunctions(e)
 	authController.AttachHandlerFunctions(e)
 
 	return Server{
@@ -1,6 +1,8 @@
 package servutil
 
 import (
+	"github.com/kilianmandscharo/lethimcook/errutil"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/labstack/echo/v4"
 )
 
@@ -9,10 +11,56 @@ func IsHxRequest(c echo.Context) bool {
 	return len(hxRequestEntry) > 0 && hxRequestEntry[0] == "true"
 }
 
+// GetUser returns the user the request was made by, it is set by the token
+// middleware for logged in users.
+func GetUser(c echo.Context) (types.User, bool) {
+	user, ok := c.Get("user").(types.User)
+	return user, ok
+}
+
+func SetUser(c echo.Context, user types.User) {
+	c.Set("user", user)
+}
+
+func ClearUser(c echo.Context) {
+	c.Set("user", nil)
+}
+
+// GetRole returns the role of the logged in user, or an empty role for
+// visitors.
+func GetRole(c echo.Context) types.Role {
+	user, _ := GetUser(c)
+	return user.Role
+}
+
+func IsLoggedIn(c echo.Context) bool {
+	_, ok := GetUser(c)
+	return ok
+}
+
+// IsAuthorized reports whether the request was made by an admin.
 func IsAuthorized(c echo.Context) bool {
-	authorizedValue := c.Get("authorized")
-	if authorized, ok := authorizedValue.(bool); ok {
-		return authorized
+	return GetRole(c).IsAdmin()
+}
+
+// CanEdit reports whether the request was made by a user who may create and
+// edit recipes.
+func CanEdit(c echo.Context) bool {
+	return GetRole(c).CanEdit()
+}
+
+// CanModify reports whether the request was made by a user who may change
+// the recipe.
+func CanModify(c echo.Context, recipe types.Recipe) bool {
+	user, _ := GetUser(c)
+	return user.CanModify(recipe)
+}
+
+// NewAppErrorNotAllowed returns the error for a request the user may not
+// make. Visitors have to log in, logged in users lack the permission.
+func NewAppErrorNotAllowed(c echo.Context, functionName string) error {
+	if IsLoggedIn(c) {
+		return errutil.NewAppErrorForbidden(functionName)
 	}
-	return false
+	return errutil.NewAppErrorNotAuthorized(functionName)
 }
@@ -1,9 +1,12 @@
 package servutil
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/kilianmandscharo/lethimcook/errutil"
 	"github.com/kilianmandscharo/lethimcook/testutil"
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,13 +27,38 @@ func TestIsHxRequest(t *testing.T) {
 func TestIsAuthorized(t *testing.T) {
 	c := testutil.NewEmptyTestContext(t)
 	assert.False(t, IsAuthorized(c))
+	assert.False(t, CanEdit(c))
+	assert.False(t, IsLoggedIn(c))
 
-	c.Set("authorized", false)
+	c.Set("user", "admin")
 	assert.False(t, IsAuthorized(c))
+	assert.False(t, IsLoggedIn(c))
 
-	c.Set("authorized", "true")
+	SetUser(c, types.User{ID: 2, Name: "Oma", Role: types.RoleContributor})
 	assert.False(t, IsAuthorized(c))
+	assert.False(t, CanEdit(c))
+	assert.True(t, IsLoggedIn(c))
 
-	c.Set("authorized", true)
+	SetUser(c, types.User{ID: 3, Name: "Papa", Role: types.RoleEditor})
+	assert.False(t, IsAuthorized(c))
+	assert.True(t, CanEdit(c))
+	assert.True(t, CanModify(c, types.Recipe{CreatedByID: 3}))
+	assert.False(t, CanModify(c, types.Recipe{CreatedByID: 1}))
+
+	SetUser(c, types.User{ID: 1, Name: "admin", Role: types.RoleAdmin})
 	assert.True(t, IsAuthorized(c))
+	assert.True(t, CanEdit(c))
+	assert.Equal(t, types.RoleAdmin, GetRole(c))
+
+	ClearUser(c)
+	assert.False(t, IsAuthorized(c))
+	assert.False(t, IsLoggedIn(c))
+}
+
+func TestNewAppErrorNotAllowed(t *testing.T) {
+	c := testutil.NewEmptyTestContext(t)
+	assert.Equal(t, http.StatusUnauthorized, errutil.GetAppErrorStatusCode(NewAppErrorNotAllowed(c, "test()")))
+
+	SetUser(c, types.User{ID: 3, Name: "Papa", Role: types.RoleEditor})
+	assert.Equal(t, http.StatusForbidden, errutil.GetAppErrorStatusCode(NewAppErrorNotAllowed(c, "test()")))
 }
@@ -0,0 +1,240 @@
+// Package spam protects the forms anonymous visitors can submit against
+// automated submissions.
+package spam
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/kilianmandscharo/lethimcook/env"
+	"github.com/kilianmandscharo/lethimcook/logging"
+)
+
+const (
+	// HoneypotField is the name of a form field that is hidden from people,
+	// only bots fill it in.
+	HoneypotField = "website"
+	// StartedAtField is the name of the form field holding the unix time at
+	// which the form was rendered.
+	StartedAtField = "formStartedAt"
+)
+
+var (
+	ErrRateLimited  = errors.New("too many submissions")
+	ErrHoneypot     = errors.New("honeypot filled in")
+	ErrTooFast      = errors.New("form filled in too fast")
+	ErrTooManyLinks = errors.New("too many links")
+	ErrBannedWord   = errors.New("banned word")
+)
+
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// Config holds the limits of the filter. A limit of 0 turns its check off.
+type Config struct {
+	// RateLimit is the number of submissions one IP may make per RateWindow
+	RateLimit   int
+	RateWindow  time.Duration
+	MinFillTime time.Duration
+	MaxLinks    int
+	BannedWords []string
+}
+
+func DefaultConfig() Config {
+	return Config{
+		RateLimit:   5,
+		RateWindow:  time.Hour,
+		MinFillTime: 5 * time.Second,
+		MaxLinks:    3,
+	}
+}
+
+// LoadConfig reads the configuration from the environment, falling back to
+// the defaults for missing or invalid values. The banned words are read from
+// a file with one word per line, lines starting with # are ignored.
+func LoadConfig(logger *logging.Logger) Config {
+	config := DefaultConfig()
+
+	readInt := func(key string, fallback int) int {
+		value := env.Get(key)
+		if len(value) == 0 {
+			return fallback
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			logger.Warnf("invalid value %q for %s, using %d", value, key, fallback)
+			return fallback
+		}
+		return parsed
+	}
+
+	config.RateLimit = readInt(env.EnvKeySpamRateLimit, config.RateLimit)
+	config.RateWindow = time.Duration(readInt(env.EnvKeySpamRateWindowMinutes, int(config.RateWindow.Minutes()))) * time.Minute
+	config.MinFillTime = time.Duration(readInt(env.EnvKeySpamMinFillSeconds, int(config.MinFillTime.Seconds()))) * time.Second
+	config.MaxLinks = readInt(env.EnvKeySpamMaxLinks, config.MaxLinks)
+
+	if path := env.Get(env.EnvKeySpamBannedWordsFile); len(path) > 0 {
+		words, err := readBannedWords(path)
+		if err != nil {
+			logger.Error(fmt.Errorf("failed at LoadConfig(), could not read banned words: %w", err))
+		} else {
+			config.BannedWords = words
+		}
+	}
+
+	return config
+}
+
+func readBannedWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if len(word) == 0 || strings.HasPrefix(word, "#") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words, scanner.Err()
+}
+
+// Submission is what the filter needs to know about a submitted form.
+type Submission struct {
+	IP        string
+	Honeypot  string
+	StartedAt string
+	Texts     []string
+}
+
+type Filter struct {
+	config      Config
+	logger      *logging.Logger
+	bannedWords map[string]bool
+	now         func() time.Time
+
+	mu          sync.Mutex
+	submissions map[string][]time.Time
+}
+
+func NewFilter(config Config, logger *logging.Logger) *Filter {
+	bannedWords := make(map[string]bool)
+	for _, word := range config.BannedWords {
+		bannedWords[strings.ToLower(word)] = true
+	}
+	return &Filter{
+		config:      config,
+		logger:      logger,
+		bannedWords: bannedWords,
+		now:         time.Now,
+		submissions: make(map[string][]time.Time),
+	}
+}
+
+// Check returns an error wrapping one of the Err values if the submission
+// looks like spam. Rejected submissions are logged. Only submissions passed
+// to Record count towards the rate limit of their IP.
+func (f *Filter) Check(submission Submission) error {
+	err := f.check(submission)
+	if err != nil {
+		f.logger.Warnf("rejected submission from %s: %v", submission.IP, err)
+	}
+	return err
+}
+
+func (f *Filter) check(submission Submission) error {
+	now := f.now()
+
+	if len(submission.Honeypot) > 0 {
+		return ErrHoneypot
+	}
+
+	if f.config.MinFillTime > 0 {
+		startedAt, err := strconv.ParseInt(submission.StartedAt, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w, invalid start time %q", ErrTooFast, submission.StartedAt)
+		}
+		if fillTime := now.Sub(time.Unix(startedAt, 0)); fillTime < f.config.MinFillTime {
+			return fmt.Errorf("%w, took %s", ErrTooFast, fillTime)
+		}
+	}
+
+	text := strings.Join(submission.Texts, "\n")
+	if f.config.MaxLinks > 0 {
+		if links := len(linkPattern.FindAllStringIndex(text, -1)); links > f.config.MaxLinks {
+			return fmt.Errorf("%w, found %d", ErrTooManyLinks, links)
+		}
+	}
+	if word, found := f.findBannedWord(text); found {
+		return fmt.Errorf("%w %q", ErrBannedWord, word)
+	}
+
+	return f.checkRateLimit(submission.IP, now)
+}
+
+// Record counts a submission of the IP towards its rate limit. It is called
+// once the submission was saved, so that forms with errors don't count.
+func (f *Filter) Record(ip string) {
+	if f.config.RateLimit <= 0 || f.config.RateWindow <= 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submissions[ip] = append(f.submissions[ip], f.now())
+}
+
+func (f *Filter) findBannedWord(text string) (string, bool) {
+	if len(f.bannedWords) == 0 {
+		return "", false
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if f.bannedWords[word] {
+			return word, true
+		}
+	}
+	return "", false
+}
+
+func (f *Filter) checkRateLimit(ip string, now time.Time) error {
+	if f.config.RateLimit <= 0 || f.config.RateWindow <= 0 {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	windowStart := now.Add(-f.config.RateWindow)
+	for key, times := range f.submissions {
+		recent := times[:0]
+		for _, t := range times {
+			if t.After(windowStart) {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == 0 {
+			delete(f.submissions, key)
+		} else {
+			f.submissions[key] = recent
+		}
+	}
+
+	if len(f.submissions[ip]) >= f.config.RateLimit {
+		return fmt.Errorf("%w, %d in %s", ErrRateLimited, len(f.submissions[ip]), f.config.RateWindow)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package spam
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kilianmandscharo/lethimcook/env"
+	"github.com/kilianmandscharo/lethimcook/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFilter(config Config, now time.Time) *Filter {
+	filter := NewFilter(config, logging.New(logging.Debug, false))
+	filter.now = func() time.Time { return now }
+	return filter
+}
+
+func newTestSubmission(startedAt time.Time, texts ...string) Submission {
+	return Submission{
+		IP:        "192.0.2.1",
+		StartedAt: fmt.Sprint(startedAt.Unix()),
+		Texts:     texts,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultConfig()
+	config.BannedWords = []string{"Casino"}
+	filter := newTestFilter(config, now)
+
+	// Honeypot
+	submission := newTestSubmission(now.Add(-time.Minute), "Linsensuppe")
+	submission.Honeypot = "https://example.com"
+	assert.ErrorIs(t, filter.Check(submission), ErrHoneypot)
+
+	// Fill time
+	assert.ErrorIs(t, filter.Check(newTestSubmission(now.Add(-2*time.Second), "Linsensuppe")), ErrTooFast)
+	submission = newTestSubmission(now, "Linsensuppe")
+	submission.StartedAt = ""
+	assert.ErrorIs(t, filter.Check(submission), ErrTooFast)
+
+	// Links
+	assert.ErrorIs(t, filter.Check(newTestSubmission(
+		now.Add(-time.Minute),
+		"https://a.de http://b.de",
+		"www.c.de https://d.de",
+	)), ErrTooManyLinks)
+
+	// Banned words
+	assert.ErrorIs(t, filter.Check(newTestSubmission(now.Add(-time.Minute), "Bestes CASINO!")), ErrBannedWord)
+	assert.NoError(t, filter.Check(newTestSubmission(now.Add(-time.Minute), "Casinosalat")))
+
+	// Valid
+	assert.NoError(t, filter.Check(newTestSubmission(now.Add(-time.Minute), "Linsensuppe", "https://chefkoch.de")))
+}
+
+func TestCheckRateLimit(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultConfig()
+	config.RateLimit = 2
+	filter := newTestFilter(config, now)
+
+	// Checks alone don't count
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, filter.Check(newTestSubmission(now.Add(-time.Minute))))
+	}
+
+	filter.Record("192.0.2.1")
+	assert.NoError(t, filter.Check(newTestSubmission(now.Add(-time.Minute))))
+	filter.Record("192.0.2.1")
+	assert.ErrorIs(t, filter.Check(newTestSubmission(now.Add(-time.Minute))), ErrRateLimited)
+
+	other := newTestSubmission(now.Add(-time.Minute))
+	other.IP = "192.0.2.2"
+	assert.NoError(t, filter.Check(other))
+	filter.Record(other.IP)
+
+	filter.now = func() time.Time { return now.Add(time.Hour + time.Second) }
+	assert.NoError(t, filter.Check(newTestSubmission(now)))
+	assert.Equal(t, 0, len(filter.submissions))
+}
+
+func TestCheckDisabled(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	filter := newTestFilter(Config{}, now)
+
+	for i := 0; i < 10; i++ {
+		submission := newTestSubmission(now, "https://a.de https://b.de https://c.de https://d.de")
+		submission.StartedAt = ""
+		assert.NoError(t, filter.Check(submission))
+		filter.Record(submission.IP)
+	}
+	assert.Equal(t, 0, len(filter.submissions))
+}
+
+func TestLoadConfig(t *testing.T) {
+	logger := logging.New(logging.Debug, false)
+	path := filepath.Join(t.TempDir(), "banned.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# Werbung\nCasino\n\n  Viagra \n"), 0o644))
+
+	t.Setenv(env.EnvKeySpamRateLimit, "10")
+	t.Setenv(env.EnvKeySpamRateWindowMinutes, "abc")
+	t.Setenv(env.EnvKeySpamMinFillSeconds, "0")
+	t.Setenv(env.EnvKeySpamMaxLinks, "-1")
+	t.Setenv(env.EnvKeySpamBannedWordsFile, path)
+
+	config := LoadConfig(logger)
+
+	assert.Equal(t, Config{
+		RateLimit:   10,
+		RateWindow:  time.Hour,
+		MinFillTime: 0,
+		MaxLinks:    3,
+		BannedWords: []string{"casino", "viagra"},
+	}, config)
+}
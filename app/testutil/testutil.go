@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kilianmandscharo/lethimcook/types"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -18,8 +19,12 @@ type RequestOptions struct {
 	Route           string
 	StatusWant      int
 	Authorized      bool
+	Role            types.Role
+	UserID          uint
 	WithFormData    bool
 	FormData        string
+	WithJSONData    bool
+	JSONData        string
 	WithCookie      bool
 	Cookie          http.Cookie
 	WithPathParam   bool
@@ -41,6 +46,8 @@ func AssertRequest(t *testing.T, options RequestOptions) (*httptest.ResponseReco
 
 	if options.WithFormData {
 		body = bytes.NewBufferString(options.FormData)
+	} else if options.WithJSONData {
+		body = bytes.NewBufferString(options.JSONData)
 	} else {
 		body = nil
 	}
@@ -56,13 +63,27 @@ func AssertRequest(t *testing.T, options RequestOptions) (*httptest.ResponseReco
 	if options.WithFormData {
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
 	}
+	if options.WithJSONData {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+
+	if options.WithCookie {
+		req.AddCookie(&options.Cookie)
+	}
 
 	c := e.NewContext(req, rr)
 
-	if options.Authorized {
-		c.Set("authorized", true)
-	} else {
-		c.Set("authorized", false)
+	// Authorized requests are made by an admin, Role makes them by a user
+	// with another role. The user is named after the role.
+	if options.Authorized && len(options.Role) == 0 {
+		options.Role = types.RoleAdmin
+	}
+	if len(options.Role) > 0 {
+		userId := options.UserID
+		if userId == 0 {
+			userId = 1
+		}
+		c.Set("user", types.User{ID: userId, Name: string(options.Role), Role: options.Role})
 	}
 
 	if options.WithPathParam {
@@ -6,26 +6,256 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/kilianmandscharo/lethimcook/errutil"
-	"github.com/yuin/goldmark"
+	"github.com/kilianmandscharo/lethimcook/markdown"
+	"gorm.io/gorm"
 )
 
 type Recipe struct {
-	ID             uint   `json:"id"`
-	Author         string `json:"author"`
-	Source         string `json:"source"`
-	Title          string `json:"title"`
-	Description    string `json:"description"`
-	Duration       int    `json:"duration"`
-	TotalDuration  int    `json:"totalDuration"`
-	Ingredients    string `json:"ingredients"`
-	Instructions   string `json:"instructions"`
-	Tags           string `json:"tags"`
-	Pending        bool   `json:"-"`
-	CreatedAt      string `json:"createdAt"`
-	LastModifiedAt string `json:"-"`
+	ID                uint           `json:"id"`
+	Author            string         `json:"author"`
+	Source            string         `json:"source"`
+	Title             string         `json:"title"`
+	Description       string         `json:"description"`
+	Duration          int            `json:"duration"`
+	TotalDuration     int            `json:"totalDuration"`
+	Servings          int            `json:"servings"`
+	Ingredients       string         `json:"ingredients"`
+	ParsedIngredients []Ingredient   `json:"parsedIngredients,omitempty" gorm:"serializer:json"`
+	Instructions      string         `json:"instructions"`
+	Tags              string         `json:"tags"`
+	Pending           bool           `json:"-"`
+	CreatedAt         string         `json:"createdAt"`
+	LastModifiedAt    string         `json:"-"`
+	Image             string         `json:"-"`
+	Submitter         string         `json:"-"`
+	CreatedByID       uint           `json:"-" gorm:"index"`
+	CreatedByName     string         `json:"-"`
+	LastEditedByID    uint           `json:"-"`
+	LastEditedByName  string         `json:"-"`
+	RejectionReason   string         `json:"-"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name" gorm:"uniqueIndex"`
+}
+
+// RecipeTag links a recipe to one of its tags. Position keeps the order in
+// which the tags were entered.
+type RecipeTag struct {
+	RecipeID uint `gorm:"primaryKey"`
+	TagID    uint `gorm:"primaryKey;index"`
+	Position int
+}
+
+type TagUsage struct {
+	ID          uint
+	Name        string
+	RecipeCount int
+}
+
+type TrashedRecipe struct {
+	ID              uint
+	Title           string
+	DeletedAt       string
+	PurgeAt         string
+	RejectionReason string
+}
+
+// PendingRecipe is a submitted recipe waiting in the moderation queue.
+type PendingRecipe struct {
+	ID          uint
+	Title       string
+	Description string
+	Author      string
+	Source      string
+	Submitter   string
+	SubmittedAt string
+}
+
+// RecipeRevision is a snapshot of a recipe's content as saved by one update.
+type RecipeRevision struct {
+	ID            uint
+	RecipeID      uint `gorm:"index"`
+	Editor        string
+	CreatedAt     string
+	Author        string
+	Source        string
+	Title         string
+	Description   string
+	Duration      int
+	TotalDuration int
+	Servings      int
+	Ingredients   string
+	Instructions  string
+	Tags          string
+	Image         string
+}
+
+func NewRecipeRevision(recipe Recipe, editor string, createdAt string) RecipeRevision {
+	return RecipeRevision{
+		RecipeID:      recipe.ID,
+		Editor:        editor,
+		CreatedAt:     createdAt,
+		Author:        recipe.Author,
+		Source:        recipe.Source,
+		Title:         recipe.Title,
+		Description:   recipe.Description,
+		Duration:      recipe.Duration,
+		TotalDuration: recipe.TotalDuration,
+		Servings:      recipe.Servings,
+		Ingredients:   recipe.Ingredients,
+		Instructions:  recipe.Instructions,
+		Tags:          recipe.Tags,
+		Image:         recipe.Image,
+	}
+}
+
+// ApplyTo sets the content of the recipe to the one of the revision.
+func (r *RecipeRevision) ApplyTo(recipe *Recipe) {
+	recipe.Author = r.Author
+	recipe.Source = r.Source
+	recipe.Title = r.Title
+	recipe.Description = r.Description
+	recipe.Duration = r.Duration
+	recipe.TotalDuration = r.TotalDuration
+	recipe.Servings = r.Servings
+	recipe.Ingredients = r.Ingredients
+	recipe.Instructions = r.Instructions
+	recipe.Tags = r.Tags
+	recipe.Image = r.Image
+}
+
+// FormatCreatedAt returns the time of the revision for display, e.g.
+// "24.12.2024 18:30".
+func (r *RecipeRevision) FormatCreatedAt() string {
+	createdAt, err := time.Parse(time.RFC3339, r.CreatedAt)
+	if err != nil {
+		return r.CreatedAt
+	}
+	return createdAt.Local().Format("02.01.2006 15:04")
+}
+
+func (r *RecipeRevision) GetEditor() string {
+	if len(r.Editor) == 0 {
+		return "Unbekannt"
+	}
+	return r.Editor
+}
+
+const (
+	DiffEqual   = "equal"
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+)
+
+type DiffLine struct {
+	Kind string
+	Text string
+}
+
+// RecipeRevisionEntry is a revision in the history together with what it
+// changed compared to the revision before it.
+type RecipeRevisionEntry struct {
+	Revision         RecipeRevision
+	Current          bool
+	ChangedFields    []string
+	IngredientsDiff  []DiffLine
+	InstructionsDiff []DiffLine
+}
+
+// RecipeSuggestion is a change to a recipe proposed by a visitor. It holds
+// the complete suggested content and waits for an admin to accept or reject
+// it.
+type RecipeSuggestion struct {
+	ID            uint
+	RecipeID      uint `gorm:"index"`
+	CreatedAt     string
+	Comment       string
+	Author        string
+	Source        string
+	Title         string
+	Description   string
+	Duration      int
+	TotalDuration int
+	Servings      int
+	Ingredients   string
+	Instructions  string
+	Tags          string
+	Image         string
+}
+
+func NewRecipeSuggestion(recipe Recipe, comment string, createdAt string) RecipeSuggestion {
+	return RecipeSuggestion{
+		RecipeID:      recipe.ID,
+		CreatedAt:     createdAt,
+		Comment:       comment,
+		Author:        recipe.Author,
+		Source:        recipe.Source,
+		Title:         recipe.Title,
+		Description:   recipe.Description,
+		Duration:      recipe.Duration,
+		TotalDuration: recipe.TotalDuration,
+		Servings:      recipe.Servings,
+		Ingredients:   recipe.Ingredients,
+		Instructions:  recipe.Instructions,
+		Tags:          recipe.Tags,
+		Image:         recipe.Image,
+	}
+}
+
+// ApplyTo sets the content of the recipe to the suggested one.
+func (s *RecipeSuggestion) ApplyTo(recipe *Recipe) {
+	recipe.Author = s.Author
+	recipe.Source = s.Source
+	recipe.Title = s.Title
+	recipe.Description = s.Description
+	recipe.Duration = s.Duration
+	recipe.TotalDuration = s.TotalDuration
+	recipe.Servings = s.Servings
+	recipe.Ingredients = s.Ingredients
+	recipe.Instructions = s.Instructions
+	recipe.Tags = s.Tags
+	recipe.Image = s.Image
+}
+
+func (s *RecipeSuggestion) FormatCreatedAt() string {
+	createdAt, err := time.Parse(time.RFC3339, s.CreatedAt)
+	if err != nil {
+		return s.CreatedAt
+	}
+	return createdAt.Local().Format("02.01.2006 15:04")
+}
+
+// RecipeSuggestionEntry is a suggestion together with what it changes
+// compared to the current recipe.
+type RecipeSuggestionEntry struct {
+	Suggestion       RecipeSuggestion
+	ChangedFields    []string
+	IngredientsDiff  []DiffLine
+	InstructionsDiff []DiffLine
+}
+
+type Ingredient struct {
+	Quantity    float64 `json:"quantity,omitempty"`
+	QuantityMax float64 `json:"quantityMax,omitempty"`
+	Unit        string  `json:"unit,omitempty"`
+	Name        string  `json:"name"`
+	Note        string  `json:"note,omitempty"`
+	Group       string  `json:"group,omitempty"`
+}
+
+func (i *Ingredient) HasQuantity() bool {
+	return i.Quantity > 0
+}
+
+func (i *Ingredient) IsRange() bool {
+	return i.QuantityMax > i.Quantity
 }
 
 func (r *Recipe) String() string {
@@ -44,6 +274,8 @@ func (r *Recipe) String() string {
 	buf.WriteString(strconv.Itoa(r.Duration))
 	buf.WriteString("\n    total duration: ")
 	buf.WriteString(strconv.Itoa(r.TotalDuration))
+	buf.WriteString("\n    servings: ")
+	buf.WriteString(strconv.Itoa(r.Servings))
 	buf.WriteString("\n    ingredients: ")
 	buf.WriteString(r.Ingredients)
 	buf.WriteString("\n    instructions: ")
@@ -82,7 +314,8 @@ func (r *Recipe) ContainsQuery(query string) bool {
 	return strings.Contains(strings.ToLower(r.Title), query) ||
 		strings.Contains(strings.ToLower(r.Description), query) ||
 		strings.Contains(strings.ToLower(r.Author), query) ||
-		r.containsQueryInTags(query)
+		r.containsQueryInTags(query) ||
+		r.containsQueryInIngredients(query)
 }
 
 func (r *Recipe) containsQueryInTags(query string) bool {
@@ -95,28 +328,36 @@ func (r *Recipe) containsQueryInTags(query string) bool {
 	return false
 }
 
-func (r *Recipe) RenderMarkdown() error {
-	var buf bytes.Buffer
+func (r *Recipe) containsQueryInIngredients(query string) bool {
+	for _, ingredient := range r.ParsedIngredients {
+		if strings.Contains(strings.ToLower(ingredient.Name), query) {
+			return true
+		}
+	}
+
+	return false
+}
 
-	if err := goldmark.Convert([]byte(r.Ingredients), &buf); err != nil {
+func (r *Recipe) RenderMarkdown() error {
+	ingredients, err := markdown.Convert(r.Ingredients)
+	if err != nil {
 		return &errutil.AppError{
 			UserMessage: "Fehler beim Markdownparsing",
 			StatusCode:  http.StatusInternalServerError,
 			Err:         fmt.Errorf("failed at RenderMarkdown() with ingredients %s", r.Ingredients),
 		}
 	}
-	r.Ingredients = buf.String()
+	r.Ingredients = ingredients
 
-	buf.Reset()
-
-	if err := goldmark.Convert([]byte(r.Instructions), &buf); err != nil {
+	instructions, err := markdown.Convert(r.Instructions)
+	if err != nil {
 		return &errutil.AppError{
 			UserMessage: "Fehler beim Markdownparsing",
 			StatusCode:  http.StatusInternalServerError,
 			Err:         fmt.Errorf("failed at RenderMarkdown() with instructions %s", r.Instructions),
 		}
 	}
-	r.Instructions = buf.String()
+	r.Instructions = instructions
 
 	return nil
 }
@@ -143,6 +384,9 @@ type FormElementType int
 const (
 	FormElementInput FormElementType = iota + 1
 	FormElementTextArea
+	// FormElementImage is a file input for an image, Value holds the key of
+	// the current image.
+	FormElementImage
 )
 
 type FormElement struct {
@@ -156,6 +400,13 @@ type FormElement struct {
 	Disabled       bool
 	Placeholder    string
 	LabelComponent templ.Component
+	// SuggestionsUrl, if set, is queried with the value of the input while
+	// typing and answers with the options of a datalist.
+	SuggestionsUrl string
+}
+
+func (f *FormElement) GetSuggestionsId() string {
+	return f.Name + "-suggestions"
 }
 
 func (f *FormElement) GetPlaceholder() string {
@@ -185,7 +436,215 @@ func (p *PaginationInfo) GetPageLabels() int {
 	return p.TotalPages
 }
 
+const (
+	TagModeAnd = "and"
+	TagModeOr  = "or"
+)
+
+type TagFacet struct {
+	Name     string
+	Count    int
+	Selected bool
+}
+
+type TagFilter struct {
+	Facets []TagFacet
+	Mode   string
+}
+
+const (
+	SortNewest   = "newest"
+	SortOldest   = "oldest"
+	SortTitle    = "title"
+	SortDuration = "duration"
+	SortModified = "modified"
+	SortRandom   = "random"
+)
+
+type SortOption struct {
+	Value string
+	Label string
+}
+
+var SortOptions = []SortOption{
+	{Value: SortNewest, Label: "Neueste zuerst"},
+	{Value: SortOldest, Label: "Älteste zuerst"},
+	{Value: SortTitle, Label: "Titel A–Z"},
+	{Value: SortDuration, Label: "Kürzeste Gesamtzeit"},
+	{Value: SortModified, Label: "Zuletzt bearbeitet"},
+	{Value: SortRandom, Label: "Zufällig"},
+}
+
+// RecipeSort is the order of the recipe list. Seed keeps a random order
+// stable across the pages of the list.
+type RecipeSort struct {
+	Value string
+	Seed  int64
+}
+
 type RecipeLinkData struct {
 	ID    uint   `json:"id"`
 	Title string `json:"title"`
 }
+
+type ShoppingListEntry struct {
+	RecipeID uint
+	Servings int
+}
+
+type ShoppingListRecipe struct {
+	ID       uint
+	Title    string
+	Servings int
+}
+
+type ShoppingListCategory struct {
+	Name        string
+	Ingredients []Ingredient
+}
+
+type ShoppingList struct {
+	Recipes    []ShoppingListRecipe
+	Categories []ShoppingListCategory
+}
+
+type MealPlanEntry struct {
+	ID       uint   `json:"id"`
+	Date     string `json:"date" gorm:"index"`
+	Slot     string `json:"slot"`
+	RecipeID uint   `json:"recipeId" gorm:"index"`
+}
+
+type MealPlanItem struct {
+	EntryID  uint
+	RecipeID uint
+	Title    string
+	Missing  bool
+}
+
+type MealPlanSlot struct {
+	Name  string
+	Label string
+	Items []MealPlanItem
+}
+
+type MealPlanDay struct {
+	Date  time.Time
+	Label string
+	Slots []MealPlanSlot
+}
+
+type MealPlanWeek struct {
+	Start   time.Time
+	Days    []MealPlanDay
+	Recipes []RecipeLinkData
+}
+
+// DuplicateMatch is an existing recipe that is likely the same as the one
+// being created, Reasons tells why.
+type DuplicateMatch struct {
+	ID      uint
+	Title   string
+	Reasons []string
+}
+
+type DuplicatePair struct {
+	First   DuplicateMatch
+	Second  DuplicateMatch
+	Reasons []string
+}
+
+type RecipeImportResult struct {
+	Source  string
+	Title   string
+	ID      uint
+	Trashed bool
+	Errors  []string
+}
+
+func (r *RecipeImportResult) Success() bool {
+	return len(r.Errors) == 0
+}
+
+// Link returns the page of the imported recipe, recipes that were put back
+// into the trash can only be seen there.
+func (r *RecipeImportResult) Link() string {
+	if r.Trashed {
+		return "/admin/trash"
+	}
+	return fmt.Sprintf("/recipe/%d", r.ID)
+}
+
+// PageMeta holds the per-page metadata rendered into the head of a full page
+// load. Empty fields fall back to the defaults of the site.
+type PageMeta struct {
+	Title       string
+	Description string
+	Url         string
+	Type        string
+	Image       string
+	JsonLd      any
+}
+
+// Role decides what a logged in user may do. Editors create and edit
+// recipes, submissions of contributors wait in the moderation queue like the
+// ones of visitors.
+type Role string
+
+const (
+	RoleAdmin       Role = "admin"
+	RoleEditor      Role = "editor"
+	RoleContributor Role = "contributor"
+)
+
+var Roles = []Role{RoleAdmin, RoleEditor, RoleContributor}
+
+func (r Role) IsValid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Role) IsAdmin() bool {
+	return r == RoleAdmin
+}
+
+func (r Role) CanEdit() bool {
+	return r == RoleAdmin || r == RoleEditor
+}
+
+func (r Role) Label() string {
+	switch r {
+	case RoleAdmin:
+		return "Admin"
+	case RoleEditor:
+		return "Redakteur"
+	case RoleContributor:
+		return "Beitragender"
+	}
+	return "Besucher"
+}
+
+// User is an account as seen by the rest of the app, without its password.
+type User struct {
+	ID   uint
+	Name string
+	Role Role
+}
+
+// IsLoggedIn is false for the empty user of visitors.
+func (u User) IsLoggedIn() bool {
+	return u.Role.IsValid()
+}
+
+// CanModify reports whether the user may change the recipe. Editors may only
+// change the recipes they created, admins all of them.
+func (u User) CanModify(recipe Recipe) bool {
+	if u.Role.IsAdmin() {
+		return true
+	}
+	return u.Role.CanEdit() && recipe.CreatedByID != 0 && recipe.CreatedByID == u.ID
+}
@@ -53,6 +53,13 @@ func TestContainsQuery(t *testing.T) {
 			query:    "Beilage",
 			contains: true,
 		},
+		{
+			recipe: Recipe{
+				ParsedIngredients: []Ingredient{{Quantity: 200, Unit: "g", Name: "Mehl"}},
+			},
+			query:    "mehl",
+			contains: true,
+		},
 		{
 			recipe: Recipe{
 				Title:       "Naan",
@@ -104,3 +111,27 @@ func TestParseTags(t *testing.T) {
 		assert.Equal(t, test.parsed, parsed)
 	}
 }
+
+func TestCanModify(t *testing.T) {
+	own := Recipe{CreatedByID: 2}
+	other := Recipe{CreatedByID: 3}
+	anonymous := Recipe{}
+
+	testCases := []struct {
+		user      User
+		recipe    Recipe
+		canModify bool
+	}{
+		{user: User{ID: 1, Role: RoleAdmin}, recipe: other, canModify: true},
+		{user: User{ID: 1, Role: RoleAdmin}, recipe: anonymous, canModify: true},
+		{user: User{ID: 2, Role: RoleEditor}, recipe: own, canModify: true},
+		{user: User{ID: 2, Role: RoleEditor}, recipe: other, canModify: false},
+		{user: User{ID: 2, Role: RoleEditor}, recipe: anonymous, canModify: false},
+		{user: User{ID: 2, Role: RoleContributor}, recipe: own, canModify: false},
+		{user: User{}, recipe: anonymous, canModify: false},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.canModify, test.user.CanModify(test.recipe))
+	}
+}
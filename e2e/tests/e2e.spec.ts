@@ -35,17 +35,18 @@ test("admin tests", async ({ page }) => {
     await page.goto("");
     await navigateToAdminPage(page);
 
+    await testInvalidPassword(page, "invalid");
+    await login(page, "admin");
+
     await testInvalidPasswordChange(page);
     await testPasswordChangeTooShort(page);
 
-    await testInvalidPassword(page, "invalid");
-    await login(page, "admin");
-    await logout(page);
     await changePassword(page, "admin", "nimda");
+    await logout(page);
     await testInvalidPassword(page, "admin");
     await login(page, "nimda");
-    await logout(page);
     await changePassword(page, "nimda", "admin");
+    await logout(page);
     await testInvalidPassword(page, "nimda");
     await login(page, "admin");
 });
@@ -1,7 +1,8 @@
 import { Page, expect } from "@playwright/test";
 import { fillInputByPlaceholder, submitForm, clickButtonByTitle, assertTextVisible } from "./utils";
 
-export async function login(page: Page, password: string) {
+export async function login(page: Page, password: string, name = "admin") {
+    await fillInputByPlaceholder(page, "Benutzername", name);
     await fillInputByPlaceholder(page, "Passwort", password);
     await submitForm(page, "Anmelden");
     await expect(page.getByRole("button", { name: "Abmelden" })).toBeVisible();
@@ -45,12 +46,13 @@ export async function testPasswordChangeTooShort(page: Page) {
 }
 
 export async function testInvalidPassword(page: Page, invalidPassword: string) {
+    await fillInputByPlaceholder(page, "Benutzername", "admin");
     await fillInputByPlaceholder(page, "Passwort", invalidPassword);
     await submitForm(page, "Anmelden");
     await page.waitForLoadState("networkidle");
     await expect(
         page.getByRole("button", { name: "Abmelden" }),
     ).not.toBeVisible();
-    await assertTextVisible(page, "Falsches Passwort");
+    await assertTextVisible(page, "Falscher Benutzername oder Passwort");
 }
 
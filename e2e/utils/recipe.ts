@@ -163,6 +163,11 @@ export async function createRecipe(
     page.keyboard.down("Escape");
     await expect(page.locator("#preview-modal")).not.toBeVisible();
 
+    if (pending) {
+        // Submissions filled in faster than SPAM_MIN_FILL_SECONDS are rejected
+        await page.waitForTimeout(5000);
+    }
+
     await page
         .getByRole("button", {
             name: pending ? "Rezept einreichen" : "Rezept erstellen",